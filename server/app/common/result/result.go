@@ -7,32 +7,30 @@ type HttpResult struct {
 	Data interface{} `json:"data"` // 数据
 }
 
-var httpResult HttpResult
+// 业务错误码
+const (
+	CodeRecordDuplicate        = 40901 // 记录重复提交
+	CodeRecordReplay           = 40902 // 记录重放(打乱不属于当前用户或已完成)
+	CodeIdempotencyKeyInvalid  = 40903 // 幂等键不合法
+	CodeIdempotencyKeyConflict = 40904 // 幂等键与请求内容不匹配
+	CodeIdempotencyInProgress  = 40905 // 相同幂等键的请求正在处理中
+	CodeReportDuplicate        = 40906 // 重复举报
+	CodeReportRateLimited      = 42901 // 举报过于频繁
+)
 
 // Success 成功
 func Success(data interface{}) HttpResult {
-	httpResult.Code = 200
-	httpResult.Msg = "success"
-	httpResult.Data = data
-	return httpResult
+	return HttpResult{Code: 200, Msg: "success", Data: data}
 }
 
 // Fail 失败
 func Fail(msg string) HttpResult {
-	httpResult.Code = 400
-	httpResult.Msg = msg
-	return httpResult
+	return HttpResult{Code: 400, Msg: msg}
 }
 
 // FailWithCode 失败
 func FailWithCode(code int, msg string) HttpResult {
-	httpResult.Code = code
-	httpResult.Msg = msg
-	return httpResult
-}
-
-func Error() string {
-	return httpResult.Msg
+	return HttpResult{Code: code, Msg: msg}
 }
 
 // 构造函数
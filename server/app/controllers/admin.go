@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"puzzle/app/common/result"
+	ws "puzzle/app/middlewares/websocket"
 	"puzzle/app/models"
 	"puzzle/app/services"
 
@@ -98,13 +99,14 @@ func (AdminController) UpdateRecordData(c *gin.Context) {
 		Id:        id,
 		UserId:    userId,
 		Dimension: recordReq.Dimension,
+		Width:     recordReq.Width,
+		Height:    recordReq.Height,
 		Type:      recordReq.Type,
 		Duration:  recordReq.Duration,
 		Step:      recordReq.Step,
 		Scramble:  recordReq.Scramble,
 		Solution:  recordReq.Solution,
 		Idx:       idx,
-		Status:    recordReq.Status,
 	}
 
 	err = services.Record.Update(&record)
@@ -169,3 +171,236 @@ func (AdminController) ListRecordBestStepData(c *gin.Context) {
 
 	c.JSON(200, result.Success(recordBestStepListResp))
 }
+
+func (AdminController) ListRecordModerationData(c *gin.Context) {
+	var moderationReq models.RecordModerationReq
+	err := c.ShouldBindJSON(&moderationReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	moderationListResp, err := services.RecordModeration.List(&moderationReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success(moderationListResp))
+}
+
+func (AdminController) FlagRecordData(c *gin.Context) {
+	var flagReq models.RecordModerationFlagReq
+	err := c.ShouldBindJSON(&flagReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	flagReq.Source = 3
+
+	err = services.RecordModeration.Flag(&flagReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("标记成功"))
+}
+
+func (AdminController) HandleRecordModerationData(c *gin.Context) {
+	var handleReq models.RecordModerationHandleReq
+	err := c.ShouldBindJSON(&handleReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	err = services.RecordModeration.Handle(&handleReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("审核成功"))
+}
+
+func (AdminController) ListRecordModerationLogData(c *gin.Context) {
+	var logReq models.RecordModerationLogReq
+	err := c.ShouldBindJSON(&logReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	logListResp, err := services.RecordModeration.ListLog(&logReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success(logListResp))
+}
+
+func (AdminController) ListChatMessageData(c *gin.Context) {
+	var messageReq models.ChatMessageReq
+	err := c.ShouldBindJSON(&messageReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	messageListResp, err := services.Chat.List(&messageReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success(messageListResp))
+}
+
+func (AdminController) UpdateChatMessageData(c *gin.Context) {
+	var messageReq models.ChatMessageReq
+	err := c.ShouldBindJSON(&messageReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	err = services.Chat.Update(&messageReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("更新成功"))
+}
+
+func (AdminController) RestrictChatData(c *gin.Context) {
+	var restrictionReq models.ChatRestrictionReq
+	err := c.ShouldBindJSON(&restrictionReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	err = services.Chat.Restrict(&restrictionReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("操作成功"))
+}
+
+func (AdminController) ListChatRestrictionData(c *gin.Context) {
+	var restrictionReq models.ChatRestrictionReq
+	err := c.ShouldBindJSON(&restrictionReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	restrictionListResp, err := services.Chat.ListRestriction(&restrictionReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success(restrictionListResp))
+}
+
+func (AdminController) UpdateChatRestrictionData(c *gin.Context) {
+	var restrictionReq models.ChatRestrictionReq
+	err := c.ShouldBindJSON(&restrictionReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	err = services.Chat.UpdateRestriction(&restrictionReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("解除成功"))
+}
+
+func (AdminController) CreateTournamentData(c *gin.Context) {
+	var createReq models.TournamentCreateReq
+	err := c.ShouldBindJSON(&createReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	tournamentResp, err := services.Tournament.Create(&createReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success(tournamentResp))
+}
+
+func (AdminController) StartTournamentData(c *gin.Context) {
+	var tournamentReq models.TournamentReq
+	err := c.ShouldBindJSON(&tournamentReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	err = services.Tournament.Start(&tournamentReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("开赛成功"))
+}
+
+func (AdminController) CancelTournamentData(c *gin.Context) {
+	var tournamentReq models.TournamentReq
+	err := c.ShouldBindJSON(&tournamentReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	err = services.Tournament.Cancel(&tournamentReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("取消成功"))
+}
+
+func (AdminController) JudgeTournamentMatchData(c *gin.Context) {
+	var matchReq models.TournamentMatchReq
+	err := c.ShouldBindJSON(&matchReq)
+	if err != nil {
+		c.JSON(200, result.Fail("参数错误"))
+		return
+	}
+
+	err = services.Tournament.Judge(&matchReq)
+	if err != nil {
+		c.JSON(200, result.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, result.Success("判定成功"))
+}
+
+func (AdminController) ListWebsocketNodeData(c *gin.Context) {
+	nodes, err := ws.Nodes()
+	if err != nil {
+		c.JSON(200, result.Fail("查询网关节点失败"))
+		return
+	}
+
+	c.JSON(200, result.Success(nodes))
+}
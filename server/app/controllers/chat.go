@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChatController struct{}
+
+func (ChatController) History(c *gin.Context) {
+	var messageReq models.ChatMessageReq
+	err := c.ShouldBind(&messageReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	messageReq.UserId = userId.(int64)
+
+	messageListResp, err := services.Chat.History(&messageReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(messageListResp))
+}
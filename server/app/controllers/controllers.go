@@ -1,14 +1,25 @@
 package controllers
 
 var (
-	User               = new(UserController)
-	Record             = new(RecordController)
-	RecordBestSingle   = new(RecordBestSingleController)
-	RecordBestAverage  = new(RecordBestAverageController)
-	RecordBestStep     = new(RecordBestStepController)
-	Scramble           = new(ScrambleController)
-	Notification       = new(NotificationController)
-	AdminAuthorization = new(AdminAuthorizationController)
-	Admin              = new(AdminController)
-	WebSocket          = new(WebSocketController)
+	User                = new(UserController)
+	Record              = new(RecordController)
+	RecordBestSingle    = new(RecordBestSingleController)
+	RecordBestAverage   = new(RecordBestAverageController)
+	RecordBestStep      = new(RecordBestStepController)
+	Scramble            = new(ScrambleController)
+	Notification        = new(NotificationController)
+	AdminAuthorization  = new(AdminAuthorizationController)
+	Admin               = new(AdminController)
+	WebSocket           = new(WebSocketController)
+	RecordReport        = new(RecordReportController)
+	ScrambleSet         = new(ScrambleSetController)
+	Relay               = new(RelayController)
+	RecordBestRelay     = new(RecordBestRelayController)
+	Marathon            = new(MarathonController)
+	RecordBestMarathon  = new(RecordBestMarathonController)
+	RecordBestBlindfold = new(RecordBestBlindfoldController)
+	Live                = new(LiveController)
+	Chat                = new(ChatController)
+	Tournament          = new(TournamentController)
+	FriendChallenge     = new(FriendChallengeController)
 )
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FriendChallengeController struct{}
+
+func (FriendChallengeController) Create(c *gin.Context) {
+	var createReq models.FriendChallengeCreateReq
+	err := c.ShouldBind(&createReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	createReq.UserId = userId.(int64)
+
+	challengeResp, err := services.FriendChallenge.Create(&createReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(challengeResp))
+}
+
+func (FriendChallengeController) Decline(c *gin.Context) {
+	var challengeReq models.FriendChallengeReq
+	err := c.ShouldBind(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	challengeReq.UserId = userId.(int64)
+
+	err = services.FriendChallenge.Decline(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success("已拒绝挑战"))
+}
+
+func (FriendChallengeController) Cancel(c *gin.Context) {
+	var challengeReq models.FriendChallengeReq
+	err := c.ShouldBind(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	challengeReq.UserId = userId.(int64)
+
+	err = services.FriendChallenge.Cancel(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success("已取消挑战"))
+}
+
+func (FriendChallengeController) Submit(c *gin.Context) {
+	var submitReq models.FriendChallengeSubmitReq
+	err := c.ShouldBind(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	submitReq.UserId = userId.(int64)
+
+	challengeResp, err := services.FriendChallenge.Submit(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(challengeResp))
+}
+
+func (FriendChallengeController) List(c *gin.Context) {
+	var challengeReq models.FriendChallengeReq
+	err := c.ShouldBind(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	challengeReq.UserId = userId.(int64)
+
+	challengeListResp, err := services.FriendChallenge.List(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(challengeListResp))
+}
+
+func (FriendChallengeController) Get(c *gin.Context) {
+	var challengeReq models.FriendChallengeReq
+	err := c.ShouldBind(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	challengeReq.UserId = userId.(int64)
+
+	challengeResp, err := services.FriendChallenge.Get(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(challengeResp))
+}
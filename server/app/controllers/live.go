@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LiveController struct{}
+
+func (LiveController) List(c *gin.Context) {
+	var listReq models.LiveListReq
+	err := c.ShouldBind(&listReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	liveListResp, err := services.Live.List(&listReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(liveListResp))
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MarathonController struct{}
+
+func (MarathonController) Start(c *gin.Context) {
+	var startReq models.MarathonStartReq
+	err := c.ShouldBind(&startReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	startReq.UserId = userId.(int64)
+
+	marathonResp, err := services.Marathon.Start(&startReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(marathonResp))
+}
+
+func (MarathonController) Submit(c *gin.Context) {
+	var submitReq models.MarathonSubmitReq
+	err := c.ShouldBind(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	submitReq.UserId = userId.(int64)
+
+	marathonResp, err := services.Marathon.Submit(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(marathonResp))
+}
+
+func (MarathonController) Finish(c *gin.Context) {
+	var marathonReq models.MarathonReq
+	err := c.ShouldBind(&marathonReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	marathonReq.UserId = userId.(int64)
+
+	marathonResp, err := services.Marathon.Finish(&marathonReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(marathonResp))
+}
+
+func (MarathonController) List(c *gin.Context) {
+	var marathonReq models.MarathonReq
+	err := c.ShouldBind(&marathonReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	marathonList, err := services.Marathon.List(&marathonReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(marathonList))
+}
@@ -1,17 +1,19 @@
 package controllers
 
 import (
+	"errors"
 	HttpResult "puzzle/app/common/result"
 	"puzzle/app/models"
 	"puzzle/app/services"
 
-	"puzzle/utils"
-
 	"github.com/gin-gonic/gin"
 )
 
 type RecordController struct{}
 
+// 新增记录的幂等作用域
+const recordInsertScope = "record_insert"
+
 func (RecordController) Insert(c *gin.Context) {
 	var record models.Record
 	err := c.ShouldBind(&record)
@@ -20,30 +22,73 @@ func (RecordController) Insert(c *gin.Context) {
 		return
 	}
 
-	encryptionParams := utils.EncryptionParams{
-		Dimension: record.Dimension,
-		RandomIdx: record.Idx,
-		StepCount: record.Step,
-		Scramble:  record.Scramble,
-		Solution:  record.Solution,
-	}
-
-	if !encryptionParams.VerifyScramble() {
-		c.JSON(200, HttpResult.Fail("参数错误!"))
-		return
-	}
-
 	// 获取用户ID
 	userId, _ := c.Get("userId")
 	record.UserId = userId.(int64)
 
+	// 幂等键, 相同的键重复请求时直接返回首次请求的响应
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	fingerprint := services.Record.Fingerprint(&record)
+
+	if idempotencyKey != "" {
+		storedResp, err := services.Idempotency.Acquire(recordInsertScope, record.UserId, idempotencyKey, fingerprint)
+		if err != nil {
+			c.JSON(200, HttpResult.FailWithCode(recordErrorCode(err), err.Error()))
+			return
+		}
+
+		if storedResp != nil {
+			c.JSON(200, *storedResp)
+			return
+		}
+	}
+
+	resp := HttpResult.Success("数据上传成功")
+
 	err = services.Record.Insert(&record)
 	if err != nil {
-		c.JSON(200, HttpResult.Fail(err.Error()))
-		return
+		resp = HttpResult.FailWithCode(recordErrorCode(err), err.Error())
 	}
 
-	c.JSON(200, HttpResult.Success("数据上传成功"))
+	if idempotencyKey != "" {
+		// 只保存成功和确定性的拒绝, 临时性的失败释放幂等键, 允许客户端使用相同的键重试
+		if err == nil || isRecordRejection(err) {
+			err = services.Idempotency.Save(recordInsertScope, record.UserId, idempotencyKey, fingerprint, resp)
+			if err != nil {
+				services.Idempotency.Release(recordInsertScope, record.UserId, idempotencyKey)
+			}
+		} else {
+			services.Idempotency.Release(recordInsertScope, record.UserId, idempotencyKey)
+		}
+	}
+
+	c.JSON(200, resp)
+}
+
+// isRecordRejection 判断新增记录的错误是否为确定性的拒绝, 相同的请求重试也会得到相同的结果
+func isRecordRejection(err error) bool {
+	return errors.Is(err, services.ErrRecordDuplicate) ||
+		errors.Is(err, services.ErrRecordReplay) ||
+		errors.Is(err, services.ErrRecordVerify) ||
+		errors.Is(err, services.ErrRecordInvalid)
+}
+
+// recordErrorCode 根据错误获取业务错误码
+func recordErrorCode(err error) int {
+	switch {
+	case errors.Is(err, services.ErrRecordDuplicate):
+		return HttpResult.CodeRecordDuplicate
+	case errors.Is(err, services.ErrRecordReplay):
+		return HttpResult.CodeRecordReplay
+	case errors.Is(err, services.ErrIdempotencyKeyInvalid):
+		return HttpResult.CodeIdempotencyKeyInvalid
+	case errors.Is(err, services.ErrIdempotencyKeyConflict):
+		return HttpResult.CodeIdempotencyKeyConflict
+	case errors.Is(err, services.ErrIdempotencyInProgress):
+		return HttpResult.CodeIdempotencyInProgress
+	default:
+		return 400
+	}
 }
 
 func (RecordController) List(c *gin.Context) {
@@ -63,19 +108,79 @@ func (RecordController) List(c *gin.Context) {
 	c.JSON(200, HttpResult.Success(recordList))
 }
 
-func (RecordController) Update(c *gin.Context) {
-	var record models.Record
-	err := c.ShouldBind(&record)
+func (RecordController) AnalyzeSolution(c *gin.Context) {
+	var analysisReq models.SolutionAnalysisReq
+	err := c.ShouldBind(&analysisReq)
 	if err != nil {
 		c.JSON(200, HttpResult.Fail("参数错误"))
 		return
 	}
 
-	err = services.Record.Update(&record)
+	analysisResp, err := services.Solution.Analyze(&analysisReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(analysisResp))
+}
+
+func (RecordController) GetChallengeScramble(c *gin.Context) {
+	var challengeReq models.RecordChallengeReq
+	err := c.ShouldBind(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	challengeReq.UserId = userId.(int64)
+
+	challengeResp, err := services.RecordChallenge.GetScramble(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(challengeResp))
+}
+
+func (RecordController) ChallengeLeaderboard(c *gin.Context) {
+	var challengeReq models.RecordChallengeReq
+	err := c.ShouldBind(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	challengeReq.UserId = userId.(int64)
+
+	leaderboardResp, err := services.RecordChallenge.Leaderboard(&challengeReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(leaderboardResp))
+}
+
+func (RecordController) GetGhost(c *gin.Context) {
+	var ghostReq models.GhostReq
+	err := c.ShouldBind(&ghostReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	ghostReq.UserId = userId.(int64)
+
+	ghostResp, err := services.Ghost.Get(&ghostReq)
 	if err != nil {
 		c.JSON(200, HttpResult.Fail(err.Error()))
 		return
 	}
 
-	c.JSON(200, HttpResult.Success("修改成功"))
+	c.JSON(200, HttpResult.Success(ghostResp))
 }
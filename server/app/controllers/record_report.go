@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"errors"
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecordReportController struct{}
+
+func (RecordReportController) Insert(c *gin.Context) {
+	var reportReq models.RecordReportReq
+	err := c.ShouldBind(&reportReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	reportReq.ReporterId = userId.(int64)
+
+	err = services.RecordReport.Report(&reportReq)
+	if errors.Is(err, services.ErrReportDuplicate) {
+		c.JSON(200, HttpResult.FailWithCode(HttpResult.CodeReportDuplicate, err.Error()))
+		return
+	}
+	if errors.Is(err, services.ErrReportRateLimited) {
+		c.JSON(200, HttpResult.FailWithCode(HttpResult.CodeReportRateLimited, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success("举报成功"))
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RelayController struct{}
+
+func (RelayController) Start(c *gin.Context) {
+	var startReq models.RelayStartReq
+	err := c.ShouldBind(&startReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	startReq.UserId = userId.(int64)
+
+	relayResp, err := services.Relay.Start(&startReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(relayResp))
+}
+
+func (RelayController) Submit(c *gin.Context) {
+	var submitReq models.RelaySubmitReq
+	err := c.ShouldBind(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	submitReq.UserId = userId.(int64)
+
+	relayResp, err := services.Relay.Submit(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(relayResp))
+}
+
+func (RelayController) List(c *gin.Context) {
+	var relayReq models.RelayReq
+	err := c.ShouldBind(&relayReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	relayList, err := services.Relay.List(&relayReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(relayList))
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScrambleSetController struct{}
+
+func (ScrambleSetController) Insert(c *gin.Context) {
+	var setReq models.ScrambleSetReq
+	err := c.ShouldBind(&setReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	setReq.CreatorId = userId.(int64)
+
+	setResp, err := services.ScrambleSet.Insert(&setReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(setResp))
+}
+
+func (ScrambleSetController) List(c *gin.Context) {
+	var setReq models.ScrambleSetReq
+	err := c.ShouldBind(&setReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	setListResp, err := services.ScrambleSet.List(&setReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(setListResp))
+}
+
+func (ScrambleSetController) StartSession(c *gin.Context) {
+	var sessionReq models.ScrambleSessionReq
+	err := c.ShouldBind(&sessionReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	sessionReq.UserId = userId.(int64)
+
+	sessionResp, err := services.ScrambleSet.StartSession(&sessionReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(sessionResp))
+}
+
+func (ScrambleSetController) GetSessionScramble(c *gin.Context) {
+	var sessionReq models.ScrambleSessionReq
+	err := c.ShouldBind(&sessionReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	sessionReq.UserId = userId.(int64)
+
+	sessionResp, err := services.ScrambleSet.GetSessionScramble(&sessionReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(sessionResp))
+}
+
+func (ScrambleSetController) Result(c *gin.Context) {
+	var setReq models.ScrambleSetReq
+	err := c.ShouldBind(&setReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	// 获取用户ID
+	userId, _ := c.Get("userId")
+	setReq.UserId = userId.(int64)
+
+	resultResp, err := services.ScrambleSet.Result(&setReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(resultResp))
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	HttpResult "puzzle/app/common/result"
+	"puzzle/app/models"
+	"puzzle/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TournamentController struct{}
+
+func (TournamentController) List(c *gin.Context) {
+	var tournamentReq models.TournamentReq
+	err := c.ShouldBind(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	tournamentListResp, err := services.Tournament.List(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(tournamentListResp))
+}
+
+func (TournamentController) Get(c *gin.Context) {
+	var tournamentReq models.TournamentReq
+	err := c.ShouldBind(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	tournamentReq.UserId = userId.(int64)
+
+	tournamentResp, err := services.Tournament.Get(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(tournamentResp))
+}
+
+func (TournamentController) Register(c *gin.Context) {
+	var tournamentReq models.TournamentReq
+	err := c.ShouldBind(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	tournamentReq.UserId = userId.(int64)
+
+	err = services.Tournament.Register(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success("报名成功"))
+}
+
+func (TournamentController) Unregister(c *gin.Context) {
+	var tournamentReq models.TournamentReq
+	err := c.ShouldBind(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	tournamentReq.UserId = userId.(int64)
+
+	err = services.Tournament.Unregister(&tournamentReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success("取消报名成功"))
+}
+
+func (TournamentController) GetMatch(c *gin.Context) {
+	var matchReq models.TournamentMatchReq
+	err := c.ShouldBind(&matchReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	matchReq.UserId = userId.(int64)
+
+	matchResp, err := services.Tournament.GetMatch(&matchReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(matchResp))
+}
+
+func (TournamentController) Submit(c *gin.Context) {
+	var submitReq models.TournamentSubmitReq
+	err := c.ShouldBind(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail("参数错误"))
+		return
+	}
+
+	userId, _ := c.Get("userId")
+	submitReq.UserId = userId.(int64)
+
+	matchResp, err := services.Tournament.Submit(&submitReq)
+	if err != nil {
+		c.JSON(200, HttpResult.Fail(err.Error()))
+		return
+	}
+
+	c.JSON(200, HttpResult.Success(matchResp))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"puzzle/app/common/result"
+	"puzzle/app/services"
 	"time"
 
 	ws "puzzle/app/middlewares/websocket"
@@ -41,17 +42,36 @@ func (WebSocketController) Connect(c *gin.Context) {
 		}
 	}()
 
+	// 握手时携带令牌的连接在升级前完成认证, 未携带令牌的连接需要在宽限时长内发送认证消息
+	token, subprotocol := ws.TokenFromRequest(c.Request)
+
+	var userId string
+	var err error
+	if token != "" {
+		userId, err = ws.Authenticate(token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, result.Fail(err.Error()))
+			return
+		}
+
+		if !ws.CanConnect(userId) {
+			c.JSON(http.StatusTooManyRequests, result.Fail(ws.ErrTooManyConnections.Error()))
+			return
+		}
+	}
+
+	var header http.Header
+	if subprotocol != "" {
+		header = http.Header{"Sec-WebSocket-Protocol": []string{subprotocol}}
+	}
+
 	// 将 HTTP 连接升级为 WebSocket 连接
-	conn, err := socketSet.Upgrade(c.Writer, c.Request, nil)
+	conn, err := socketSet.Upgrade(c.Writer, c.Request, header)
 	if err != nil {
 		c.JSON(200, result.Fail("升级协议失败"))
 		return
 	}
 
-	// 获取用户ID
-	// userId, _ := c.Get("userId")
-	// userIdStr := strconv.FormatInt(userId.(int64), 10)
-
 	// 初始化客户端
 	client := ws.InitClient(conn)
 
@@ -60,7 +80,46 @@ func (WebSocketController) Connect(c *gin.Context) {
 		return
 	}
 
+	if userId != "" {
+		// 握手检查后其他连接可能已占满数量
+		if err = client.BindUser(userId); err != nil {
+			client.Reject(err.Error())
+			return
+		}
+	} else {
+		go ws.ClientAuthCheck(client.ID) // 未认证连接的宽限检测
+	}
+
 	go ws.ClientHeartbeatCheck(client.ID)       // 心跳检测
 	go client.Reader(&ws.ClientManagerInstance) // 读取消息
 	go client.Writer()                          // 发送消息
 }
+
+// RegisterHandlers 注册 WebSocket 业务消息处理函数
+func (WebSocketController) RegisterHandlers() {
+	// 未登录的客户端可以观看进行中的直播, 大厅频道需要登录
+	ws.AllowAnonymousGroup("live:", services.Live.Running)
+
+	ws.Handle("ghost-start", ws.LegacyError("ghost-error", services.Ghost.Stream))   // 开始推送幽灵
+	ws.Handle("ghost-stop", ws.LegacyError("ghost-error", services.Ghost.Stop))      // 停止推送幽灵
+	ws.Handle("live-start", ws.LegacyError("live-error", services.Live.Start))       // 开始直播或对战
+	ws.Handle("live-scramble", ws.LegacyError("live-error", services.Live.Scramble)) // 直播开始新打乱
+	ws.Handle("live-move", ws.LegacyError("live-error", services.Live.Move))         // 直播的一步
+	ws.Handle("live-result", ws.LegacyError("live-error", services.Live.Result))     // 直播的最终成绩
+	ws.Handle("live-end", ws.LegacyError("live-error", services.Live.End))           // 结束直播
+	ws.Handle("live-accept", ws.LegacyError("live-error", services.Live.Accept))     // 接受对战邀请
+	ws.Handle("live-decline", ws.LegacyError("live-error", services.Live.Decline))   // 拒绝对战邀请
+	ws.Handle("live-join", ws.LegacyError("live-error", services.Live.Join))         // 观看直播
+	ws.Handle("live-leave", ws.LegacyError("live-error", services.Live.Leave))       // 退出观看
+	ws.Handle("race-create", ws.LegacyError("race-error", services.RaceRoom.Create)) // 创建竞速房间
+	ws.Handle("race-invite", ws.LegacyError("race-error", services.RaceRoom.Invite)) // 邀请选手
+	ws.Handle("race-join", ws.LegacyError("race-error", services.RaceRoom.Join))     // 加入竞速房间
+	ws.Handle("race-leave", ws.LegacyError("race-error", services.RaceRoom.Leave))   // 离开竞速房间
+	ws.Handle("race-ready", ws.LegacyError("race-error", services.RaceRoom.Ready))   // 准备或取消准备
+	ws.Handle("race-start", ws.LegacyError("race-error", services.RaceRoom.Start))   // 开始比赛
+	ws.Handle("race-submit", ws.LegacyError("race-error", services.RaceRoom.Submit)) // 提交当前轮次的成绩
+	ws.Handle("race-get", ws.LegacyError("race-error", services.RaceRoom.Get))       // 获取房间状态
+	ws.Handle("chat-join", ws.LegacyError("chat-error", services.Chat.Join))         // 加入大厅频道
+	ws.Handle("chat-leave", ws.LegacyError("chat-error", services.Chat.Leave))       // 退出大厅频道
+	ws.Handle("chat-send", ws.LegacyError("chat-error", services.Chat.Send))         // 发送聊天消息
+}
@@ -7,7 +7,11 @@ import (
 
 type RankUpdate struct {
 	Dimension int
+	Width     int
+	Height    int
 	Type      int
+	Event     string // 接力等组合项目
+	Target    int    // 耐力模式的目标
 }
 
 // UpdateRecordBestSingleRank 更新记录最佳单次排名
@@ -22,7 +26,7 @@ func UpdateRecordBestSingleRank(rankUpdate any) error {
 	tx := db.Begin()
 
 	// 创建临时表
-	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_single WHERE dimension = ? ORDER BY record_duration", rankUpdateData.Dimension).Error
+	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_single WHERE width = ? AND height = ? ORDER BY record_duration", rankUpdateData.Width, rankUpdateData.Height).Error
 	if err != nil {
 		tx.Rollback() // 回滚事务
 		return errors.New("创建临时表失败")
@@ -66,7 +70,7 @@ func UpdateRecordBestAverageRank(rankUpdate any) error {
 	tx := db.Begin()
 
 	// 创建临时表
-	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_average WHERE dimension = ? AND type = ? ORDER BY record_average_duration", rankUpdateData.Dimension, rankUpdateData.Type).Error
+	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_average WHERE width = ? AND height = ? AND type = ? ORDER BY record_average_duration", rankUpdateData.Width, rankUpdateData.Height, rankUpdateData.Type).Error
 	if err != nil {
 		tx.Rollback() // 回滚事务
 		return errors.New("创建临时表失败")
@@ -111,7 +115,7 @@ func UpdateRecordBestStepRank(rankUpdate any) error {
 	tx := db.Begin()
 
 	// 创建临时表
-	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_step WHERE dimension = ? ORDER BY record_step", rankUpdateData.Dimension).Error
+	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_step WHERE width = ? AND height = ? ORDER BY record_step", rankUpdateData.Width, rankUpdateData.Height).Error
 	if err != nil {
 		tx.Rollback() // 回滚事务
 		return errors.New("创建临时表失败")
@@ -143,3 +147,144 @@ func UpdateRecordBestStepRank(rankUpdate any) error {
 
 	return nil
 }
+
+// UpdateRecordBestBlindfoldRank 更新记录最佳盲拧排名
+func UpdateRecordBestBlindfoldRank(rankUpdate any) error {
+
+	// 将rankUpdate转换为RankUpdate类型
+	rankUpdateData := rankUpdate.(RankUpdate)
+
+	db := database.GetMySQL()
+
+	// 开启事务
+	tx := db.Begin()
+
+	// 创建临时表
+	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_blindfold WHERE width = ? AND height = ? ORDER BY record_duration", rankUpdateData.Width, rankUpdateData.Height).Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("创建临时表失败")
+	}
+
+	// 设置变量
+	err = tx.Exec("SET @ranked = 0").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("设置变量失败")
+	}
+
+	// 更新记录
+	err = tx.Exec("UPDATE record_best_blindfold AS r JOIN temp_rank AS tr ON r.id = tr.id SET r.ranked = (@ranked := @ranked + 1)").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("更新记录失败")
+	}
+
+	// 删除临时表
+	err = tx.Exec("DROP TEMPORARY TABLE IF EXISTS temp_rank").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("删除临时表失败")
+	}
+
+	// 提交事务
+	tx.Commit()
+
+	return nil
+}
+
+// UpdateRecordBestRelayRank 更新记录最佳接力排名
+func UpdateRecordBestRelayRank(rankUpdate any) error {
+
+	// 将rankUpdate转换为RankUpdate类型
+	rankUpdateData := rankUpdate.(RankUpdate)
+
+	db := database.GetMySQL()
+
+	// 开启事务
+	tx := db.Begin()
+
+	// 创建临时表
+	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_relay WHERE event = ? ORDER BY record_duration", rankUpdateData.Event).Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("创建临时表失败")
+	}
+
+	// 设置变量
+	err = tx.Exec("SET @ranked = 0").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("设置变量失败")
+	}
+
+	// 更新记录
+	err = tx.Exec("UPDATE record_best_relay AS r JOIN temp_rank AS tr ON r.id = tr.id SET r.ranked = (@ranked := @ranked + 1)").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("更新记录失败")
+	}
+
+	// 删除临时表
+	err = tx.Exec("DROP TEMPORARY TABLE IF EXISTS temp_rank").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("删除临时表失败")
+	}
+
+	// 提交事务
+	tx.Commit()
+
+	return nil
+}
+
+// UpdateRecordBestMarathonRank 更新记录最佳耐力模式排名, Type 为模式
+func UpdateRecordBestMarathonRank(rankUpdate any) error {
+
+	// 将rankUpdate转换为RankUpdate类型
+	rankUpdateData := rankUpdate.(RankUpdate)
+
+	// 马拉松按总耗时排名, 限时按完成数量排名, 数量相同时按总耗时排名
+	order := "record_duration"
+	if rankUpdateData.Type == 2 {
+		order = "solved_count DESC, record_duration"
+	}
+
+	db := database.GetMySQL()
+
+	// 开启事务
+	tx := db.Begin()
+
+	// 创建临时表
+	err := tx.Exec("CREATE TEMPORARY TABLE temp_rank SELECT id FROM record_best_marathon WHERE mode = ? AND width = ? AND height = ? AND target = ? ORDER BY "+order, rankUpdateData.Type, rankUpdateData.Width, rankUpdateData.Height, rankUpdateData.Target).Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("创建临时表失败")
+	}
+
+	// 设置变量
+	err = tx.Exec("SET @ranked = 0").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("设置变量失败")
+	}
+
+	// 更新记录
+	err = tx.Exec("UPDATE record_best_marathon AS r JOIN temp_rank AS tr ON r.id = tr.id SET r.ranked = (@ranked := @ranked + 1)").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("更新记录失败")
+	}
+
+	// 删除临时表
+	err = tx.Exec("DROP TEMPORARY TABLE IF EXISTS temp_rank").Error
+	if err != nil {
+		tx.Rollback() // 回滚事务
+		return errors.New("删除临时表失败")
+	}
+
+	// 提交事务
+	tx.Commit()
+
+	return nil
+}
@@ -30,6 +30,21 @@ var QueueList = []rabbitMQ[any]{
 		ExchangeName: "",
 		callback:     handlers.UpdateRecordBestStepRank,
 	},
+	{
+		QueueName:    "best_blindfold_rank_update_queue",
+		ExchangeName: "",
+		callback:     handlers.UpdateRecordBestBlindfoldRank,
+	},
+	{
+		QueueName:    "best_relay_rank_update_queue",
+		ExchangeName: "",
+		callback:     handlers.UpdateRecordBestRelayRank,
+	},
+	{
+		QueueName:    "best_marathon_rank_update_queue",
+		ExchangeName: "",
+		callback:     handlers.UpdateRecordBestMarathonRank,
+	},
 	{
 		QueueName:    "notification_queue",
 		ExchangeName: "",
@@ -0,0 +1,198 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"puzzle/config"
+	jwt "puzzle/utils/jwt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultAuthGracePeriod       = 10 // 默认未认证连接的宽限时长(秒)
+	defaultMaxConnectionsPerUser = 5  // 默认每个用户最多同时保持的连接数量
+)
+
+// ProtocolName 客户端通过子协议传递令牌时, 可以同时声明该子协议, 服务端优先选择它作为握手响应
+const ProtocolName = "puzzle"
+
+// tokenProtocolPrefix 通过子协议传递令牌时的前缀, 如 Sec-WebSocket-Protocol: puzzle, token.<jwt>
+const tokenProtocolPrefix = "token."
+
+var (
+	ErrUnauthorized        = errors.New("登录信息有误，请重新登录")
+	ErrTooManyConnections  = errors.New("连接数量已达上限, 请关闭其他页面后重试")
+	ErrBoundToAnotherUser  = errors.New("连接已绑定其他用户")
+	ErrAuthGracePeriodOver = errors.New("认证超时, 请重新连接")
+)
+
+// userMutex 保护用户绑定的客户端列表的并发修改
+var userMutex sync.Mutex
+
+// TokenFromRequest 从握手请求中获取令牌, 依次尝试 Authorization 请求头、子协议与 token 查询参数
+// 令牌来自子协议时返回握手响应需要回应的子协议
+func TokenFromRequest(r *http.Request) (token string, subprotocol string) {
+	if token = strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); token != "" {
+		return token, ""
+	}
+
+	protocols := strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",")
+	for i := range protocols {
+		protocols[i] = strings.TrimSpace(protocols[i])
+	}
+
+	for _, protocol := range protocols {
+		if strings.HasPrefix(protocol, tokenProtocolPrefix) {
+			token = strings.TrimPrefix(protocol, tokenProtocolPrefix)
+			subprotocol = protocol
+
+			for _, p := range protocols {
+				if p == ProtocolName {
+					subprotocol = ProtocolName
+				}
+			}
+
+			return token, subprotocol
+		}
+	}
+
+	return r.URL.Query().Get("token"), ""
+}
+
+// Authenticate 校验令牌, 返回用户ID
+func Authenticate(token string) (string, error) {
+	claims, _ := jwt.ParseToken(token)
+	if claims == nil || claims.Id == 0 {
+		return "", ErrUnauthorized
+	}
+
+	return strconv.FormatInt(claims.Id, 10), nil
+}
+
+// maxConnectionsPerUser 每个用户最多同时保持的连接数量
+func maxConnectionsPerUser() int {
+	limit := config.Settings.Websocket.MaxConnectionsPerUser
+	if limit <= 0 {
+		limit = defaultMaxConnectionsPerUser
+	}
+
+	return limit
+}
+
+// CanConnect 用户在所有节点的连接数量是否未达上限, 用于握手前提前拒绝
+func CanConnect(userId string) bool {
+	return clusterUserClientCount(userId) < maxConnectionsPerUser()
+}
+
+// localUserClientCount 当前节点中用户的连接数量
+func localUserClientCount(userId string) int {
+	userMutex.Lock()
+	defer userMutex.Unlock()
+
+	value, ok := GatewayUser.Load(userId)
+	if !ok {
+		return 0
+	}
+
+	return len(value.(*WebSocketUser).ClientID)
+}
+
+// anonymousGroup 未登录客户端可以停留的群组, 如进行中的直播
+type anonymousGroup struct {
+	prefix string                    // 群组ID前缀
+	active func(groupId string) bool // 群组是否仍在进行, 结束后不再豁免认证
+}
+
+var (
+	anonymousGroups     []anonymousGroup
+	anonymousGroupMutex sync.RWMutex
+)
+
+// AllowAnonymousGroup 允许未登录的客户端停留在以 prefix 开头且 active 返回 true 的群组中
+// 只加入这些群组的客户端不会因认证超时被注销, 群组结束或退出群组后仍需认证
+func AllowAnonymousGroup(prefix string, active func(groupId string) bool) {
+	anonymousGroupMutex.Lock()
+	defer anonymousGroupMutex.Unlock()
+
+	anonymousGroups = append(anonymousGroups, anonymousGroup{prefix: prefix, active: active})
+}
+
+// isAnonymousGroup 群组是否允许未登录的客户端停留
+func isAnonymousGroup(groupId string) bool {
+	anonymousGroupMutex.RLock()
+	groups := anonymousGroups
+	anonymousGroupMutex.RUnlock()
+
+	for _, group := range groups {
+		if strings.HasPrefix(groupId, group.prefix) {
+			return group.active(groupId)
+		}
+	}
+
+	return false
+}
+
+// isAnonymousSpectator 客户端是否已加入群组, 且加入的群组都允许未登录的客户端停留
+func (c *Client) isAnonymousSpectator() bool {
+	// 检查群组是否仍在进行可能需要访问 redis, 复制后在锁外检查
+	groupMutex.Lock()
+	groupIds := slices.Clone(c.JoinGroup)
+	groupMutex.Unlock()
+
+	if len(groupIds) == 0 {
+		return false
+	}
+
+	for _, groupId := range groupIds {
+		if !isAnonymousGroup(groupId) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ClientAuthCheck 未认证连接的宽限检测, 宽限时长内未完成认证的客户端会被注销
+// 只加入进行中直播的观众不会被注销, 每个宽限时长重新检测一次, 直播结束或退出观看后仍需认证
+func ClientAuthCheck(clientID string) {
+	gracePeriod := config.Settings.Websocket.AuthGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultAuthGracePeriod
+	}
+
+	for {
+		time.Sleep(time.Duration(gracePeriod) * time.Second)
+
+		value, ok := ClientManagerInstance.Clients.Load(clientID)
+		if !ok {
+			return
+		}
+
+		client := value.(*Client)
+		if client.userId() != "" {
+			return
+		}
+
+		if client.isAnonymousSpectator() {
+			continue
+		}
+
+		client.sendError(&Envelope{}, ErrCodeUnauthorized, ErrAuthGracePeriodOver.Error())
+		ClientManagerInstance.Unregister <- client
+		return
+	}
+}
+
+// Reject 以关闭帧告知原因后注销客户端
+func (c *Client) Reject(reason string) {
+	message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+	_ = c.Conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+
+	ClientManagerInstance.Unregister <- c
+}
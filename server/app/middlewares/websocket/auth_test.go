@@ -0,0 +1,77 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenFromRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		target          string
+		authorization   string
+		protocol        string
+		wantToken       string
+		wantSubprotocol string
+	}{
+		{"没有令牌", "/ws", "", "", "", ""},
+		{"Bearer 请求头", "/ws", "Bearer abc", "", "abc", ""},
+		{"不带 Bearer 的请求头", "/ws", "abc", "", "abc", ""},
+		{"请求头优先于查询参数", "/ws?token=query", "Bearer header", "", "header", ""},
+		{"请求头优先于子协议", "/ws", "Bearer header", "token.protocol", "header", ""},
+		{"只有令牌子协议", "/ws", "", "token.abc", "abc", "token.abc"},
+		{"同时声明协议名称", "/ws", "", "puzzle, token.abc", "abc", ProtocolName},
+		{"协议名称在令牌之后", "/ws", "", "token.abc,puzzle", "abc", ProtocolName},
+		{"子协议优先于查询参数", "/ws?token=query", "", "token.abc", "abc", "token.abc"},
+		{"没有令牌的子协议", "/ws?token=query", "", "puzzle", "query", ""},
+		{"查询参数", "/ws?token=abc", "", "", "abc", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.target, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.protocol != "" {
+				r.Header.Set("Sec-WebSocket-Protocol", tt.protocol)
+			}
+
+			token, subprotocol := TokenFromRequest(r)
+			if token != tt.wantToken || subprotocol != tt.wantSubprotocol {
+				t.Errorf("TokenFromRequest() = (%q, %q), want (%q, %q)", token, subprotocol, tt.wantToken, tt.wantSubprotocol)
+			}
+		})
+	}
+}
+
+func TestIsAnonymousSpectator(t *testing.T) {
+	AllowAnonymousGroup("test-live:", func(groupId string) bool {
+		return groupId != "test-live:ended"
+	})
+
+	tests := []struct {
+		name   string
+		groups []string
+		want   bool
+	}{
+		{"没有加入群组", nil, false},
+		{"只加入进行中的直播", []string{"test-live:1", "test-live:2"}, true},
+		{"加入了其他群组", []string{"test-live:1", "race:1"}, false},
+		{"直播已结束", []string{"test-live:ended"}, false},
+		{"部分直播已结束", []string{"test-live:1", "test-live:ended"}, false},
+		{"只加入大厅频道", []string{"lobby"}, false},
+		{"同时加入直播与大厅频道", []string{"test-live:1", "lobby"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient()
+			c.JoinGroup = tt.groups
+
+			if got := c.isAnonymousSpectator(); got != tt.want {
+				t.Errorf("isAnonymousSpectator(%v) = %v, want %v", tt.groups, got, tt.want)
+			}
+		})
+	}
+}
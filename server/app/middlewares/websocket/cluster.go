@@ -0,0 +1,267 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"puzzle/database"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	fanoutChannel       = "ws:fanout" // 跨节点消息频道
+	nodeSetKey          = "ws:nodes"  // 节点ID集合
+	nodeKeyPrefix       = "ws:node:"  // 节点信息键前缀, 过期即视为节点下线
+	groupCountKeyPrefix = "ws:group:" // 群组在各节点的客户端数量键前缀
+	userCountKeyPrefix  = "ws:user:"  // 用户在各节点的连接数量键前缀
+
+	nodeTTL             = 30 * time.Second // 节点信息过期时长
+	nodeHeartbeatPeriod = 10 * time.Second // 节点信息刷新间隔
+)
+
+// 跨节点消息的投递范围
+const (
+	fanoutUser        = 1 // 用户的所有客户端
+	fanoutGroup       = 2 // 群组中的所有客户端
+	fanoutBroadcast   = 3 // 所有客户端
+	fanoutDeleteGroup = 4 // 解散群组
+)
+
+// NodeId 当前节点ID, 每次启动时生成
+var NodeId = uuid.New().String()
+
+// nodeStartedAt 当前节点启动时间
+var nodeStartedAt = time.Now()
+
+// Node 网关节点信息
+type Node struct {
+	Id          string    `json:"id"`          // 节点ID
+	Host        string    `json:"host"`        // 主机名
+	Clients     int       `json:"clients"`     // 客户端数量
+	Users       int       `json:"users"`       // 已认证的用户数量
+	StartedAt   time.Time `json:"startedAt"`   // 启动时间
+	HeartbeatAt time.Time `json:"heartbeatAt"` // 最后一次刷新时间
+}
+
+// fanoutMessage 跨节点消息, 由发送消息的节点发布, 其他节点投递给本地的客户端
+type fanoutMessage struct {
+	Node    string          `json:"node"`    // 发布消息的节点ID
+	Scope   int             `json:"scope"`   // 投递范围 1:用户 2:群组 3:全体 4:解散群组
+	Target  string          `json:"target"`  // 用户ID或群组ID
+	Type    string          `json:"type"`    // 消息类型
+	Payload json.RawMessage `json:"payload"` // 消息内容
+}
+
+var clusterOnce sync.Once
+
+// groupCountMutex, userCountMutex 保证当前节点依次同步数量, 避免较旧的数量覆盖较新的数量
+var groupCountMutex, userCountMutex sync.Mutex
+
+// StartCluster 注册当前节点并订阅跨节点消息
+func StartCluster() {
+	clusterOnce.Do(func() {
+		refreshNode()
+		go keepNodeAlive()
+		go subscribeFanout()
+	})
+}
+
+// StopCluster 注销当前节点
+func StopCluster() {
+	ctx := context.Background()
+	database.GetRedis().Del(ctx, nodeKeyPrefix+NodeId)
+	database.GetRedis().SRem(ctx, nodeSetKey, NodeId)
+}
+
+// Nodes 在线的网关节点, 同时清理已过期的节点
+func Nodes() ([]Node, error) {
+	ctx := context.Background()
+
+	nodeIds, err := database.GetRedis().SMembers(ctx, nodeSetKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]Node, 0, len(nodeIds))
+	for _, nodeId := range nodeIds {
+		value, err := database.GetRedis().Get(ctx, nodeKeyPrefix+nodeId).Result()
+		if err != nil {
+			database.GetRedis().SRem(ctx, nodeSetKey, nodeId)
+			continue
+		}
+
+		var node Node
+		if json.Unmarshal([]byte(value), &node) == nil {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes, nil
+}
+
+// refreshNode 刷新当前节点的信息与过期时间
+func refreshNode() {
+	host, _ := os.Hostname()
+
+	node := Node{
+		Id:          NodeId,
+		Host:        host,
+		StartedAt:   nodeStartedAt,
+		HeartbeatAt: time.Now(),
+	}
+
+	ClientManagerInstance.Clients.Range(func(key, value any) bool {
+		node.Clients++
+		return true
+	})
+
+	GatewayUser.Range(func(key, value any) bool {
+		node.Users++
+		return true
+	})
+
+	value, _ := json.Marshal(node)
+
+	ctx := context.Background()
+	if err := database.GetRedis().Set(ctx, nodeKeyPrefix+NodeId, value, nodeTTL).Err(); err != nil {
+		fmt.Println("刷新网关节点信息失败:", err.Error())
+		return
+	}
+
+	database.GetRedis().SAdd(ctx, nodeSetKey, NodeId)
+}
+
+// keepNodeAlive 定时刷新当前节点的信息
+func keepNodeAlive() {
+	ticker := time.NewTicker(nodeHeartbeatPeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		refreshNode()
+	}
+}
+
+// subscribeFanout 订阅跨节点消息, 投递给当前节点的客户端, 断线后由客户端自动重连
+func subscribeFanout() {
+	pubsub := database.GetRedis().Subscribe(context.Background(), fanoutChannel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		var message fanoutMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+			continue
+		}
+
+		// 发布消息的节点已直接投递
+		if message.Node == NodeId {
+			continue
+		}
+
+		switch message.Scope {
+		case fanoutUser:
+			sendToUserLocal(message.Target, message.Type, message.Payload)
+		case fanoutGroup:
+			sendToGroupLocal(message.Target, message.Type, message.Payload)
+		case fanoutBroadcast:
+			broadcastLocal(message.Type, message.Payload)
+		case fanoutDeleteGroup:
+			deleteGroupLocal(message.Target)
+		}
+	}
+}
+
+// publishFanout 发布跨节点消息
+func publishFanout(scope int, target string, messageType string, payload json.RawMessage) {
+	message, err := json.Marshal(&fanoutMessage{
+		Node:    NodeId,
+		Scope:   scope,
+		Target:  target,
+		Type:    messageType,
+		Payload: payload,
+	})
+	if err != nil {
+		return
+	}
+
+	if err = database.GetRedis().Publish(context.Background(), fanoutChannel, message).Err(); err != nil {
+		fmt.Println("发布跨节点消息失败:", err.Error())
+	}
+}
+
+// syncGroupCount 同步当前节点群组中的客户端数量, 用于统计所有节点的群组人数
+func syncGroupCount(groupIds ...string) {
+	groupCountMutex.Lock()
+	defer groupCountMutex.Unlock()
+
+	for _, groupId := range groupIds {
+		_ = setNodeCount(groupCountKeyPrefix+groupId, localGroupClientCount(groupId))
+	}
+}
+
+// syncUserCount 同步当前节点用户的连接数量, 用于限制用户在所有节点的连接数量
+func syncUserCount(userId string) {
+	userCountMutex.Lock()
+	defer userCountMutex.Unlock()
+
+	_ = setNodeCount(userCountKeyPrefix+userId, localUserClientCount(userId))
+}
+
+// clusterGroupClientCount 所有在线节点中群组的客户端数量, 查询失败时返回当前节点的数量
+func clusterGroupClientCount(groupId string) int {
+	total, err := clusterCount(groupCountKeyPrefix + groupId)
+	if err != nil {
+		return localGroupClientCount(groupId)
+	}
+
+	return total
+}
+
+// clusterUserClientCount 所有在线节点中用户的连接数量, 查询失败时返回当前节点的数量
+func clusterUserClientCount(userId string) int {
+	total, err := clusterCount(userCountKeyPrefix + userId)
+	if err != nil {
+		return localUserClientCount(userId)
+	}
+
+	return total
+}
+
+// setNodeCount 保存当前节点的数量, 数量为 0 时删除
+func setNodeCount(key string, count int) error {
+	ctx := context.Background()
+
+	if count == 0 {
+		return database.GetRedis().HDel(ctx, key, NodeId).Err()
+	}
+
+	return database.GetRedis().HSet(ctx, key, NodeId, count).Err()
+}
+
+// clusterCount 所有在线节点的数量之和, 同时清理下线节点残留的数量
+func clusterCount(key string) (int, error) {
+	ctx := context.Background()
+
+	counts, err := database.GetRedis().HGetAll(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for nodeId, value := range counts {
+		// 下线节点残留的数量不计入
+		if nodeId != NodeId && database.GetRedis().Exists(ctx, nodeKeyPrefix+nodeId).Val() == 0 {
+			database.GetRedis().HDel(ctx, key, nodeId)
+			continue
+		}
+
+		count, _ := strconv.Atoi(value)
+		total += count
+	}
+
+	return total, nil
+}
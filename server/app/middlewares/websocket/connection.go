@@ -19,7 +19,7 @@ func ClientHeartbeatCheck(clientID string) {
 		}
 
 		client, _ := clientInterface.(*Client)
-		if time.Since(client.LastHeartbeat) > HeartbeatTime {
+		if client.sinceHeartbeat() > HeartbeatTime {
 
 			fmt.Printf("Client %s heartbeat timeout", clientID)
 
@@ -30,8 +30,20 @@ func ClientHeartbeatCheck(clientID string) {
 	}
 }
 
+// heartbeat 记录心跳时间
+func (c *Client) heartbeat() {
+	c.lastHeartbeat.Store(time.Now().UnixNano())
+}
+
+// sinceHeartbeat 距最后一次心跳的时长
+func (c *Client) sinceHeartbeat() time.Duration {
+	return time.Since(time.Unix(0, c.lastHeartbeat.Load()))
+}
+
 // clientBindUid 客户端断线时自动踢出Uid绑定列表
 func clientUnBindUid(clientID string, uid string) {
+	userMutex.Lock()
+	defer userMutex.Unlock()
 
 	value, ok := GatewayUser.Load(uid)
 	if ok {
@@ -48,17 +60,22 @@ func clientUnBindUid(clientID string, uid string) {
 	}
 }
 
-// 客户端断线时自动踢出已加入的群组
-func clientLeaveGroup(clientID string) {
+// 客户端断线时自动踢出已加入的群组, 返回退出的群组, 由调用方同步群组人数
+func clientLeaveGroup(clientID string) []string {
 	// 使用 Load 方法获取值
 	value, ok := ClientManagerInstance.Clients.Load(clientID)
 	if !ok {
 		// 如果没有找到对应的值，处理相应的逻辑
-		return
+		return nil
 	}
 
 	client := value.(*Client)
 
+	groupMutex.Lock()
+	defer groupMutex.Unlock()
+
+	groupIds := append([]string(nil), client.JoinGroup...)
+
 	// 遍历 JoinGroup
 	for _, v := range client.JoinGroup {
 		// 使用 Load 方法获取值
@@ -85,4 +102,6 @@ func clientLeaveGroup(clientID string) {
 			}
 		}
 	}
+
+	return groupIds
 }
@@ -0,0 +1,46 @@
+package websocket
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	c := newTestClient()
+	c.lastHeartbeat.Store(time.Now().Add(-time.Hour).UnixNano())
+
+	c.dispatch([]byte(`{"type":"ping","content":""}`))
+
+	if got := c.sinceHeartbeat(); got > time.Minute {
+		t.Errorf("sinceHeartbeat() after ping = %v, want less than a minute", got)
+	}
+}
+
+// TestHeartbeatConcurrent 心跳消息与心跳检测在不同协程中读写, 需使用 -race 运行
+func TestHeartbeatConcurrent(t *testing.T) {
+	c := newTestClient()
+	c.heartbeat()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.heartbeat()
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if c.sinceHeartbeat() > HeartbeatTime {
+				t.Error("sinceHeartbeat() exceeded the heartbeat timeout")
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+}
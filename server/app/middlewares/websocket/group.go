@@ -0,0 +1,189 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"puzzle/database"
+	"sync"
+)
+
+// groupMutex 保护群组成员列表的并发修改
+var groupMutex sync.Mutex
+
+// JoinGroupById 客户端加入群组
+func (c *Client) JoinGroupById(groupId string) {
+	defer syncGroupCount(groupId)
+
+	groupMutex.Lock()
+	defer groupMutex.Unlock()
+
+	for _, v := range c.JoinGroup {
+		if v == groupId {
+			return
+		}
+	}
+
+	groupValue, _ := GatewayGroup.LoadOrStore(groupId, &WebSocketGroup{ClientID: make([]string, 0)})
+	group := groupValue.(*WebSocketGroup)
+	group.ClientID = append(group.ClientID, c.ID)
+
+	c.JoinGroup = append(c.JoinGroup, groupId)
+}
+
+// LeaveGroupById 客户端退出群组, 群组中没有成员时删除群组
+func (c *Client) LeaveGroupById(groupId string) {
+	defer syncGroupCount(groupId)
+
+	groupMutex.Lock()
+	defer groupMutex.Unlock()
+
+	for i, v := range c.JoinGroup {
+		if v == groupId {
+			c.JoinGroup = append(c.JoinGroup[:i], c.JoinGroup[i+1:]...)
+			break
+		}
+	}
+
+	groupValue, ok := GatewayGroup.Load(groupId)
+	if !ok {
+		return
+	}
+
+	group := groupValue.(*WebSocketGroup)
+	for i, id := range group.ClientID {
+		if id == c.ID {
+			group.ClientID = append(group.ClientID[:i], group.ClientID[i+1:]...)
+			break
+		}
+	}
+
+	if len(group.ClientID) == 0 {
+		GatewayGroup.Delete(groupId)
+	}
+}
+
+// InGroup 客户端是否已加入群组
+func (c *Client) InGroup(groupId string) bool {
+	groupMutex.Lock()
+	defer groupMutex.Unlock()
+
+	for _, v := range c.JoinGroup {
+		if v == groupId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GroupClientCount 所有节点中群组的客户端数量
+func GroupClientCount(groupId string) int {
+	return clusterGroupClientCount(groupId)
+}
+
+// localGroupClientCount 当前节点中群组的客户端数量
+func localGroupClientCount(groupId string) int {
+	groupMutex.Lock()
+	defer groupMutex.Unlock()
+
+	groupValue, ok := GatewayGroup.Load(groupId)
+	if !ok {
+		return 0
+	}
+
+	return len(groupValue.(*WebSocketGroup).ClientID)
+}
+
+// SendToGroup 向所有节点中群组的客户端发送消息
+func SendToGroup(groupId string, messageType string, content string) {
+	payload := payloadOf(content)
+
+	sendToGroupLocal(groupId, messageType, payload)
+	publishFanout(fanoutGroup, groupId, messageType, payload)
+}
+
+// sendToGroupLocal 向当前节点中群组的客户端发送消息
+func sendToGroupLocal(groupId string, messageType string, payload json.RawMessage) {
+	groupMutex.Lock()
+	groupValue, ok := GatewayGroup.Load(groupId)
+	var clientIds []string
+	if ok {
+		clientIds = append(clientIds, groupValue.(*WebSocketGroup).ClientID...)
+	}
+	groupMutex.Unlock()
+
+	for _, clientId := range clientIds {
+		if value, ok := ClientManagerInstance.Clients.Load(clientId); ok {
+			value.(*Client).send(messageType, payload, "")
+		}
+	}
+}
+
+// SendToUser 向用户在所有节点的客户端发送消息
+func SendToUser(userId string, messageType string, content string) {
+	payload := payloadOf(content)
+
+	sendToUserLocal(userId, messageType, payload)
+	publishFanout(fanoutUser, userId, messageType, payload)
+}
+
+// sendToUserLocal 向用户在当前节点的客户端发送消息
+func sendToUserLocal(userId string, messageType string, payload json.RawMessage) {
+	userMutex.Lock()
+	userValue, ok := GatewayUser.Load(userId)
+	var clientIds []string
+	if ok {
+		clientIds = append(clientIds, userValue.(*WebSocketUser).ClientID...)
+	}
+	userMutex.Unlock()
+
+	for _, clientId := range clientIds {
+		if value, ok := ClientManagerInstance.Clients.Load(clientId); ok {
+			value.(*Client).send(messageType, payload, "")
+		}
+	}
+}
+
+// SendJSON 将内容序列化为 JSON 后发送给客户端
+func (c *Client) SendJSON(messageType string, content any) bool {
+	message, err := json.Marshal(content)
+	if err != nil {
+		return false
+	}
+
+	return c.send(messageType, message, "")
+}
+
+// DeleteGroup 解散所有节点中的群组, 所有客户端退出该群组
+func DeleteGroup(groupId string) {
+	deleteGroupLocal(groupId)
+	publishFanout(fanoutDeleteGroup, groupId, "", nil)
+
+	database.GetRedis().Del(context.Background(), groupCountKeyPrefix+groupId)
+}
+
+// deleteGroupLocal 解散当前节点中的群组
+func deleteGroupLocal(groupId string) {
+	groupMutex.Lock()
+	defer groupMutex.Unlock()
+
+	groupValue, ok := GatewayGroup.LoadAndDelete(groupId)
+	if !ok {
+		return
+	}
+
+	for _, clientId := range groupValue.(*WebSocketGroup).ClientID {
+		value, ok := ClientManagerInstance.Clients.Load(clientId)
+		if !ok {
+			continue
+		}
+
+		client := value.(*Client)
+		for i, v := range client.JoinGroup {
+			if v == groupId {
+				client.JoinGroup = append(client.JoinGroup[:i], client.JoinGroup[i+1:]...)
+				break
+			}
+		}
+	}
+}
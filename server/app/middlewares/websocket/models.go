@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -12,10 +13,11 @@ type Client struct {
 	ID            string
 	Conn          *websocket.Conn // 连接
 	Send          chan []byte     // 发送消息
-	Message       *Message        // 消息
-	LastHeartbeat time.Time       // 最后一次心跳时间
+	Done          chan struct{}   // 客户端关闭时关闭, 用于通知后台推送的协程退出
+	lastHeartbeat atomic.Int64    // 最后一次心跳时间(Unix 纳秒), 读取与心跳消息在不同协程中
 	BindUserId    string          // 绑定用户ID
 	JoinGroup     []string        // 加入的群组
+	version       atomic.Int32    // 客户端使用的协议版本 0:旧版 1:信封
 }
 
 // ClientManager 客户端管理
@@ -39,6 +41,7 @@ type WebSocketGroup struct {
 	ClientID []string
 }
 
+// Message 旧版消息格式
 type Message struct {
 	Type    string `json:"type"`    // 消息类型
 	Content string `json:"content"` // 消息
@@ -46,6 +49,9 @@ type Message struct {
 
 var GatewayUser, GatewayGroup sync.Map
 
+// handlers 消息处理函数, 键为消息类型, 值为 Handler
+var handlers sync.Map
+
 var ClientManagerInstance = ClientManager{
 	Clients:    sync.Map{},
 	Broadcast:  make(chan []byte, 1024),
@@ -0,0 +1,227 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ProtocolVersion 当前消息信封版本
+// 客户端发送的消息带有 v 字段时, 服务端对该客户端改用信封格式发送, 否则沿用旧版 {type, content} 格式
+const ProtocolVersion = 1
+
+// 协议内置的消息类型
+const (
+	MessageTypeAck   = "ack"   // 服务端确认收到消息
+	MessageTypeError = "error" // 错误帧
+)
+
+// 错误帧的错误码
+const (
+	ErrCodeBadMessage   = 1 // 消息格式错误
+	ErrCodeUnknownType  = 2 // 未知的消息类型
+	ErrCodeUnauthorized = 3 // 未认证或认证失败
+	ErrCodeHandler      = 4 // 业务处理失败
+	ErrCodeVersion      = 5 // 不支持的协议版本
+)
+
+// Envelope 消息信封
+// 客户端请求携带 id, 服务端的确认、回复与错误帧通过 correlationId 关联到该请求
+type Envelope struct {
+	Version       int             `json:"v"`                       // 协议版本
+	Id            string          `json:"id,omitempty"`            // 消息ID
+	Type          string          `json:"type"`                    // 消息类型
+	Payload       json.RawMessage `json:"payload,omitempty"`       // 消息内容
+	CorrelationId string          `json:"correlationId,omitempty"` // 关联的请求消息ID
+}
+
+// ErrorPayload 错误帧的消息内容
+type ErrorPayload struct {
+	Code    int    `json:"code"`    // 错误码
+	Message string `json:"message"` // 错误信息
+	Type    string `json:"type"`    // 出错的请求消息类型
+}
+
+// Error 业务处理函数返回的错误, 会以对应错误码发送错误帧
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError 创建业务处理错误
+func NewError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Context 一条客户端消息的处理上下文
+type Context struct {
+	Client   *Client   // 发送消息的客户端
+	Envelope *Envelope // 消息信封, 旧版消息的内容以 JSON 字符串保存在 Payload 中
+}
+
+// Handler 业务消息处理函数, 返回错误时向客户端发送错误帧
+type Handler func(ctx *Context) error
+
+// Handle 注册业务消息处理函数, 同一消息类型重复注册时覆盖
+func Handle(messageType string, handler Handler) {
+	handlers.Store(messageType, handler)
+}
+
+// LegacyError 旧版客户端的处理错误以 errorType 类型的消息发送, 兼容按业务区分错误消息的旧版客户端
+// 信封格式的客户端仍然收到带错误码的错误帧
+func LegacyError(errorType string, handler Handler) Handler {
+	return func(ctx *Context) error {
+		err := handler(ctx)
+		if err == nil || ctx.Client.version.Load() != 0 {
+			return err
+		}
+
+		ctx.Reply(errorType, err.Error())
+
+		return nil
+	}
+}
+
+// Content 消息内容的字符串形式, 内容为 JSON 字符串时返回其值, 否则返回原始 JSON
+func (ctx *Context) Content() string {
+	var content string
+	if json.Unmarshal(ctx.Envelope.Payload, &content) == nil {
+		return content
+	}
+
+	return string(ctx.Envelope.Payload)
+}
+
+// Bind 将消息内容解析到 v, 兼容旧版消息中以字符串传递的 JSON
+func (ctx *Context) Bind(v any) error {
+	err := json.Unmarshal([]byte(ctx.Content()), v)
+	if err != nil {
+		return NewError(ErrCodeBadMessage, "参数错误")
+	}
+
+	return nil
+}
+
+// Reply 回复当前消息, 信封格式下携带请求的消息ID作为 correlationId
+func (ctx *Context) Reply(messageType string, payload any) bool {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+
+	return ctx.Client.send(messageType, content, ctx.Envelope.Id)
+}
+
+// Fail 回复当前消息的错误帧
+func (ctx *Context) Fail(code int, message string) bool {
+	return ctx.Reply(MessageTypeError, ErrorPayload{
+		Code:    code,
+		Message: message,
+		Type:    ctx.Envelope.Type,
+	})
+}
+
+// dispatch 解析客户端消息并交给对应的处理函数
+// 信封格式的消息在处理前回复确认, 解析失败、类型未知与处理失败时回复错误帧
+func (c *Client) dispatch(message []byte) {
+	envelope := new(Envelope)
+	if err := json.Unmarshal(message, envelope); err != nil {
+		c.sendError(&Envelope{}, ErrCodeBadMessage, "消息格式错误")
+		return
+	}
+
+	switch envelope.Version {
+	case 0: // 旧版消息, 内容以字符串保存在 content 中
+		legacy := new(Message)
+		_ = json.Unmarshal(message, legacy)
+		envelope.Payload, _ = json.Marshal(legacy.Content)
+	case ProtocolVersion:
+		if envelope.Payload == nil {
+			envelope.Payload = json.RawMessage("null")
+		}
+	default:
+		c.sendError(envelope, ErrCodeVersion, fmt.Sprintf("不支持的协议版本: %d", envelope.Version))
+		return
+	}
+
+	c.version.Store(int32(envelope.Version))
+
+	value, ok := handlers.Load(envelope.Type)
+	if !ok {
+		c.sendError(envelope, ErrCodeUnknownType, "未知的消息类型: "+envelope.Type)
+		return
+	}
+
+	if envelope.Id != "" {
+		c.send(MessageTypeAck, nil, envelope.Id)
+	}
+
+	err := value.(Handler)(&Context{Client: c, Envelope: envelope})
+	if err == nil {
+		return
+	}
+
+	var handlerErr *Error
+	if errors.As(err, &handlerErr) {
+		c.sendError(envelope, handlerErr.Code, handlerErr.Message)
+	} else {
+		c.sendError(envelope, ErrCodeHandler, err.Error())
+	}
+}
+
+// sendError 发送错误帧, 旧版客户端收到的内容为错误信息
+func (c *Client) sendError(envelope *Envelope, code int, message string) bool {
+	if c.version.Load() == 0 {
+		content, _ := json.Marshal(message)
+		return c.send(MessageTypeError, content, "")
+	}
+
+	content, _ := json.Marshal(ErrorPayload{
+		Code:    code,
+		Message: message,
+		Type:    envelope.Type,
+	})
+
+	return c.send(MessageTypeError, content, envelope.Id)
+}
+
+// encode 按客户端使用的协议版本编码消息, payload 为 JSON
+func (c *Client) encode(messageType string, payload json.RawMessage, correlationId string) ([]byte, error) {
+	if c.version.Load() == 0 {
+		// 旧版消息的内容为字符串, 字符串以外的 JSON 原样作为字符串发送
+		var content string
+		if json.Unmarshal(payload, &content) != nil {
+			content = string(payload)
+		}
+
+		return json.Marshal(&Message{
+			Type:    messageType,
+			Content: content,
+		})
+	}
+
+	return json.Marshal(&Envelope{
+		Version:       ProtocolVersion,
+		Id:            uuid.New().String(),
+		Type:          messageType,
+		Payload:       payload,
+		CorrelationId: correlationId,
+	})
+}
+
+// payloadOf 将旧版的字符串内容转换为信封的消息内容, 内容本身为 JSON 对象或数组时原样使用
+func payloadOf(content string) json.RawMessage {
+	if len(content) > 0 && (content[0] == '{' || content[0] == '[') && json.Valid([]byte(content)) {
+		return json.RawMessage(content)
+	}
+
+	payload, _ := json.Marshal(content)
+
+	return payload
+}
@@ -0,0 +1,286 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// frame 客户端收到的一条消息, 同时兼容旧版与信封格式
+type frame struct {
+	Version       int             `json:"v"`
+	Type          string          `json:"type"`
+	Content       string          `json:"content"`
+	Payload       json.RawMessage `json:"payload"`
+	CorrelationId string          `json:"correlationId"`
+}
+
+// newTestClient 创建不依赖连接的客户端, 发送的消息保存在 Send 中
+func newTestClient() *Client {
+	return &Client{
+		ID:   "test-client",
+		Send: make(chan []byte, 16),
+		Done: make(chan struct{}),
+	}
+}
+
+// received 取出客户端已收到的所有消息
+func received(t *testing.T, c *Client) []frame {
+	t.Helper()
+
+	frames := make([]frame, 0)
+	for {
+		select {
+		case message := <-c.Send:
+			var f frame
+			if err := json.Unmarshal(message, &f); err != nil {
+				t.Fatalf("unmarshal %s: %v", message, err)
+			}
+			if f.Payload != nil {
+				compacted, _ := json.Marshal(f.Payload)
+				f.Payload = compacted
+			}
+			frames = append(frames, f)
+		default:
+			return frames
+		}
+	}
+}
+
+func init() {
+	Handle("test-echo", func(ctx *Context) error {
+		ctx.Reply("test-echo", ctx.Content())
+		return nil
+	})
+	Handle("test-bind", func(ctx *Context) error {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := ctx.Bind(&req); err != nil {
+			return err
+		}
+		ctx.Reply("test-bind", req)
+		return nil
+	})
+	Handle("test-coded", func(ctx *Context) error {
+		return NewError(ErrCodeUnauthorized, "未登录")
+	})
+	Handle("test-fail", func(ctx *Context) error {
+		return errors.New("处理失败")
+	})
+	Handle("test-legacy", LegacyError("test-error", func(ctx *Context) error {
+		return errors.New("旧版错误")
+	}))
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []frame
+		version int32 // 处理后客户端的协议版本
+	}{
+		{
+			"旧版消息原样回复",
+			`{"type":"test-echo","content":"hello"}`,
+			[]frame{{Type: "test-echo", Content: "hello"}},
+			0,
+		},
+		{
+			"旧版消息以字符串传递 JSON",
+			`{"type":"test-bind","content":"{\"name\":\"puzzle\"}"}`,
+			[]frame{{Type: "test-bind", Content: `{"name":"puzzle"}`}},
+			0,
+		},
+		{
+			"信封消息先确认再回复",
+			`{"v":1,"id":"m1","type":"test-bind","payload":{"name":"puzzle"}}`,
+			[]frame{
+				{Version: 1, Type: MessageTypeAck, CorrelationId: "m1"},
+				{Version: 1, Type: "test-bind", Payload: json.RawMessage(`{"name":"puzzle"}`), CorrelationId: "m1"},
+			},
+			1,
+		},
+		{
+			"信封消息没有ID时不确认",
+			`{"v":1,"type":"test-echo","payload":"hi"}`,
+			[]frame{{Version: 1, Type: "test-echo", Payload: json.RawMessage(`"hi"`)}},
+			1,
+		},
+		{
+			"信封消息未知类型",
+			`{"v":1,"id":"m2","type":"test-missing"}`,
+			[]frame{{Version: 1, Type: MessageTypeError, Payload: json.RawMessage(`{"code":2,"message":"未知的消息类型: test-missing","type":"test-missing"}`), CorrelationId: "m2"}},
+			1,
+		},
+		{
+			"旧版消息未知类型",
+			`{"type":"test-missing","content":""}`,
+			[]frame{{Type: MessageTypeError, Content: "未知的消息类型: test-missing"}},
+			0,
+		},
+		{
+			"不支持的协议版本",
+			`{"v":2,"id":"m3","type":"test-echo"}`,
+			[]frame{{Type: MessageTypeError, Content: "不支持的协议版本: 2"}},
+			0,
+		},
+		{
+			"消息格式错误",
+			`not json`,
+			[]frame{{Type: MessageTypeError, Content: "消息格式错误"}},
+			0,
+		},
+		{
+			"参数解析失败",
+			`{"v":1,"id":"m4","type":"test-bind","payload":"bad"}`,
+			[]frame{
+				{Version: 1, Type: MessageTypeAck, CorrelationId: "m4"},
+				{Version: 1, Type: MessageTypeError, Payload: json.RawMessage(`{"code":1,"message":"参数错误","type":"test-bind"}`), CorrelationId: "m4"},
+			},
+			1,
+		},
+		{
+			"业务错误码",
+			`{"v":1,"id":"m5","type":"test-coded"}`,
+			[]frame{
+				{Version: 1, Type: MessageTypeAck, CorrelationId: "m5"},
+				{Version: 1, Type: MessageTypeError, Payload: json.RawMessage(`{"code":3,"message":"未登录","type":"test-coded"}`), CorrelationId: "m5"},
+			},
+			1,
+		},
+		{
+			"普通错误使用处理失败错误码",
+			`{"v":1,"id":"m6","type":"test-fail"}`,
+			[]frame{
+				{Version: 1, Type: MessageTypeAck, CorrelationId: "m6"},
+				{Version: 1, Type: MessageTypeError, Payload: json.RawMessage(`{"code":4,"message":"处理失败","type":"test-fail"}`), CorrelationId: "m6"},
+			},
+			1,
+		},
+		{
+			"旧版客户端收到业务错误消息",
+			`{"type":"test-legacy","content":""}`,
+			[]frame{{Type: "test-error", Content: "旧版错误"}},
+			0,
+		},
+		{
+			"信封客户端收到错误帧",
+			`{"v":1,"id":"m7","type":"test-legacy"}`,
+			[]frame{
+				{Version: 1, Type: MessageTypeAck, CorrelationId: "m7"},
+				{Version: 1, Type: MessageTypeError, Payload: json.RawMessage(`{"code":4,"message":"旧版错误","type":"test-legacy"}`), CorrelationId: "m7"},
+			},
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient()
+
+			c.dispatch([]byte(tt.message))
+
+			if got := received(t, c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dispatch(%s) = %+v, want %+v", tt.message, got, tt.want)
+			}
+			if got := c.version.Load(); got != tt.version {
+				t.Errorf("dispatch(%s) version = %d, want %d", tt.message, got, tt.version)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       int32
+		payload       string
+		correlationId string
+		want          frame
+	}{
+		{"旧版字符串", 0, `"hello"`, "", frame{Type: "test", Content: "hello"}},
+		{"旧版对象以字符串发送", 0, `{"a":1}`, "", frame{Type: "test", Content: `{"a":1}`}},
+		{"旧版忽略关联ID", 0, `"hello"`, "m1", frame{Type: "test", Content: "hello"}},
+		{"信封字符串", 1, `"hello"`, "", frame{Version: 1, Type: "test", Payload: json.RawMessage(`"hello"`)}},
+		{"信封对象", 1, `{"a":1}`, "m1", frame{Version: 1, Type: "test", Payload: json.RawMessage(`{"a":1}`), CorrelationId: "m1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient()
+			c.version.Store(tt.version)
+
+			message, err := c.encode("test", json.RawMessage(tt.payload), tt.correlationId)
+			if err != nil {
+				t.Fatalf("encode(%s) error = %v", tt.payload, err)
+			}
+
+			var got frame
+			if err = json.Unmarshal(message, &got); err != nil {
+				t.Fatalf("unmarshal %s: %v", message, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encode(%s) = %+v, want %+v", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeEnvelopeId(t *testing.T) {
+	c := newTestClient()
+	c.version.Store(ProtocolVersion)
+
+	first, _ := c.encode("test", json.RawMessage(`"a"`), "")
+	second, _ := c.encode("test", json.RawMessage(`"a"`), "")
+
+	var a, b Envelope
+	_ = json.Unmarshal(first, &a)
+	_ = json.Unmarshal(second, &b)
+
+	if a.Id == "" || a.Id == b.Id {
+		t.Errorf("encode ids = %q, %q, want unique non-empty ids", a.Id, b.Id)
+	}
+}
+
+func TestPayloadOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"字符串", "hello", `"hello"`},
+		{"空字符串", "", `""`},
+		{"对象", `{"a":1}`, `{"a":1}`},
+		{"数组", `[1,2]`, `[1,2]`},
+		{"数字作为字符串", "12", `"12"`},
+		{"不合法的 JSON 作为字符串", `{"a":`, `"{\"a\":"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(payloadOf(tt.content)); got != tt.want {
+				t.Errorf("payloadOf(%q) = %s, want %s", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadOfRoundTrip(t *testing.T) {
+	contents := []string{"hello", "", `{"a":1}`, `[1,2]`, `{"a":`}
+
+	for _, content := range contents {
+		c := newTestClient()
+
+		message, _ := c.encode("test", payloadOf(content), "")
+
+		var got Message
+		if err := json.Unmarshal(message, &got); err != nil {
+			t.Fatalf("unmarshal %s: %v", message, err)
+		}
+		if got.Content != content {
+			t.Errorf("round trip %q = %q", content, got.Content)
+		}
+	}
+}
@@ -3,9 +3,6 @@ package websocket
 import (
 	"encoding/json"
 	"log"
-	jwt "puzzle/utils/jwt"
-	"strconv"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -23,14 +20,14 @@ func InitClient(conn *websocket.Conn) *Client {
 	clientID := uuid.New().String()
 
 	client := &Client{
-		ID:            clientID,
-		Conn:          conn,
-		Send:          make(chan []byte, 1024),
-		Message:       new(Message),
-		BindUserId:    "",
-		JoinGroup:     make([]string, 0),
-		LastHeartbeat: time.Now(),
+		ID:         clientID,
+		Conn:       conn,
+		Send:       make(chan []byte, 1024),
+		Done:       make(chan struct{}),
+		BindUserId: "",
+		JoinGroup:  make([]string, 0),
 	}
+	client.heartbeat()
 
 	// 注册客户端
 	ClientManagerInstance.Register <- client
@@ -60,7 +57,7 @@ func (manager *ClientManager) Start() {
 		case conn := <-manager.Register:
 			manager.Clients.Store(conn.ID, conn)
 		case conn := <-manager.Unregister:
-			if _, ok := manager.Clients.Load(conn); ok {
+			if _, ok := manager.Clients.Load(conn.ID); ok {
 				conn.CloseClient()
 			}
 
@@ -83,17 +80,27 @@ func (manager *ClientManager) Start() {
 func (c *Client) CloseClient() {
 	if value, ok := ClientManagerInstance.Clients.Load(c.ID); ok {
 		client := value.(*Client)
-		if client.BindUserId != "" {
-			clientUnBindUid(c.ID, client.BindUserId)
+		userId := client.userId()
+		if userId != "" {
+			clientUnBindUid(c.ID, userId)
 		}
 
-		if len(client.JoinGroup) > 0 {
-			clientLeaveGroup(c.ID)
-		}
+		// 群组在 groupMutex 保护下复制与退出, 不在锁外读取 JoinGroup
+		groupIds := clientLeaveGroup(c.ID)
 
 		ClientManagerInstance.Clients.Delete(c.ID)
+
+		// 同步 redis 中的数量需要网络请求, 不阻塞客户端管理的协程
+		go func() {
+			if userId != "" {
+				syncUserCount(userId)
+			}
+
+			syncGroupCount(groupIds...)
+		}()
 	}
 
+	close(c.Done)  // 通知后台推送的协程退出
 	close(c.Send)  // 关闭发送消息通道
 	c.Conn.Close() // 关闭连接
 }
@@ -108,8 +115,12 @@ func (c *Client) Writer() {
 	}
 }
 
-// reader 读取消息
+// reader 读取消息, 连接断开后注销客户端
 func (c *Client) Reader(manager *ClientManager) {
+	defer func() {
+		manager.Unregister <- c // 已注销的客户端会被忽略
+	}()
+
 	for {
 		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
@@ -117,37 +128,122 @@ func (c *Client) Reader(manager *ClientManager) {
 			return
 		}
 
-		json.Unmarshal(message, c.Message)
+		c.dispatch(message)
+	}
+}
 
-		switch c.Message.Type {
-		case "ping": // 心跳消息
-			message, _ := json.Marshal(&Message{
-				Type:    "pong",
-				Content: "pong",
-			})
-			c.Send <- message            // 回复心跳消息
-			c.LastHeartbeat = time.Now() // 更新心跳时间
-
-		case "auth": // 认证消息
-			claims, _ := jwt.ParseToken(c.Message.Content)
-			if claims != nil && claims.Id != 0 { // 认证成功
-				userId := strconv.FormatInt(claims.Id, 10)
-				c.bindUserId(userId) // 绑定用户ID
-			} else { // 认证失败
-				message, _ := json.Marshal(&Message{
-					Type:    "auth",
-					Content: "登录信息有误，请重新登录",
-				})
-				c.Send <- message       // 回复认证消息
-				manager.Unregister <- c // 注销客户端
-			}
+func init() {
+	Handle("ping", ping) // 心跳消息
+	Handle("auth", auth) // 认证消息
+}
+
+// ping 回复心跳消息并更新心跳时间
+func ping(ctx *Context) error {
+	ctx.Reply("pong", "pong")
+	ctx.Client.heartbeat()
+
+	return nil
+}
+
+// auth 认证消息, 兼容握手时未携带令牌的客户端, 认证成功时绑定用户ID, 失败时注销客户端
+func auth(ctx *Context) error {
+	userId, err := Authenticate(ctx.Content())
+	if err == nil {
+		err = ctx.Client.BindUser(userId) // 绑定用户ID
+	}
+
+	if err != nil {
+		if ctx.Client.version.Load() == 0 {
+			ctx.Reply("auth", err.Error())
+		} else {
+			ctx.Fail(ErrCodeUnauthorized, err.Error())
 		}
+
+		ClientManagerInstance.Unregister <- ctx.Client // 注销客户端
 	}
+
+	return nil
 }
 
-// clientUnBindUid 客户端解绑用户ID
-func (c *Client) bindUserId(userId string) {
-	c.BindUserId = userId
+// SendMessage 向客户端发送消息, 客户端已关闭或发送缓冲区已满时返回 false
+// 内容为 JSON 对象或数组时, 信封格式下作为对象发送
+func (c *Client) SendMessage(messageType string, content string) bool {
+	return c.send(messageType, payloadOf(content), "")
+}
+
+// Broadcast 向所有节点的客户端发送消息
+func Broadcast(messageType string, content string) {
+	payload := payloadOf(content)
+
+	broadcastLocal(messageType, payload)
+	publishFanout(fanoutBroadcast, "", messageType, payload)
+}
+
+// broadcastLocal 向当前节点的所有客户端发送消息
+func broadcastLocal(messageType string, payload json.RawMessage) {
+	ClientManagerInstance.Clients.Range(func(key, value any) bool {
+		value.(*Client).send(messageType, payload, "")
+		return true
+	})
+}
+
+// send 按客户端的协议版本编码并发送消息
+func (c *Client) send(messageType string, payload json.RawMessage, correlationId string) (ok bool) {
+	message, err := c.encode(messageType, payload, correlationId)
+	if err != nil {
+		return false
+	}
+
+	// 客户端关闭后发送通道已关闭
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	select {
+	case <-c.Done:
+		return false
+	case c.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// BindUser 将客户端绑定到用户, 用户在所有节点的连接数量达到上限或客户端已绑定其他用户时返回错误
+func (c *Client) BindUser(userId string) error {
+	// 当前节点的绑定依次进行, 先在 redis 中预占连接, 其他节点同时绑定时也不会超过上限
+	userCountMutex.Lock()
+	defer userCountMutex.Unlock()
+
+	boundUserId := c.userId()
+	if boundUserId == userId {
+		return nil
+	}
+
+	if boundUserId != "" {
+		return ErrBoundToAnotherUser
+	}
+
+	key := userCountKeyPrefix + userId
+	count := localUserClientCount(userId) + 1
+
+	// redis 不可用时只按当前节点的连接数量限制
+	total := count
+	if setNodeCount(key, count) == nil {
+		if clusterTotal, err := clusterCount(key); err == nil {
+			total = clusterTotal
+		}
+	}
+
+	if total > maxConnectionsPerUser() {
+		_ = setNodeCount(key, localUserClientCount(userId))
+		return ErrTooManyConnections
+	}
+
+	userMutex.Lock()
+	defer userMutex.Unlock()
 
 	// 绑定用户ID
 	userBase, ok := GatewayUser.Load(userId)
@@ -159,6 +255,17 @@ func (c *Client) bindUserId(userId string) {
 	}
 
 	userBase.(*WebSocketUser).ClientID = append(userBase.(*WebSocketUser).ClientID, c.ID)
+	c.BindUserId = userId
 
 	GatewayUser.Store(userId, userBase)
+
+	return nil
+}
+
+// userId 客户端绑定的用户ID, 未认证时为空
+func (c *Client) userId() string {
+	userMutex.Lock()
+	defer userMutex.Unlock()
+
+	return c.BindUserId
 }
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// ChatMessage 聊天消息模型
+// 频道 lobby:大厅 race:<房间ID>:竞速房间 live:<对局ID>:直播与对战, 与 WebSocket 群组一致
+type ChatMessage struct {
+	Id        int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	Channel   string    `json:"channel"`                         // 频道
+	UserId    int64     `json:"userId"`                          // 发送者用户ID
+	Content   string    `json:"content"`                         // 消息内容(已过滤敏感词)
+	Status    int       `json:"status" gorm:"default 1"`         // 状态 1:正常 2:已删除
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// ChatSendReq 发送聊天消息请求模型
+type ChatSendReq struct {
+	Channel string `json:"channel"` // 频道
+	Content string `json:"content"` // 消息内容
+}
+
+// ChatChannelReq 加入或退出频道请求模型
+type ChatChannelReq struct {
+	Channel string `json:"channel"` // 频道
+}
+
+// ChatMessageReq 聊天消息请求模型
+type ChatMessageReq struct {
+	Id       int64 `json:"-"` // 主键ID
+	UserId   int64 `json:"-"` // 发送者用户ID
+	BeforeId int64 `json:"-"` // 只查询该消息之前的消息
+
+	IdStr       string           `json:"id"`        // 主键ID
+	UserIdStr   string           `json:"userId"`    // 发送者用户ID
+	BeforeIdStr string           `json:"beforeId"`  // 只查询该消息之前的消息
+	Channel     string           `json:"channel"`   // 频道
+	Content     string           `json:"content"`   // 消息内容
+	Status      int              `json:"status"`    // 状态 1:正常 2:已删除
+	DateRange   []time.Time      `json:"dateRange"` // 日期范围
+	Pagination  utils.Pagination `gorm:"embedded"`  // 分页
+}
+
+// ChatMessageResp 聊天消息响应模型
+type ChatMessageResp struct {
+	Id        string    `json:"id"`                                              // 主键ID
+	Channel   string    `json:"channel"`                                         // 频道
+	UserId    string    `json:"userId"`                                          // 发送者用户ID
+	Content   string    `json:"content"`                                         // 消息内容
+	Status    int       `json:"status"`                                          // 状态 1:正常 2:已删除
+	CreatedAt time.Time `json:"createdAt"`                                       // 创建时间
+	UserInfo  UserResp  `json:"userInfo" gorm:"foreignKey:Id;references:UserId"` // 发送者信息
+}
+
+// ChatMessageListResp 聊天消息列表响应模型
+type ChatMessageListResp struct {
+	Total   int64             `json:"total"`   // 总数
+	Records []ChatMessageResp `json:"records"` // 消息列表
+}
+
+// ChatRestriction 聊天限制模型
+type ChatRestriction struct {
+	Id        int64      `json:"id" gorm:"primaryKey"`            // 主键ID
+	UserId    int64      `json:"userId"`                          // 用户ID
+	Type      int        `json:"type"`                            // 类型 1:禁言 2:封禁
+	Reason    string     `json:"reason"`                          // 原因
+	ExpiredAt *time.Time `json:"expiredAt"`                       // 到期时间 为空时永久
+	Status    int        `json:"status" gorm:"default 1"`         // 状态 1:生效中 2:已解除
+	CreatedAt time.Time  `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt time.Time  `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// ChatRestrictionReq 聊天限制请求模型
+type ChatRestrictionReq struct {
+	Id     int64 `json:"-"` // 主键ID
+	UserId int64 `json:"-"` // 用户ID
+
+	IdStr      string           `json:"id"`       // 主键ID
+	UserIdStr  string           `json:"userId"`   // 用户ID
+	Type       int              `json:"type"`     // 类型 1:禁言 2:封禁
+	Reason     string           `json:"reason"`   // 原因
+	Duration   int              `json:"duration"` // 时长(秒) 0:永久
+	Status     int              `json:"status"`   // 状态 1:生效中 2:已解除
+	Pagination utils.Pagination `gorm:"embedded"` // 分页
+}
+
+// ChatRestrictionResp 聊天限制响应模型
+type ChatRestrictionResp struct {
+	Id        string     `json:"id"`                                              // 主键ID
+	UserId    string     `json:"userId"`                                          // 用户ID
+	Type      int        `json:"type"`                                            // 类型 1:禁言 2:封禁
+	Reason    string     `json:"reason"`                                          // 原因
+	ExpiredAt *time.Time `json:"expiredAt"`                                       // 到期时间 为空时永久
+	Status    int        `json:"status"`                                          // 状态 1:生效中 2:已解除
+	CreatedAt time.Time  `json:"createdAt"`                                       // 创建时间
+	UpdatedAt time.Time  `json:"updatedAt"`                                       // 更新时间
+	UserInfo  UserResp   `json:"userInfo" gorm:"foreignKey:Id;references:UserId"` // 用户信息
+}
+
+// ChatRestrictionListResp 聊天限制列表响应模型
+type ChatRestrictionListResp struct {
+	Total   int64                 `json:"total"`   // 总数
+	Records []ChatRestrictionResp `json:"records"` // 限制列表
+}
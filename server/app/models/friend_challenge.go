@@ -0,0 +1,110 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// FriendChallenge 好友挑战模型, 发起者指定打乱与截止时间, 双方在截止前任意时间完成
+// 双方都提交或超过截止时间后结算, 结算前不公开对方成绩
+type FriendChallenge struct {
+	Id                 int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	ChallengerId       int64     `json:"challengerId"`                    // 发起者用户ID
+	OpponentId         int64     `json:"opponentId"`                      // 被挑战者用户ID
+	Dimension          int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width              int       `json:"width"`                           // 宽度(列数)
+	Height             int       `json:"height"`                          // 高度(行数)
+	Idx                int64     `json:"idx"`                             // 打乱随机数
+	Scramble           string    `json:"scramble"`                        // 打乱公式
+	ScrambleVersion    int       `json:"scrambleVersion"`                 // 打乱生成器版本 1:旧版 2:均匀
+	Message            string    `json:"message"`                         // 留言
+	Deadline           time.Time `json:"deadline"`                        // 截止时间
+	ChallengerDuration int       `json:"challengerDuration"`              // 发起者耗时
+	ChallengerStep     int       `json:"challengerStep"`                  // 发起者步数
+	ChallengerSolution string    `json:"challengerSolution"`              // 发起者解法
+	ChallengerStatus   int       `json:"challengerStatus"`                // 发起者状态 1:未提交 2:完成 3:放弃
+	OpponentDuration   int       `json:"opponentDuration"`                // 被挑战者耗时
+	OpponentStep       int       `json:"opponentStep"`                    // 被挑战者步数
+	OpponentSolution   string    `json:"opponentSolution"`                // 被挑战者解法
+	OpponentStatus     int       `json:"opponentStatus"`                  // 被挑战者状态 1:未提交 2:完成 3:放弃
+	WinnerId           int64     `json:"winnerId"`                        // 胜者用户ID 0:无(平局或双方都未完成)
+	Status             int       `json:"status" gorm:"default 1"`         // 状态 1:进行中 2:已结束 3:已拒绝 4:已取消
+	CreatedAt          time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt          time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// FriendChallengeCreateReq 发起好友挑战请求模型
+// 指定记录时使用该记录的打乱, 否则按尺寸下发新的打乱
+type FriendChallengeCreateReq struct {
+	UserId        int64  `json:"-"`             // 发起者用户ID
+	OpponentId    int64  `json:"-"`             // 被挑战者用户ID
+	RecordId      int64  `json:"-"`             // 打乱来源记录ID
+	OpponentIdStr string `json:"opponentId"`    // 被挑战者用户ID
+	RecordIdStr   string `json:"recordId"`      // 打乱来源记录ID
+	Dimension     int    `json:"dimension"`     // 阶数(方形边长) 0:非方形
+	Width         int    `json:"width"`         // 宽度(列数)
+	Height        int    `json:"height"`        // 高度(行数)
+	DeadlineHours int    `json:"deadlineHours"` // 截止时长(小时) 0:默认24小时
+	Message       string `json:"message"`       // 留言
+}
+
+// FriendChallengeReq 好友挑战请求模型
+type FriendChallengeReq struct {
+	Id         int64            `json:"-"`        // 主键ID
+	UserId     int64            `json:"-"`        // 用户ID
+	IdStr      string           `json:"id"`       // 主键ID
+	Role       int              `json:"role"`     // 角色 0:全部 1:发起的 2:收到的
+	Status     int              `json:"status"`   // 状态 1:进行中 2:已结束 3:已拒绝 4:已取消
+	Pagination utils.Pagination `gorm:"embedded"` // 分页
+	Sorted     string           `json:"sorted"`   // 排序
+}
+
+// FriendChallengeSubmitReq 提交好友挑战成绩请求模型
+type FriendChallengeSubmitReq struct {
+	Id       int64  `json:"-"`        // 主键ID
+	UserId   int64  `json:"-"`        // 用户ID
+	IdStr    string `json:"id"`       // 主键ID
+	Solution string `json:"solution"` // 解法 放弃时为空
+	Duration int    `json:"duration"` // 耗时
+	Step     int    `json:"step"`     // 步数
+	Status   int    `json:"status"`   // 状态 2:完成 3:放弃
+}
+
+// FriendChallengeResp 好友挑战响应模型, 结算前不包含对方的成绩
+type FriendChallengeResp struct {
+	Id                 string    `json:"id"`                                                          // 主键ID
+	ChallengerId       string    `json:"challengerId"`                                                // 发起者用户ID
+	ChallengerInfo     UserResp  `json:"challengerInfo" gorm:"foreignKey:Id;references:ChallengerId"` // 发起者用户信息
+	OpponentId         string    `json:"opponentId"`                                                  // 被挑战者用户ID
+	OpponentInfo       UserResp  `json:"opponentInfo" gorm:"foreignKey:Id;references:OpponentId"`     // 被挑战者用户信息
+	Dimension          int       `json:"dimension"`                                                   // 阶数(方形边长) 0:非方形
+	Width              int       `json:"width"`                                                       // 宽度(列数)
+	Height             int       `json:"height"`                                                      // 高度(行数)
+	Idx                string    `json:"idx"`                                                         // 打乱随机数
+	Scramble           string    `json:"scramble"`                                                    // 打乱公式
+	ScrambleVersion    int       `json:"scrambleVersion"`                                             // 打乱生成器版本 1:旧版 2:均匀
+	Message            string    `json:"message"`                                                     // 留言
+	Deadline           time.Time `json:"deadline"`                                                    // 截止时间
+	ChallengerDuration int       `json:"challengerDuration"`                                          // 发起者耗时
+	ChallengerStep     int       `json:"challengerStep"`                                              // 发起者步数
+	ChallengerSolution string    `json:"challengerSolution"`                                          // 发起者解法
+	ChallengerStatus   int       `json:"challengerStatus"`                                            // 发起者状态 1:未提交 2:完成 3:放弃
+	OpponentDuration   int       `json:"opponentDuration"`                                            // 被挑战者耗时
+	OpponentStep       int       `json:"opponentStep"`                                                // 被挑战者步数
+	OpponentSolution   string    `json:"opponentSolution"`                                            // 被挑战者解法
+	OpponentStatus     int       `json:"opponentStatus"`                                              // 被挑战者状态 1:未提交 2:完成 3:放弃
+	WinnerId           string    `json:"winnerId"`                                                    // 胜者用户ID
+	Status             int       `json:"status"`                                                      // 状态 1:进行中 2:已结束 3:已拒绝 4:已取消
+	CreatedAt          time.Time `json:"createdAt"`                                                   // 创建时间
+	UpdatedAt          time.Time `json:"updatedAt"`                                                   // 更新时间
+}
+
+func (FriendChallengeResp) TableName() string {
+	return "friend_challenge"
+}
+
+// FriendChallengeListResp 好友挑战列表响应模型
+type FriendChallengeListResp struct {
+	Total   int64                 `json:"total"`   // 总数
+	Records []FriendChallengeResp `json:"records"` // 好友挑战列表
+}
@@ -0,0 +1,31 @@
+package models
+
+// GhostReq 幽灵请求模型, 未指定记录时使用用户在该尺寸下的最佳单次记录
+type GhostReq struct {
+	UserId      int64  `json:"-"`         // 用户ID
+	RecordId    int64  `json:"-"`         // 记录ID
+	RecordIdStr string `json:"recordId"`  // 记录ID
+	Dimension   int    `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width       int    `json:"width"`     // 宽度(列数)
+	Height      int    `json:"height"`    // 高度(行数)
+}
+
+// GhostMove 幽灵的一步
+type GhostMove struct {
+	Seq  int `json:"seq"`  // 序号 从1开始
+	Tile int `json:"tile"` // 点击的数字
+	Time int `json:"time"` // 距开始的耗时
+}
+
+// GhostResp 幽灵响应模型
+type GhostResp struct {
+	RecordId  string      `json:"recordId"`  // 记录ID
+	UserInfo  UserResp    `json:"userInfo"`  // 记录的用户信息
+	Dimension int         `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int         `json:"width"`     // 宽度(列数)
+	Height    int         `json:"height"`    // 高度(行数)
+	Scramble  string      `json:"scramble"`  // 记录的打乱公式
+	Duration  int         `json:"duration"`  // 耗时
+	Step      int         `json:"step"`      // 步数
+	Moves     []GhostMove `json:"moves"`     // 每一步, 通过 WebSocket 推送时为空
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// LiveGame 直播中的对局, 保存在 redis 中, 观众通过 WebSocket 群组接收每一步与最终成绩
+type LiveGame struct {
+	Id         string    `json:"id"`         // 对局ID
+	Type       int       `json:"type"`       // 类型 1:对战 2:直播
+	HostId     string    `json:"hostId"`     // 发起者用户ID
+	PlayerIds  []string  `json:"playerIds"`  // 选手用户ID, 包括发起者
+	InviteeIds []string  `json:"inviteeIds"` // 已邀请未接受的用户ID 仅对战
+	Dimension  int       `json:"dimension"`  // 阶数(方形边长) 0:非方形
+	Width      int       `json:"width"`      // 宽度(列数)
+	Height     int       `json:"height"`     // 高度(行数)
+	Scramble   string    `json:"scramble"`   // 当前打乱公式
+	StartedAt  time.Time `json:"startedAt"`  // 开始时间
+}
+
+// LiveStartReq 开始直播请求模型
+type LiveStartReq struct {
+	Type      int      `json:"type"`      // 类型 1:对战 2:直播
+	Dimension int      `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int      `json:"width"`     // 宽度(列数)
+	Height    int      `json:"height"`    // 高度(行数)
+	PlayerIds []string `json:"playerIds"` // 邀请的其他选手用户ID 仅对战, 接受邀请后成为选手
+}
+
+// LiveReq 对局请求模型, 用于观看、退出观看、接受或拒绝对战邀请与结束直播
+type LiveReq struct {
+	Id string `json:"id"` // 对局ID
+}
+
+// LiveScrambleReq 直播开始新打乱请求模型
+type LiveScrambleReq struct {
+	Id       string `json:"id"`       // 对局ID
+	UserId   string `json:"userId"`   // 选手用户ID, 由服务端填写
+	Scramble string `json:"scramble"` // 打乱公式
+}
+
+// LiveMoveReq 直播的一步
+type LiveMoveReq struct {
+	Id     string `json:"id"`     // 对局ID
+	UserId string `json:"userId"` // 选手用户ID, 由服务端填写
+	Seq    int    `json:"seq"`    // 序号 从1开始
+	Tile   int    `json:"tile"`   // 点击的数字
+	Time   int    `json:"time"`   // 距开始的耗时
+}
+
+// LiveResultReq 直播的最终成绩
+type LiveResultReq struct {
+	Id       string `json:"id"`       // 对局ID
+	UserId   string `json:"userId"`   // 选手用户ID, 由服务端填写
+	RecordId string `json:"recordId"` // 记录ID 可为空
+	Duration int    `json:"duration"` // 耗时
+	Step     int    `json:"step"`     // 步数
+	Status   int    `json:"status"`   // 状态 1:完成 2:放弃
+}
+
+// LiveDeclinedResp 拒绝对战邀请
+type LiveDeclinedResp struct {
+	Id     string `json:"id"`     // 对局ID
+	UserId string `json:"userId"` // 拒绝邀请的用户ID
+}
+
+// LiveSpectatorResp 观众人数
+type LiveSpectatorResp struct {
+	Id         string `json:"id"`         // 对局ID
+	Spectators int    `json:"spectators"` // 观众人数
+}
+
+// LiveListReq 直播列表请求模型
+type LiveListReq struct {
+	Type int `json:"type"` // 类型 1:对战 2:直播 0:全部
+	utils.Pagination
+}
+
+// LiveResp 直播响应模型
+type LiveResp struct {
+	LiveGame
+	Players    []UserResp `json:"players"`    // 选手信息
+	Spectators int        `json:"spectators"` // 观众人数
+}
+
+// LiveListResp 直播列表响应模型
+type LiveListResp struct {
+	Total   int64      `json:"total"`   // 总数
+	Records []LiveResp `json:"records"` // 直播列表
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// Marathon 耐力模式模型, 服务端逐个下发打乱
+// 马拉松: 连续完成 Target 个打乱, 成绩为总耗时; 限时: 在 Target 秒内完成尽可能多的打乱
+type Marathon struct {
+	Id          int64      `json:"id" gorm:"primaryKey"`            // 主键ID
+	UserId      int64      `json:"userId"`                          // 用户ID
+	Mode        int        `json:"mode"`                            // 模式 1:马拉松 2:限时
+	Dimension   int        `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width       int        `json:"width"`                           // 宽度(列数)
+	Height      int        `json:"height"`                          // 高度(行数)
+	Target      int        `json:"target"`                          // 目标 马拉松:打乱数量 限时:时限(秒)
+	Current     int        `json:"current"`                         // 当前打乱的序号
+	SolvedCount int        `json:"solvedCount"`                     // 完成数量
+	Duration    int        `json:"duration"`                        // 总耗时
+	Step        int        `json:"step"`                            // 总步数
+	Status      int        `json:"status" gorm:"default 1"`         // 状态 1:进行中 2:已完成 3:已放弃
+	ExpiredAt   *time.Time `json:"expiredAt"`                       // 截止时间 仅限时模式
+	CreatedAt   time.Time  `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt   time.Time  `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// MarathonSolve 耐力模式中的一次还原
+type MarathonSolve struct {
+	Id              int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	MarathonId      int64     `json:"marathonId"`                      // 耐力模式ID
+	Seq             int       `json:"seq"`                             // 序号 从1开始
+	ScrambleId      int64     `json:"scrambleId"`                      // 打乱ID
+	Idx             int64     `json:"idx"`                             // 打乱随机数
+	Scramble        string    `json:"scramble"`                        // 打乱公式
+	ScrambleVersion int       `json:"scrambleVersion"`                 // 打乱生成器版本 1:旧版 2:均匀
+	Solution        string    `json:"solution"`                        // 解法
+	Duration        int       `json:"duration"`                        // 耗时
+	Step            int       `json:"step"`                            // 步数
+	Status          int       `json:"status" gorm:"default 1"`         // 状态 1:未完成 2:已完成
+	CreatedAt       time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt       time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// MarathonStartReq 开始耐力模式请求模型
+type MarathonStartReq struct {
+	UserId    int64 `json:"-"`         // 用户ID
+	Mode      int   `json:"mode"`      // 模式 1:马拉松 2:限时
+	Dimension int   `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int   `json:"width"`     // 宽度(列数)
+	Height    int   `json:"height"`    // 高度(行数)
+	Target    int   `json:"target"`    // 目标 马拉松:打乱数量 限时:时限(秒)
+}
+
+// MarathonSubmitReq 提交当前打乱请求模型
+type MarathonSubmitReq struct {
+	Id       int64  `json:"-"`        // 主键ID
+	UserId   int64  `json:"-"`        // 用户ID
+	IdStr    string `json:"id"`       // 主键ID
+	Duration int    `json:"duration"` // 耗时
+	Step     int    `json:"step"`     // 步数
+	Solution string `json:"solution"` // 解法
+}
+
+// MarathonReq 耐力模式请求模型
+type MarathonReq struct {
+	Id         int64            `json:"-"`         // 主键ID
+	UserId     int64            `json:"-"`         // 用户ID
+	IdStr      string           `json:"id"`        // 主键ID
+	UserIdStr  string           `json:"userId"`    // 用户ID
+	Mode       int              `json:"mode"`      // 模式 1:马拉松 2:限时
+	Dimension  int              `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width      int              `json:"width"`     // 宽度(列数)
+	Height     int              `json:"height"`    // 高度(行数)
+	Target     int              `json:"target"`    // 目标
+	Status     int              `json:"status"`    // 状态 1:进行中 2:已完成 3:已放弃
+	Pagination utils.Pagination `gorm:"embedded"`  // 分页
+	Sorted     string           `json:"sorted"`    // 排序
+}
+
+// MarathonSolveResp 耐力模式中一次还原的响应模型
+type MarathonSolveResp struct {
+	Id              string    `json:"id"`              // 主键ID
+	MarathonId      string    `json:"marathonId"`      // 耐力模式ID
+	Seq             int       `json:"seq"`             // 序号
+	Idx             string    `json:"idx"`             // 打乱随机数
+	Scramble        string    `json:"scramble"`        // 打乱公式
+	ScrambleVersion int       `json:"scrambleVersion"` // 打乱生成器版本 1:旧版 2:均匀
+	Solution        string    `json:"solution"`        // 解法
+	Duration        int       `json:"duration"`        // 耗时
+	Step            int       `json:"step"`            // 步数
+	Status          int       `json:"status"`          // 状态 1:未完成 2:已完成
+	CreatedAt       time.Time `json:"createdAt"`       // 创建时间
+	UpdatedAt       time.Time `json:"updatedAt"`       // 更新时间
+}
+
+func (MarathonSolveResp) TableName() string {
+	return "marathon_solve"
+}
+
+// MarathonResp 耐力模式响应模型
+type MarathonResp struct {
+	Id          string              `json:"id"`                                                // 主键ID
+	UserId      string              `json:"userId"`                                            // 用户ID
+	UserInfo    UserResp            `json:"userInfo" gorm:"foreignKey:Id;references:UserId"`   // 用户信息
+	Mode        int                 `json:"mode"`                                              // 模式 1:马拉松 2:限时
+	Dimension   int                 `json:"dimension"`                                         // 阶数(方形边长) 0:非方形
+	Width       int                 `json:"width"`                                             // 宽度(列数)
+	Height      int                 `json:"height"`                                            // 高度(行数)
+	Target      int                 `json:"target"`                                            // 目标 马拉松:打乱数量 限时:时限(秒)
+	Current     int                 `json:"current"`                                           // 当前打乱的序号
+	SolvedCount int                 `json:"solvedCount"`                                       // 完成数量
+	Duration    int                 `json:"duration"`                                          // 总耗时
+	Step        int                 `json:"step"`                                              // 总步数
+	Status      int                 `json:"status"`                                            // 状态 1:进行中 2:已完成 3:已放弃
+	ExpiredAt   *time.Time          `json:"expiredAt"`                                         // 截止时间 仅限时模式
+	Solves      []MarathonSolveResp `json:"solves" gorm:"foreignKey:MarathonId;references:Id"` // 所有还原, 按序号排列, 最后一个未完成的为当前打乱
+	CreatedAt   time.Time           `json:"createdAt"`                                         // 创建时间
+	UpdatedAt   time.Time           `json:"updatedAt"`                                         // 更新时间
+}
+
+func (MarathonResp) TableName() string {
+	return "marathon"
+}
+
+// MarathonListResp 耐力模式列表响应模型
+type MarathonListResp struct {
+	Total   int64          `json:"total"`   // 总数
+	Records []MarathonResp `json:"records"` // 耐力模式列表
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// RaceRoom 竞速房间, 保存在 redis 中, 所有选手每轮使用相同的打乱, 按轮次名次计分
+type RaceRoom struct {
+	Id             string           `json:"id"`             // 房间ID
+	HostId         string           `json:"hostId"`         // 房主用户ID
+	Dimension      int              `json:"dimension"`      // 阶数(方形边长) 0:非方形
+	Width          int              `json:"width"`          // 宽度(列数)
+	Height         int              `json:"height"`         // 高度(行数)
+	Rounds         int              `json:"rounds"`         // 轮数
+	MaxPlayers     int              `json:"maxPlayers"`     // 最多选手数量, 包括房主
+	Status         int              `json:"status"`         // 状态 1:等待中 2:进行中 3:已结束 4:已解散
+	CurrentRound   int              `json:"currentRound"`   // 当前轮次 从1开始, 未开始时为0
+	InviteeIds     []string         `json:"inviteeIds"`     // 已邀请未加入的用户ID
+	Players        []RaceRoomPlayer `json:"players"`        // 选手
+	RoundList      []RaceRound      `json:"roundList"`      // 已开始的轮次
+	RoundStartedAt time.Time        `json:"roundStartedAt"` // 当前轮次开始时间
+	CreatedAt      time.Time        `json:"createdAt"`      // 创建时间
+}
+
+// RaceRoomPlayer 竞速房间中的选手
+type RaceRoomPlayer struct {
+	UserId string `json:"userId"` // 用户ID
+	Ready  bool   `json:"ready"`  // 是否已准备
+	Points int    `json:"points"` // 总积分
+	Status int    `json:"status"` // 状态 1:在房间 2:已离开
+}
+
+// RaceRound 竞速房间的一轮
+type RaceRound struct {
+	Seq      int               `json:"seq"`        // 轮次 从1开始
+	Idx      int64             `json:"idx,string"` // 打乱随机数, 保存在redis中用于校验成绩
+	Scramble string            `json:"scramble"`   // 打乱公式
+	Version  int               `json:"version"`    // 打乱生成器版本
+	Status   int               `json:"status"`     // 状态 1:进行中 2:已结束
+	Results  []RaceRoundResult `json:"results"`    // 成绩
+}
+
+// RaceRoundResult 一轮中选手的成绩
+type RaceRoundResult struct {
+	UserId   string `json:"userId"`   // 用户ID
+	Duration int    `json:"duration"` // 耗时
+	Step     int    `json:"step"`     // 步数
+	Status   int    `json:"status"`   // 状态 1:完成 2:放弃 3:超时
+	Points   int    `json:"points"`   // 本轮积分
+}
+
+// RaceRoomCreateReq 创建竞速房间请求模型
+type RaceRoomCreateReq struct {
+	Dimension  int      `json:"dimension"`  // 阶数(方形边长) 0:非方形
+	Width      int      `json:"width"`      // 宽度(列数)
+	Height     int      `json:"height"`     // 高度(行数)
+	Rounds     int      `json:"rounds"`     // 轮数
+	MaxPlayers int      `json:"maxPlayers"` // 最多选手数量, 包括房主 0:默认上限
+	InviteeIds []string `json:"inviteeIds"` // 邀请的用户ID
+}
+
+// RaceRoomInviteReq 邀请选手请求模型
+type RaceRoomInviteReq struct {
+	Id         string   `json:"id"`         // 房间ID
+	InviteeIds []string `json:"inviteeIds"` // 邀请的用户ID
+}
+
+// RaceRoomReq 房间请求模型, 用于加入、离开、开始与获取房间
+type RaceRoomReq struct {
+	Id string `json:"id"` // 房间ID
+}
+
+// RaceRoomReadyReq 准备请求模型
+type RaceRoomReadyReq struct {
+	Id    string `json:"id"`    // 房间ID
+	Ready bool   `json:"ready"` // 是否准备
+}
+
+// RaceRoomSubmitReq 提交成绩请求模型
+type RaceRoomSubmitReq struct {
+	Id       string `json:"id"`       // 房间ID
+	Round    int    `json:"round"`    // 轮次
+	Solution string `json:"solution"` // 解法 放弃时为空
+	Duration int    `json:"duration"` // 耗时
+	Step     int    `json:"step"`     // 步数
+	Status   int    `json:"status"`   // 状态 1:完成 2:放弃
+}
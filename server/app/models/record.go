@@ -7,18 +7,28 @@ import (
 
 // Record 记录模型
 type Record struct {
-	Id        int64     `json:"id" gorm:"primaryKey"`            // 主键ID
-	UserId    int64     `json:"userId"`                          // 用户ID
-	Dimension int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6 | 7 | 8
-	Type      int       `json:"type"`                            // 类型 1:练习 2:排行榜 3:对战
-	Duration  int       `json:"duration"`                        // 耗时
-	Step      int       `json:"step"`                            // 步数
-	Status    int       `json:"status"`                          // 状态 1:启用 2:冻结 3:删除
-	Scramble  string    `json:"scramble"`                        // 打乱公式
-	Solution  string    `json:"solution"`                        // 解法
-	Idx       int64     `json:"idx"`                             // 打乱随机数
-	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
-	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+	Id              int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	UserId          int64     `json:"userId"`                          // 用户ID
+	Dimension       int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width           int       `json:"width"`                           // 宽度(列数)
+	Height          int       `json:"height"`                          // 高度(行数)
+	Type            int       `json:"type"`                            // 类型 1:练习 2:排行榜 3:对战 4:打乱组 5:挑战 6:盲拧 7:好友挑战
+	Duration        int       `json:"duration"`                        // 耗时 盲拧为记忆与执行耗时之和
+	MemoDuration    int       `json:"memoDuration"`                    // 记忆耗时 仅盲拧
+	ExecDuration    int       `json:"execDuration"`                    // 执行耗时 仅盲拧
+	Step            int       `json:"step"`                            // 步数
+	Status          int       `json:"status"`                          // 状态 1:启用 2:冻结 3:删除
+	Scramble        string    `json:"scramble"`                        // 打乱公式
+	Solution        string    `json:"solution"`                        // 解法 点击数字(逗号分隔)或方向记法(如 R3 D2 L)
+	MoveTimes       string    `json:"moveTimes"`                       // 每一步距开始的耗时(逗号分隔), 用于回放, 可为空
+	Idx             int64     `json:"idx"`                             // 打乱随机数
+	ScrambleVersion int       `json:"scrambleVersion"`                 // 打乱生成器版本 1:旧版 2:均匀
+	SessionId       int64     `json:"-"`                               // 打乱组挑战ID 0:非打乱组记录
+	SessionIdStr    string    `json:"sessionId" gorm:"-"`              // 打乱组挑战ID
+	ChallengeId     int64     `json:"-"`                               // 挑战的原记录ID 0:非挑战记录
+	ChallengeIdStr  string    `json:"challengeId" gorm:"-"`            // 挑战的原记录ID
+	CreatedAt       time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt       time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
 }
 
 // RecordReq 记录请求模型
@@ -26,8 +36,10 @@ type RecordReq struct {
 	Id        int64   `json:"-"`         // 主键ID
 	Ids       []int64 `json:"-"`         // 主键ID列表
 	UserId    int64   `json:"-"`         // 用户ID
-	Dimension int     `json:"dimension"` // 阶数 3 | 4 | 5 | 6 | 7 | 8
-	Type      int     `json:"type"`      // 类型 1:练习 2:排行榜 3:对战
+	Dimension int     `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int     `json:"width"`     // 宽度(列数)
+	Height    int     `json:"height"`    // 高度(行数)
+	Type      int     `json:"type"`      // 类型 1:练习 2:排行榜 3:对战 4:打乱组 5:挑战 6:盲拧 7:好友挑战
 	Duration  int     `json:"duration"`  // 耗时
 	Step      int     `json:"step"`      // 步数
 	Status    int     `json:"status"`    // 状态 1:启用 2:冻结 3:删除
@@ -35,36 +47,45 @@ type RecordReq struct {
 	Solution  string  `json:"solution"`  // 解法
 	Idx       int64   `json:"-"`         // 打乱随机数
 
-	Pagination    utils.Pagination `gorm:"embedded"`      // 分页
-	DurationRange []int            `json:"durationRange"` // 耗时范围
-	StepRange     []int            `json:"stepRange"`     // 步数范围
-	DateRange     []time.Time      `json:"dateRange"`     // 日期范围
-	IdStr         string           `json:"id"`            // 主键ID
-	IdsStr        []string         `json:"ids"`           // 主键ID列表
-	UserIdStr     string           `json:"userId"`        // 用户ID
-	Username      string           `json:"username"`      // 用户名
-	Nickname      string           `json:"nickname"`      // 昵称
-	IdxStr        string           `json:"idx"`           // 打乱随机数
-	Sorted        string           `json:"sorted"`        // 排序
-	OrderBy       string           `json:"orderBy"`       // 排序字段
-	NeedUserInfo  bool             `json:"needUserInfo"`  // 是否需要用户信息
+	Pagination     utils.Pagination `gorm:"embedded"`       // 分页
+	DurationRange  []int            `json:"durationRange"`  // 耗时范围
+	StepRange      []int            `json:"stepRange"`      // 步数范围
+	DateRange      []time.Time      `json:"dateRange"`      // 日期范围
+	IdStr          string           `json:"id"`             // 主键ID
+	IdsStr         []string         `json:"ids"`            // 主键ID列表
+	UserIdStr      string           `json:"userId"`         // 用户ID
+	Username       string           `json:"username"`       // 用户名
+	Nickname       string           `json:"nickname"`       // 昵称
+	IdxStr         string           `json:"idx"`            // 打乱随机数
+	Sorted         string           `json:"sorted"`         // 排序
+	OrderBy        string           `json:"orderBy"`        // 排序字段
+	NeedUserInfo   bool             `json:"needUserInfo"`   // 是否需要用户信息
+	NeedDifficulty bool             `json:"needDifficulty"` // 是否需要打乱难度
 }
 
 // RecordResp 记录响应模型
 type RecordResp struct {
-	Id        string    `json:"id"`                                              // 主键ID
-	UserId    string    `json:"userId"`                                          // 用户ID
-	UserInfo  UserResp  `json:"userInfo" gorm:"foreignKey:Id;references:UserId"` // 用户信息
-	Dimension int       `json:"dimension"`                                       // 阶数 3 | 4 | 5 | 6 | 7 | 8
-	Type      int       `json:"type"`                                            // 类型 1:练习 2:排行榜 3:对战
-	Duration  int       `json:"duration"`                                        // 耗时
-	Step      int       `json:"step"`                                            // 步数
-	Status    int       `json:"status"`                                          // 状态 1:启用 2:冻结 3:删除
-	Scramble  string    `json:"scramble"`                                        // 打乱公式
-	Solution  string    `json:"solution"`                                        // 解法
-	Idx       string    `json:"idx"`                                             // 打乱随机数
-	CreatedAt time.Time `json:"createdAt"`                                       // 创建时间
-	UpdatedAt time.Time `json:"updatedAt"`                                       // 更新时间
+	Id              string              `json:"id"`                                              // 主键ID
+	UserId          string              `json:"userId"`                                          // 用户ID
+	UserInfo        UserResp            `json:"userInfo" gorm:"foreignKey:Id;references:UserId"` // 用户信息
+	Dimension       int                 `json:"dimension"`                                       // 阶数(方形边长) 0:非方形
+	Width           int                 `json:"width"`                                           // 宽度(列数)
+	Height          int                 `json:"height"`                                          // 高度(行数)
+	Type            int                 `json:"type"`                                            // 类型 1:练习 2:排行榜 3:对战 4:打乱组 5:挑战 6:盲拧 7:好友挑战
+	Duration        int                 `json:"duration"`                                        // 耗时
+	MemoDuration    int                 `json:"memoDuration"`                                    // 记忆耗时 仅盲拧
+	ExecDuration    int                 `json:"execDuration"`                                    // 执行耗时 仅盲拧
+	Step            int                 `json:"step"`                                            // 步数
+	Status          int                 `json:"status"`                                          // 状态 1:启用 2:冻结 3:删除
+	Scramble        string              `json:"scramble"`                                        // 打乱公式
+	Solution        string              `json:"solution"`                                        // 解法
+	MoveTimes       string              `json:"moveTimes"`                                       // 每一步距开始的耗时(逗号分隔)
+	Idx             string              `json:"idx"`                                             // 打乱随机数
+	ScrambleVersion int                 `json:"scrambleVersion"`                                 // 打乱生成器版本 1:旧版 2:均匀
+	ChallengeId     string              `json:"challengeId"`                                     // 挑战的原记录ID 0:非挑战记录
+	Difficulty      *ScrambleDifficulty `json:"difficulty" gorm:"-"`                             // 打乱难度, 仅服务端下发的打乱有值
+	CreatedAt       time.Time           `json:"createdAt"`                                       // 创建时间
+	UpdatedAt       time.Time           `json:"updatedAt"`                                       // 更新时间
 }
 
 // RecordListResp 记录列表响应模型
@@ -9,7 +9,9 @@ import (
 type RecordBestAverage struct {
 	Id                    int64     `json:"id" gorm:"primaryKey"`            // 主键ID
 	UserId                int64     `json:"userId"`                          // 用户ID
-	Dimension             int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension             int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width                 int       `json:"width"`                           // 宽度(列数)
+	Height                int       `json:"height"`                          // 高度(行数)
 	Type                  int       `json:"type"`                            // 类型 5:5次平均 12:12次平均
 	RecordIds             string    `json:"recordIds"`                       // 记录ID
 	RecordAverageDuration int       `json:"recordAverageDuration"`           // 平均耗时
@@ -23,7 +25,9 @@ type RecordBestAverage struct {
 type RecordBestAverageReq struct {
 	Id               int64 `json:"-"`                // 主键ID
 	UserId           int64 `json:"-"`                // 用户ID
-	Dimension        int   `json:"dimension"`        // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension        int   `json:"dimension"`        // 阶数(方形边长) 0:非方形
+	Width            int   `json:"width"`            // 宽度(列数)
+	Height           int   `json:"height"`           // 高度(行数)
 	Type             int   `json:"type"`             // 类型 5:5次平均 12:12次平均
 	RecordBreakCount int   `json:"recordBreakCount"` // 破纪录次数
 
@@ -47,7 +51,9 @@ type RecordBestAverageResp struct {
 	Id     string `json:"id" gorm:"primaryKey"` // 主键ID
 	UserId string `json:"userId"`               // 用户ID
 
-	Dimension             int            `json:"dimension"`                                       // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension             int            `json:"dimension"`                                       // 阶数(方形边长) 0:非方形
+	Width                 int            `json:"width"`                                           // 宽度(列数)
+	Height                int            `json:"height"`                                          // 高度(行数)
 	Type                  int            `json:"type"`                                            // 类型 5:5次平均 12:12次平均
 	RecordBreakCount      int            `json:"recordBreakCount"`                                // 破纪录次数
 	RecordIds             string         `json:"recordIds"`                                       // 记录ID
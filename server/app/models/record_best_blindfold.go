@@ -0,0 +1,76 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// RecordBestBlindfold 最佳盲拧记录模型
+type RecordBestBlindfold struct {
+	Id                 int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	UserId             int64     `json:"userId"`                          // 用户ID
+	Dimension          int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width              int       `json:"width"`                           // 宽度(列数)
+	Height             int       `json:"height"`                          // 高度(行数)
+	RecordId           int64     `json:"recordId"`                        // 记录ID
+	RecordDuration     int       `json:"recordDuration"`                  // 总耗时
+	RecordMemoDuration int       `json:"recordMemoDuration"`              // 记忆耗时
+	RecordExecDuration int       `json:"recordExecDuration"`              // 执行耗时
+	RecordStep         int       `json:"recordStep"`                      // 步数
+	RecordBreakCount   int       `json:"recordBreakCount"`                // 破纪录次数
+	Ranked             int       `json:"ranked"`                          // 排名
+	CreatedAt          time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt          time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// RecordBestBlindfoldReq 最佳盲拧记录请求模型
+type RecordBestBlindfoldReq struct {
+	Id        int64 `json:"-"`         // 主键ID
+	UserId    int64 `json:"-"`         // 用户ID
+	Dimension int   `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int   `json:"width"`     // 宽度(列数)
+	Height    int   `json:"height"`    // 高度(行数)
+	RecordId  int64 `json:"-"`         // 记录ID
+
+	IdStr            string           `json:"id"`               // 主键ID
+	UserIdStr        string           `json:"userId"`           // 用户ID
+	Username         string           `json:"username"`         // 用户名
+	Nickname         string           `json:"nickname"`         // 昵称
+	RecordIdStr      string           `json:"recordId"`         // 记录ID
+	DurationRange    []int            `json:"durationRange"`    // 耗时范围
+	StepRange        []int            `json:"stepRange"`        // 步数范围
+	DateRange        []time.Time      `json:"dateRange"`        // 日期范围
+	RankRange        []int            `json:"rankRange"`        // 排名范围
+	BreakCountRange  []int            `json:"breakCountRange"`  // 破纪录次数范围
+	Pagination       utils.Pagination `gorm:"embedded"`         // 分页
+	Sorted           string           `json:"sorted"`           // 排序
+	OrderBy          string           `json:"orderBy"`          // 排序字段
+	NeedUserInfo     bool             `json:"needUserInfo"`     // 是否需要用户信息
+	NeedRecordDetail bool             `json:"needRecordDetail"` // 是否需要记录详情
+}
+
+// RecordBestBlindfoldResp 最佳盲拧记录响应模型
+type RecordBestBlindfoldResp struct {
+	Id                 string       `json:"id" gorm:"primaryKey"`                                  // 主键ID
+	UserId             string       `json:"userId"`                                                // 用户ID
+	Dimension          int          `json:"dimension"`                                             // 阶数(方形边长) 0:非方形
+	Width              int          `json:"width"`                                                 // 宽度(列数)
+	Height             int          `json:"height"`                                                // 高度(行数)
+	RecordId           string       `json:"recordId"`                                              // 记录ID
+	RecordDuration     int          `json:"recordDuration"`                                        // 总耗时
+	RecordMemoDuration int          `json:"recordMemoDuration"`                                    // 记忆耗时
+	RecordExecDuration int          `json:"recordExecDuration"`                                    // 执行耗时
+	RecordStep         int          `json:"recordStep"`                                            // 步数
+	RecordBreakCount   int          `json:"recordBreakCount"`                                      // 破纪录次数
+	Ranked             int          `json:"ranked"`                                                // 排名
+	CreatedAt          time.Time    `json:"createdAt"`                                             // 创建时间
+	UpdatedAt          time.Time    `json:"updatedAt"`                                             // 更新时间
+	UserInfo           UserResp     `json:"userInfo" gorm:"foreignKey:Id;references:UserId"`       // 用户信息
+	RecordDetail       []RecordResp `json:"recordDetail" gorm:"foreignKey:Id;references:RecordId"` // 记录详情
+}
+
+// RecordBestBlindfoldListResp 最佳盲拧记录列表响应模型
+type RecordBestBlindfoldListResp struct {
+	Total   int64                     `json:"total"`
+	Records []RecordBestBlindfoldResp `json:"records"`
+}
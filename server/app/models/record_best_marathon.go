@@ -0,0 +1,72 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// RecordBestMarathon 最佳耐力模式记录模型
+type RecordBestMarathon struct {
+	Id               int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	UserId           int64     `json:"userId"`                          // 用户ID
+	Mode             int       `json:"mode"`                            // 模式 1:马拉松 2:限时
+	Dimension        int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width            int       `json:"width"`                           // 宽度(列数)
+	Height           int       `json:"height"`                          // 高度(行数)
+	Target           int       `json:"target"`                          // 目标 马拉松:打乱数量 限时:时限(秒)
+	MarathonId       int64     `json:"marathonId"`                      // 耐力模式ID
+	SolvedCount      int       `json:"solvedCount"`                     // 完成数量
+	RecordDuration   int       `json:"recordDuration"`                  // 总耗时
+	RecordStep       int       `json:"recordStep"`                      // 总步数
+	RecordBreakCount int       `json:"recordBreakCount"`                // 破纪录次数
+	Ranked           int       `json:"ranked"`                          // 排名
+	CreatedAt        time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt        time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// RecordBestMarathonReq 最佳耐力模式记录请求模型
+type RecordBestMarathonReq struct {
+	Id        int64 `json:"-"`         // 主键ID
+	UserId    int64 `json:"-"`         // 用户ID
+	Mode      int   `json:"mode"`      // 模式 1:马拉松 2:限时
+	Dimension int   `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int   `json:"width"`     // 宽度(列数)
+	Height    int   `json:"height"`    // 高度(行数)
+	Target    int   `json:"target"`    // 目标
+
+	IdStr        string           `json:"id"`           // 主键ID
+	UserIdStr    string           `json:"userId"`       // 用户ID
+	Username     string           `json:"username"`     // 用户名
+	Nickname     string           `json:"nickname"`     // 昵称
+	RankRange    []int            `json:"rankRange"`    // 排名范围
+	Pagination   utils.Pagination `gorm:"embedded"`     // 分页
+	Sorted       string           `json:"sorted"`       // 排序
+	OrderBy      string           `json:"orderBy"`      // 排序字段
+	NeedUserInfo bool             `json:"needUserInfo"` // 是否需要用户信息
+}
+
+// RecordBestMarathonResp 最佳耐力模式记录响应模型
+type RecordBestMarathonResp struct {
+	Id               string    `json:"id" gorm:"primaryKey"`                            // 主键ID
+	UserId           string    `json:"userId"`                                          // 用户ID
+	Mode             int       `json:"mode"`                                            // 模式 1:马拉松 2:限时
+	Dimension        int       `json:"dimension"`                                       // 阶数(方形边长) 0:非方形
+	Width            int       `json:"width"`                                           // 宽度(列数)
+	Height           int       `json:"height"`                                          // 高度(行数)
+	Target           int       `json:"target"`                                          // 目标 马拉松:打乱数量 限时:时限(秒)
+	MarathonId       string    `json:"marathonId"`                                      // 耐力模式ID
+	SolvedCount      int       `json:"solvedCount"`                                     // 完成数量
+	RecordDuration   int       `json:"recordDuration"`                                  // 总耗时
+	RecordStep       int       `json:"recordStep"`                                      // 总步数
+	RecordBreakCount int       `json:"recordBreakCount"`                                // 破纪录次数
+	Ranked           int       `json:"ranked"`                                          // 排名
+	CreatedAt        time.Time `json:"createdAt"`                                       // 创建时间
+	UpdatedAt        time.Time `json:"updatedAt"`                                       // 更新时间
+	UserInfo         UserResp  `json:"userInfo" gorm:"foreignKey:Id;references:UserId"` // 用户信息
+}
+
+// RecordBestMarathonListResp 最佳耐力模式记录列表响应模型
+type RecordBestMarathonListResp struct {
+	Total   int64                    `json:"total"`
+	Records []RecordBestMarathonResp `json:"records"`
+}
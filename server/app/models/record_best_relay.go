@@ -0,0 +1,62 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// RecordBestRelay 最佳接力记录模型
+type RecordBestRelay struct {
+	Id               int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	UserId           int64     `json:"userId"`                          // 用户ID
+	Event            string    `json:"event"`                           // 项目 各段尺寸按顺序拼接, 如 3x3,4x4,5x5
+	RelayId          int64     `json:"relayId"`                         // 接力ID
+	RecordDuration   int       `json:"recordDuration"`                  // 总耗时
+	RecordStep       int       `json:"recordStep"`                      // 总步数
+	RecordBreakCount int       `json:"recordBreakCount"`                // 破纪录次数
+	Ranked           int       `json:"ranked"`                          // 排名
+	CreatedAt        time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt        time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// RecordBestRelayReq 最佳接力记录请求模型
+type RecordBestRelayReq struct {
+	Id      int64  `json:"-"`     // 主键ID
+	UserId  int64  `json:"-"`     // 用户ID
+	Event   string `json:"event"` // 项目
+	RelayId int64  `json:"-"`     // 接力ID
+
+	IdStr         string           `json:"id"`            // 主键ID
+	UserIdStr     string           `json:"userId"`        // 用户ID
+	Username      string           `json:"username"`      // 用户名
+	Nickname      string           `json:"nickname"`      // 昵称
+	DurationRange []int            `json:"durationRange"` // 耗时范围
+	RankRange     []int            `json:"rankRange"`     // 排名范围
+	Pagination    utils.Pagination `gorm:"embedded"`      // 分页
+	Sorted        string           `json:"sorted"`        // 排序
+	OrderBy       string           `json:"orderBy"`       // 排序字段
+	NeedUserInfo  bool             `json:"needUserInfo"`  // 是否需要用户信息
+	NeedDetail    bool             `json:"needDetail"`    // 是否需要接力详情(包括分段)
+}
+
+// RecordBestRelayResp 最佳接力记录响应模型
+type RecordBestRelayResp struct {
+	Id               string      `json:"id" gorm:"primaryKey"`                                // 主键ID
+	UserId           string      `json:"userId"`                                              // 用户ID
+	Event            string      `json:"event"`                                               // 项目
+	RelayId          string      `json:"relayId"`                                             // 接力ID
+	RecordDuration   int         `json:"recordDuration"`                                      // 总耗时
+	RecordStep       int         `json:"recordStep"`                                          // 总步数
+	RecordBreakCount int         `json:"recordBreakCount"`                                    // 破纪录次数
+	Ranked           int         `json:"ranked"`                                              // 排名
+	CreatedAt        time.Time   `json:"createdAt"`                                           // 创建时间
+	UpdatedAt        time.Time   `json:"updatedAt"`                                           // 更新时间
+	UserInfo         UserResp    `json:"userInfo" gorm:"foreignKey:Id;references:UserId"`     // 用户信息
+	RelayDetail      []RelayResp `json:"relayDetail" gorm:"foreignKey:Id;references:RelayId"` // 接力详情
+}
+
+// RecordBestRelayListResp 最佳接力记录列表响应模型
+type RecordBestRelayListResp struct {
+	Total   int64                 `json:"total"`
+	Records []RecordBestRelayResp `json:"records"`
+}
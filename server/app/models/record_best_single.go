@@ -9,7 +9,9 @@ import (
 type RecordBestSingle struct {
 	Id               int64     `json:"id" gorm:"primaryKey"`            // 主键ID
 	UserId           int64     `json:"userId" gorm:"primaryKey"`        // 用户ID
-	Dimension        int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension        int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width            int       `json:"width"`                           // 宽度(列数)
+	Height           int       `json:"height"`                          // 高度(行数)
 	RecordId         int64     `json:"recordId"`                        // 记录ID
 	RecordDuration   int       `json:"recordDuration"`                  // 耗时
 	RecordStep       int       `json:"recordStep"`                      // 步数
@@ -23,7 +25,9 @@ type RecordBestSingle struct {
 type RecordBestSingleReq struct {
 	Id               int64 `json:"-"`                // 主键ID
 	UserId           int64 `json:"-"`                // 用户ID
-	Dimension        int   `json:"dimension"`        // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension        int   `json:"dimension"`        // 阶数(方形边长) 0:非方形
+	Width            int   `json:"width"`            // 宽度(列数)
+	Height           int   `json:"height"`           // 高度(行数)
 	RecordId         int64 `json:"-"`                // 记录ID
 	RecordBreakCount int   `json:"recordBreakCount"` // 破纪录次数
 
@@ -48,7 +52,9 @@ type RecordBestSingleReq struct {
 type RecordBestSingleResp struct {
 	Id               string       `json:"id" gorm:"primaryKey"`                                  // 主键ID
 	UserId           string       `json:"userId"`                                                // 用户ID
-	Dimension        int          `json:"dimension"`                                             // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension        int          `json:"dimension"`                                             // 阶数(方形边长) 0:非方形
+	Width            int          `json:"width"`                                                 // 宽度(列数)
+	Height           int          `json:"height"`                                                // 高度(行数)
 	RecordId         string       `json:"recordId"`                                              // 记录ID
 	RecordDuration   int          `json:"recordDuration"`                                        // 耗时
 	RecordStep       int          `json:"recordStep"`                                            // 步数
@@ -9,7 +9,9 @@ import (
 type RecordBestStep struct {
 	Id               int64     `json:"id" gorm:"primaryKey"`            // 主键ID
 	UserId           int64     `json:"userId"`                          // 用户ID
-	Dimension        int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension        int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width            int       `json:"width"`                           // 宽度(列数)
+	Height           int       `json:"height"`                          // 高度(行数)
 	RecordId         int64     `json:"recordId"`                        // 记录ID
 	RecordStep       int       `json:"recordStep"`                      // 步数
 	RecordBreakCount int       `json:"recordBreakCount"`                // 破纪录次数
@@ -22,7 +24,9 @@ type RecordBestStep struct {
 type RecordBestStepReq struct {
 	Id               int64 `json:"-"`                // 主键ID
 	UserId           int64 `json:"-"`                // 用户ID
-	Dimension        int   `json:"dimension"`        // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension        int   `json:"dimension"`        // 阶数(方形边长) 0:非方形
+	Width            int   `json:"width"`            // 宽度(列数)
+	Height           int   `json:"height"`           // 高度(行数)
 	RecordId         int64 `json:"-"`                // 记录ID
 	RecordBreakCount int   `json:"recordBreakCount"` // 破纪录次数
 
@@ -46,7 +50,9 @@ type RecordBestStepReq struct {
 type RecordBestStepResp struct {
 	Id               string         `json:"id" gorm:"primaryKey"`                            // 主键ID
 	UserId           string         `json:"userId"`                                          // 用户ID
-	Dimension        int            `json:"dimension"`                                       // 阶数 3 | 4 | 5 | 6 | 7 | 8
+	Dimension        int            `json:"dimension"`                                       // 阶数(方形边长) 0:非方形
+	Width            int            `json:"width"`                                           // 宽度(列数)
+	Height           int            `json:"height"`                                          // 高度(行数)
 	RecordId         string         `json:"recordId"`                                        // 记录ID
 	RecordStep       int            `json:"recordStep"`                                      // 步数
 	RecordBreakCount int            `json:"recordBreakCount"`                                // 破纪录次数
@@ -0,0 +1,33 @@
+package models
+
+import "puzzle/utils"
+
+// RecordChallengeReq 挑战记录请求模型
+type RecordChallengeReq struct {
+	Id         int64            `json:"-"`        // 被挑战的记录ID
+	UserId     int64            `json:"-"`        // 查看者ID
+	IdStr      string           `json:"id"`       // 被挑战的记录ID
+	Pagination utils.Pagination `gorm:"embedded"` // 分页
+}
+
+// RecordChallengeResp 挑战打乱响应模型, 不包含原记录的解法
+type RecordChallengeResp struct {
+	RecordId        string              `json:"recordId"`        // 被挑战的记录ID
+	UserInfo        UserResp            `json:"userInfo"`        // 原记录的用户信息
+	Dimension       int                 `json:"dimension"`       // 阶数(方形边长) 0:非方形
+	Width           int                 `json:"width"`           // 宽度(列数)
+	Height          int                 `json:"height"`          // 高度(行数)
+	Duration        int                 `json:"duration"`        // 原记录耗时
+	Step            int                 `json:"step"`            // 原记录步数
+	Scramble        string              `json:"scramble"`        // 打乱公式
+	Idx             string              `json:"idx"`             // 打乱随机数
+	ScrambleVersion int                 `json:"scrambleVersion"` // 打乱生成器版本 1:旧版 2:均匀
+	Difficulty      *ScrambleDifficulty `json:"difficulty"`      // 打乱难度, 仅服务端下发的打乱有值
+}
+
+// RecordChallengeLeaderboardResp 同一打乱的排行榜响应模型
+type RecordChallengeLeaderboardResp struct {
+	Challenge RecordChallengeResp `json:"challenge"` // 打乱信息
+	Total     int64               `json:"total"`     // 总数
+	Records   []RecordResp        `json:"records"`   // 按耗时升序, 步数升序排列
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// RecordModeration 记录审核模型
+type RecordModeration struct {
+	Id         int64      `json:"id" gorm:"primaryKey"`            // 主键ID
+	RecordId   int64      `json:"recordId"`                        // 记录ID
+	UserId     int64      `json:"userId"`                          // 记录所属用户ID
+	Dimension  int        `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width      int        `json:"width"`                           // 宽度(列数)
+	Height     int        `json:"height"`                          // 高度(行数)
+	Source     int        `json:"source"`                          // 来源 1:自动标记 2:用户举报 3:管理员标记
+	Reason     string     `json:"reason"`                          // 标记原因
+	FlagCount  int        `json:"flagCount"`                       // 被标记次数
+	ReporterId int64      `json:"reporterId"`                      // 首次举报的用户ID 0:非用户举报
+	Status     int        `json:"status"`                          // 状态 1:待审核 2:已通过 3:已驳回 4:已封禁
+	Remark     string     `json:"remark"`                          // 审核备注
+	HandledAt  *time.Time `json:"handledAt"`                       // 审核时间
+	CreatedAt  time.Time  `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt  time.Time  `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// RecordModerationReq 记录审核请求模型
+type RecordModerationReq struct {
+	Id        int64 `json:"-"`         // 主键ID
+	RecordId  int64 `json:"-"`         // 记录ID
+	UserId    int64 `json:"-"`         // 记录所属用户ID
+	Dimension int   `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int   `json:"width"`     // 宽度(列数)
+	Height    int   `json:"height"`    // 高度(行数)
+	Source    int   `json:"source"`    // 来源 1:自动标记 2:用户举报 3:管理员标记
+	Status    int   `json:"status"`    // 状态 1:待审核 2:已通过 3:已驳回 4:已封禁
+
+	IdStr           string           `json:"id"`              // 主键ID
+	RecordIdStr     string           `json:"recordId"`        // 记录ID
+	UserIdStr       string           `json:"userId"`          // 记录所属用户ID
+	Reason          string           `json:"reason"`          // 标记原因
+	DateRange       []time.Time      `json:"dateRange"`       // 日期范围
+	Pagination      utils.Pagination `gorm:"embedded"`        // 分页
+	Sorted          string           `json:"sorted"`          // 排序
+	OrderBy         string           `json:"orderBy"`         // 排序字段
+	NeedUserHistory bool             `json:"needUserHistory"` // 是否需要用户历史记录
+}
+
+// RecordModerationResp 记录审核响应模型
+type RecordModerationResp struct {
+	Id          string         `json:"id"`                                                  // 主键ID
+	RecordId    string         `json:"recordId"`                                            // 记录ID
+	UserId      string         `json:"userId"`                                              // 记录所属用户ID
+	Dimension   int            `json:"dimension"`                                           // 阶数(方形边长) 0:非方形
+	Width       int            `json:"width"`                                               // 宽度(列数)
+	Height      int            `json:"height"`                                              // 高度(行数)
+	Source      int            `json:"source"`                                              // 来源 1:自动标记 2:用户举报 3:管理员标记
+	Reason      string         `json:"reason"`                                              // 标记原因
+	FlagCount   int            `json:"flagCount"`                                           // 被标记次数
+	Status      int            `json:"status"`                                              // 状态 1:待审核 2:已通过 3:已驳回 4:已封禁
+	Remark      string         `json:"remark"`                                              // 审核备注
+	ReporterId  string         `json:"reporterId"`                                          // 首次举报的用户ID
+	HandledAt   *time.Time     `json:"handledAt"`                                           // 审核时间
+	CreatedAt   time.Time      `json:"createdAt"`                                           // 创建时间
+	UpdatedAt   time.Time      `json:"updatedAt"`                                           // 更新时间
+	RecordInfo  RecordResp     `json:"recordInfo" gorm:"foreignKey:Id;references:RecordId"` // 记录信息(含打乱与解法, 可用于回放)
+	UserInfo    UserResp       `json:"userInfo" gorm:"foreignKey:Id;references:UserId"`     // 用户信息
+	UserHistory RecordListResp `json:"userHistory" gorm:"-"`                                // 用户同阶数的历史记录
+}
+
+// RecordModerationListResp 记录审核列表响应模型
+type RecordModerationListResp struct {
+	Total   int64                  `json:"total"`
+	Records []RecordModerationResp `json:"records"`
+}
+
+// RecordModerationFlagReq 标记记录请求模型
+type RecordModerationFlagReq struct {
+	RecordId    int64  `json:"-"`        // 记录ID
+	ReporterId  int64  `json:"-"`        // 举报用户ID
+	Source      int    `json:"-"`        // 来源 1:自动标记 2:用户举报 3:管理员标记
+	Reason      string `json:"reason"`   // 标记原因
+	RecordIdStr string `json:"recordId"` // 记录ID
+}
+
+// RecordModerationHandleReq 审核处理请求模型
+type RecordModerationHandleReq struct {
+	Id     int64  `json:"-"`      // 审核ID
+	IdStr  string `json:"id"`     // 审核ID
+	Action int    `json:"action"` // 操作 1:通过 2:驳回 3:封禁
+	Remark string `json:"remark"` // 审核备注
+}
+
+// RecordModerationLog 审核决定日志模型
+type RecordModerationLog struct {
+	Id           int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	ModerationId int64     `json:"moderationId"`                    // 审核ID
+	RecordId     int64     `json:"recordId"`                        // 记录ID
+	UserId       int64     `json:"userId"`                          // 记录所属用户ID
+	Action       int       `json:"action"`                          // 操作 1:通过 2:驳回 3:封禁
+	Remark       string    `json:"remark"`                          // 审核备注
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+}
+
+// RecordModerationLogReq 审核决定日志请求模型
+type RecordModerationLogReq struct {
+	ModerationId    int64            `json:"-"`            // 审核ID
+	RecordId        int64            `json:"-"`            // 记录ID
+	UserId          int64            `json:"-"`            // 记录所属用户ID
+	Action          int              `json:"action"`       // 操作 1:通过 2:驳回 3:封禁
+	ModerationIdStr string           `json:"moderationId"` // 审核ID
+	RecordIdStr     string           `json:"recordId"`     // 记录ID
+	UserIdStr       string           `json:"userId"`       // 记录所属用户ID
+	DateRange       []time.Time      `json:"dateRange"`    // 日期范围
+	Pagination      utils.Pagination `gorm:"embedded"`     // 分页
+}
+
+// RecordModerationLogResp 审核决定日志响应模型
+type RecordModerationLogResp struct {
+	Id           string    `json:"id"`           // 主键ID
+	ModerationId string    `json:"moderationId"` // 审核ID
+	RecordId     string    `json:"recordId"`     // 记录ID
+	UserId       string    `json:"userId"`       // 记录所属用户ID
+	Action       int       `json:"action"`       // 操作 1:通过 2:驳回 3:封禁
+	Remark       string    `json:"remark"`       // 审核备注
+	CreatedAt    time.Time `json:"createdAt"`    // 创建时间
+}
+
+// RecordModerationLogListResp 审核决定日志列表响应模型
+type RecordModerationLogListResp struct {
+	Total   int64                     `json:"total"`
+	Records []RecordModerationLogResp `json:"records"`
+}
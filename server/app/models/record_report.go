@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RecordReport 用户举报模型
+type RecordReport struct {
+	Id         int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	ReporterId int64     `json:"reporterId"`                      // 举报用户ID
+	TargetType int       `json:"targetType"`                      // 举报对象类型 1:最佳单次 2:最佳平均 3:最佳步数
+	TargetId   int64     `json:"targetId"`                        // 举报对象ID
+	RecordIds  string    `json:"recordIds"`                       // 举报对象关联的记录ID(升序, 逗号分隔)
+	Reason     string    `json:"reason"`                          // 举报原因
+	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+}
+
+// RecordReportReq 用户举报请求模型
+type RecordReportReq struct {
+	ReporterId  int64  `json:"-"`          // 举报用户ID
+	TargetType  int    `json:"targetType"` // 举报对象类型 1:最佳单次 2:最佳平均 3:最佳步数
+	TargetId    int64  `json:"-"`          // 举报对象ID
+	TargetIdStr string `json:"targetId"`   // 举报对象ID
+	Reason      string `json:"reason"`     // 举报原因
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// Relay 接力模型, 一次接力由多个连续的打乱组成, 成绩为所有打乱的总耗时
+type Relay struct {
+	Id        int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	UserId    int64     `json:"userId"`                          // 用户ID
+	Event     string    `json:"event"`                           // 项目 各段尺寸按顺序拼接, 如 3x3,4x4,5x5
+	LegCount  int       `json:"legCount"`                        // 段数
+	Duration  int       `json:"duration"`                        // 总耗时
+	Step      int       `json:"step"`                            // 总步数
+	Status    int       `json:"status" gorm:"default 1"`         // 状态 1:进行中 2:已完成 3:已放弃
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// RelayLeg 接力中的一段
+type RelayLeg struct {
+	Id              int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	RelayId         int64     `json:"relayId"`                         // 接力ID
+	Seq             int       `json:"seq"`                             // 序号 从1开始
+	Dimension       int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width           int       `json:"width"`                           // 宽度(列数)
+	Height          int       `json:"height"`                          // 高度(行数)
+	ScrambleId      int64     `json:"scrambleId"`                      // 打乱ID
+	Idx             int64     `json:"idx"`                             // 打乱随机数
+	Scramble        string    `json:"scramble"`                        // 打乱公式
+	ScrambleVersion int       `json:"scrambleVersion"`                 // 打乱生成器版本 1:旧版 2:均匀
+	Solution        string    `json:"solution"`                        // 解法
+	Duration        int       `json:"duration"`                        // 分段耗时
+	Step            int       `json:"step"`                            // 步数
+	CreatedAt       time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt       time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// RelaySize 接力中一段的尺寸
+type RelaySize struct {
+	Dimension int `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int `json:"width"`     // 宽度(列数)
+	Height    int `json:"height"`    // 高度(行数)
+}
+
+// RelayStartReq 开始接力请求模型
+type RelayStartReq struct {
+	UserId int64       `json:"-"`     // 用户ID
+	Sizes  []RelaySize `json:"sizes"` // 各段尺寸, 按顺序还原
+}
+
+// RelayLegReq 提交接力中一段的请求模型
+type RelayLegReq struct {
+	Seq      int    `json:"seq"`      // 序号
+	Duration int    `json:"duration"` // 分段耗时
+	Step     int    `json:"step"`     // 步数
+	Solution string `json:"solution"` // 解法
+}
+
+// RelaySubmitReq 提交接力请求模型, 需要一次提交所有段
+type RelaySubmitReq struct {
+	Id     int64         `json:"-"`    // 主键ID
+	UserId int64         `json:"-"`    // 用户ID
+	IdStr  string        `json:"id"`   // 主键ID
+	Legs   []RelayLegReq `json:"legs"` // 各段成绩
+}
+
+// RelayReq 接力请求模型
+type RelayReq struct {
+	Id         int64            `json:"-"`        // 主键ID
+	UserId     int64            `json:"-"`        // 用户ID
+	IdStr      string           `json:"id"`       // 主键ID
+	UserIdStr  string           `json:"userId"`   // 用户ID
+	Event      string           `json:"event"`    // 项目
+	Status     int              `json:"status"`   // 状态 1:进行中 2:已完成 3:已放弃
+	Pagination utils.Pagination `gorm:"embedded"` // 分页
+	Sorted     string           `json:"sorted"`   // 排序
+}
+
+// RelayLegResp 接力中一段的响应模型
+type RelayLegResp struct {
+	Id              string    `json:"id"`              // 主键ID
+	RelayId         string    `json:"relayId"`         // 接力ID
+	Seq             int       `json:"seq"`             // 序号
+	Dimension       int       `json:"dimension"`       // 阶数(方形边长) 0:非方形
+	Width           int       `json:"width"`           // 宽度(列数)
+	Height          int       `json:"height"`          // 高度(行数)
+	Idx             string    `json:"idx"`             // 打乱随机数
+	Scramble        string    `json:"scramble"`        // 打乱公式
+	ScrambleVersion int       `json:"scrambleVersion"` // 打乱生成器版本 1:旧版 2:均匀
+	Solution        string    `json:"solution"`        // 解法
+	Duration        int       `json:"duration"`        // 分段耗时
+	Step            int       `json:"step"`            // 步数
+	CreatedAt       time.Time `json:"createdAt"`       // 创建时间
+	UpdatedAt       time.Time `json:"updatedAt"`       // 更新时间
+}
+
+func (RelayLegResp) TableName() string {
+	return "relay_leg"
+}
+
+// RelayResp 接力响应模型
+type RelayResp struct {
+	Id        string         `json:"id"`                                              // 主键ID
+	UserId    string         `json:"userId"`                                          // 用户ID
+	UserInfo  UserResp       `json:"userInfo" gorm:"foreignKey:Id;references:UserId"` // 用户信息
+	Event     string         `json:"event"`                                           // 项目
+	LegCount  int            `json:"legCount"`                                        // 段数
+	Duration  int            `json:"duration"`                                        // 总耗时
+	Step      int            `json:"step"`                                            // 总步数
+	Status    int            `json:"status"`                                          // 状态 1:进行中 2:已完成 3:已放弃
+	Legs      []RelayLegResp `json:"legs" gorm:"foreignKey:RelayId;references:Id"`    // 各段, 按序号排列
+	CreatedAt time.Time      `json:"createdAt"`                                       // 创建时间
+	UpdatedAt time.Time      `json:"updatedAt"`                                       // 更新时间
+}
+
+// RelayListResp 接力列表响应模型
+type RelayListResp struct {
+	Total   int64       `json:"total"`   // 总数
+	Records []RelayResp `json:"records"` // 接力列表
+}
+
+func (RelayResp) TableName() string {
+	return "relay"
+}
@@ -5,21 +5,43 @@ import (
 	"time"
 )
 
+// ScrambleDifficulty 打乱难度
+type ScrambleDifficulty struct {
+	ManhattanDistance int `json:"manhattanDistance"` // 曼哈顿距离之和
+	LinearConflicts   int `json:"linearConflicts"`   // 线性冲突数
+	OptimalLength     int `json:"optimalLength"`     // 最少单格移动步数 -1:无法计算
+}
+
+// ScrambleKey 打乱的唯一标识, 不同尺寸与生成器版本的打乱可能使用相同的随机数
+type ScrambleKey struct {
+	Idx     int64 // 随机数索引
+	Width   int   // 宽度(列数)
+	Height  int   // 高度(行数)
+	Version int   // 打乱生成器版本 1:旧版 2:均匀
+}
+
 type Scramble struct {
-	Id        int64     `json:"id" gorm:"primaryKey"`            // 主键ID
-	Dimension int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6
-	Idx       int64     `json:"idx"`                             // 随机数索引
-	Scramble  string    `json:"scramble"`                        // 打乱公式
-	Status    int       `json:"status" gorm:"default 1"`         // 状态 1:启用 2:冻结 3:删除
-	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
-	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+	Id         int64              `json:"id" gorm:"primaryKey"`            // 主键ID
+	Dimension  int                `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width      int                `json:"width"`                           // 宽度(列数)
+	Height     int                `json:"height"`                          // 高度(行数)
+	Idx        int64              `json:"idx"`                             // 随机数索引
+	Scramble   string             `json:"scramble"`                        // 打乱公式
+	Version    int                `json:"version"`                         // 打乱生成器版本 1:旧版 2:均匀
+	Difficulty ScrambleDifficulty `json:"difficulty" gorm:"embedded"`      // 难度
+	Status     int                `json:"status" gorm:"default 1"`         // 状态 1:启用 2:冻结 3:删除
+	CreatedAt  time.Time          `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt  time.Time          `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
 }
 
 type ScrambleReq struct {
 	Id         int64            `json:"id"`        // 主键ID
-	Dimension  int              `json:"dimension"` // 阶数 3 | 4 | 5 | 6
+	Dimension  int              `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width      int              `json:"width"`     // 宽度(列数)
+	Height     int              `json:"height"`    // 高度(行数)
 	Idx        int64            `json:"idx"`       // 随机数索引
 	Scramble   string           `json:"scramble"`  // 打乱公式
+	Version    int              `json:"version"`   // 打乱生成器版本 1:旧版 2:均匀
 	Status     int              `json:"status"`    // 状态 1:启用 2:冻结 3:删除
 	DateRange  []time.Time      `json:"dateRange"` // 时间范围
 	Pagination utils.Pagination `gorm:"embedded"`  // 分页
@@ -27,13 +49,17 @@ type ScrambleReq struct {
 }
 
 type ScrambleResp struct {
-	Id        int64     `json:"id" gorm:"primaryKey"`            // 主键ID
-	Dimension int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6
-	Idx       int64     `json:"idx"`                             // 随机数索引
-	Scramble  string    `json:"scramble"`                        // 打乱公式
-	Status    int       `json:"status" gorm:"default 1"`         // 状态 1:启用 2:冻结 3:删除
-	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
-	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+	Id         int64              `json:"id" gorm:"primaryKey"`            // 主键ID
+	Dimension  int                `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width      int                `json:"width"`                           // 宽度(列数)
+	Height     int                `json:"height"`                          // 高度(行数)
+	Idx        int64              `json:"idx"`                             // 随机数索引
+	Scramble   string             `json:"scramble"`                        // 打乱公式
+	Version    int                `json:"version"`                         // 打乱生成器版本 1:旧版 2:均匀
+	Difficulty ScrambleDifficulty `json:"difficulty" gorm:"embedded"`      // 难度
+	Status     int                `json:"status" gorm:"default 1"`         // 状态 1:启用 2:冻结 3:删除
+	CreatedAt  time.Time          `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt  time.Time          `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
 }
 
 type ScrambleListResp struct {
@@ -43,5 +69,8 @@ type ScrambleListResp struct {
 
 type GetNewScambleReq struct {
 	UserId    int64 `json:"userId"`    // 用户ID
-	Dimension int   `json:"dimension"` // 阶数 3 | 4 | 5 | 6
+	Dimension int   `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width     int   `json:"width"`     // 宽度(列数)
+	Height    int   `json:"height"`    // 高度(行数)
+	Event     int   `json:"event"`     // 项目 1:普通 2:盲拧
 }
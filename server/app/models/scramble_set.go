@@ -0,0 +1,120 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// ScrambleSet 打乱组模型, 组内的打乱对所有参与者相同
+type ScrambleSet struct {
+	Id        int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	Name      string    `json:"name"`                            // 名称
+	CreatorId int64     `json:"creatorId"`                       // 创建者ID
+	Dimension int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width     int       `json:"width"`                           // 宽度(列数)
+	Height    int       `json:"height"`                          // 高度(行数)
+	Count     int       `json:"count"`                           // 打乱数量
+	Status    int       `json:"status" gorm:"default 1"`         // 状态 1:启用 2:冻结 3:删除
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// ScrambleSetReq 打乱组请求模型
+type ScrambleSetReq struct {
+	Id         int64            `json:"-"`         // 主键ID
+	CreatorId  int64            `json:"-"`         // 创建者ID
+	UserId     int64            `json:"-"`         // 查看者ID
+	IdStr      string           `json:"id"`        // 主键ID
+	Name       string           `json:"name"`      // 名称
+	Dimension  int              `json:"dimension"` // 阶数(方形边长) 0:非方形
+	Width      int              `json:"width"`     // 宽度(列数)
+	Height     int              `json:"height"`    // 高度(行数)
+	Count      int              `json:"count"`     // 打乱数量
+	Pagination utils.Pagination `gorm:"embedded"`  // 分页
+	Sorted     string           `json:"sorted"`    // 排序
+}
+
+// ScrambleSetResp 打乱组响应模型
+type ScrambleSetResp struct {
+	Id          string    `json:"id"`                                                    // 主键ID
+	Name        string    `json:"name"`                                                  // 名称
+	CreatorId   string    `json:"creatorId"`                                             // 创建者ID
+	CreatorInfo UserResp  `json:"creatorInfo" gorm:"foreignKey:Id;references:CreatorId"` // 创建者信息
+	Dimension   int       `json:"dimension"`                                             // 阶数(方形边长) 0:非方形
+	Width       int       `json:"width"`                                                 // 宽度(列数)
+	Height      int       `json:"height"`                                                // 高度(行数)
+	Count       int       `json:"count"`                                                 // 打乱数量
+	Status      int       `json:"status"`                                                // 状态 1:启用 2:冻结 3:删除
+	CreatedAt   time.Time `json:"createdAt"`                                             // 创建时间
+	UpdatedAt   time.Time `json:"updatedAt"`                                             // 更新时间
+}
+
+// ScrambleSetListResp 打乱组列表响应模型
+type ScrambleSetListResp struct {
+	Total   int64             `json:"total"`   // 总数
+	Records []ScrambleSetResp `json:"records"` // 打乱组列表
+}
+
+// ScrambleSetItem 打乱组中的打乱
+type ScrambleSetItem struct {
+	Id         int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	SetId      int64     `json:"setId"`                           // 打乱组ID
+	Seq        int       `json:"seq"`                             // 序号 从1开始
+	ScrambleId int64     `json:"scrambleId"`                      // 打乱ID
+	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+}
+
+// ScrambleSession 用户挑战打乱组的进度
+type ScrambleSession struct {
+	Id        int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	SetId     int64     `json:"setId"`                           // 打乱组ID
+	UserId    int64     `json:"userId"`                          // 用户ID
+	Current   int       `json:"current"`                         // 当前打乱的序号
+	Status    int       `json:"status" gorm:"default 1"`         // 状态 1:进行中 2:已完成
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// ScrambleSessionReq 打乱组挑战请求模型
+type ScrambleSessionReq struct {
+	Id       int64  `json:"-"`     // 主键ID
+	SetId    int64  `json:"-"`     // 打乱组ID
+	UserId   int64  `json:"-"`     // 用户ID
+	IdStr    string `json:"id"`    // 主键ID
+	SetIdStr string `json:"setId"` // 打乱组ID
+}
+
+// ScrambleSessionResp 打乱组挑战响应模型
+type ScrambleSessionResp struct {
+	Id       string        `json:"id"`       // 主键ID
+	SetId    string        `json:"setId"`    // 打乱组ID
+	Current  int           `json:"current"`  // 当前打乱的序号
+	Count    int           `json:"count"`    // 打乱数量
+	Status   int           `json:"status"`   // 状态 1:进行中 2:已完成
+	Scramble *ScrambleResp `json:"scramble"` // 当前打乱 已完成时为空
+}
+
+// ScrambleSetResultItem 用户在打乱组中某个打乱的成绩
+type ScrambleSetResultItem struct {
+	Seq      int    `json:"seq"`      // 序号
+	RecordId string `json:"recordId"` // 记录ID
+	Duration int    `json:"duration"` // 耗时
+	Step     int    `json:"step"`     // 步数
+}
+
+// ScrambleSetResult 用户在打乱组中的成绩
+type ScrambleSetResult struct {
+	UserId          string                  `json:"userId"`          // 用户ID
+	UserInfo        UserResp                `json:"userInfo"`        // 用户信息
+	SolvedCount     int                     `json:"solvedCount"`     // 完成数量
+	TotalDuration   int                     `json:"totalDuration"`   // 总耗时
+	AverageDuration int                     `json:"averageDuration"` // 平均耗时
+	TotalStep       int                     `json:"totalStep"`       // 总步数
+	Items           []ScrambleSetResultItem `json:"items"`           // 每个打乱的成绩
+}
+
+// ScrambleSetResultResp 打乱组成绩对比响应模型
+type ScrambleSetResultResp struct {
+	SetInfo ScrambleSetResp     `json:"setInfo"` // 打乱组信息
+	Results []ScrambleSetResult `json:"results"` // 按完成数量降序, 总耗时升序排列
+}
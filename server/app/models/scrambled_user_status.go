@@ -8,8 +8,12 @@ import (
 type ScrambledUserStatus struct {
 	Id         int64     `json:"id" gorm:"primaryKey"`            // ID
 	UserId     int64     `json:"userId"`                          // 用户ID
-	Dimension  int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6
+	Dimension  int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width      int       `json:"width"`                           // 宽度(列数)
+	Height     int       `json:"height"`                          // 高度(行数)
 	ScrambleId int64     `json:"scrambleId"`                      // 打乱公式ID
+	SessionId  int64     `json:"sessionId"`                       // 打乱组挑战ID 0:排行榜打乱
+	Event      int       `json:"event"`                           // 项目 1:普通 2:盲拧
 	Status     int       `json:"status"`                          // 完成状态 1:未完成 2:已完成
 	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
 	UpdatedAt  time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
@@ -18,8 +22,12 @@ type ScrambledUserStatus struct {
 type ScrambledUserStatusReq struct {
 	Id         int64            `json:"id"`         // ID
 	UserId     int64            `json:"userId"`     // 用户ID
-	Dimension  int              `json:"dimension"`  // 阶数 3 | 4 | 5 | 6
+	Dimension  int              `json:"dimension"`  // 阶数(方形边长) 0:非方形
+	Width      int              `json:"width"`      // 宽度(列数)
+	Height     int              `json:"height"`     // 高度(行数)
 	ScrambleId int64            `json:"scrambleId"` // 打乱公式ID
+	SessionId  int64            `json:"-"`          // 打乱组挑战ID 0:排行榜打乱
+	Event      int              `json:"event"`      // 项目 1:普通 2:盲拧
 	Status     int              `json:"status"`     // 完成状态 1:未完成 2:已完成
 	DateRange  []time.Time      `json:"dateRange"`  // 时间范围
 	Pagination utils.Pagination `gorm:"embedded"`   // 分页
@@ -29,8 +37,12 @@ type ScrambledUserStatusReq struct {
 type ScrambledUserStatusResp struct {
 	Id         string    `json:"id"`                              // ID
 	UserId     int64     `json:"userId"`                          // 用户ID
-	Dimension  int       `json:"dimension"`                       // 阶数 3 | 4 | 5 | 6
+	Dimension  int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width      int       `json:"width"`                           // 宽度(列数)
+	Height     int       `json:"height"`                          // 高度(行数)
 	ScrambleId int64     `json:"scrambleId"`                      // 打乱公式ID
+	SessionId  int64     `json:"sessionId"`                       // 打乱组挑战ID 0:排行榜打乱
+	Event      int       `json:"event"`                           // 项目 1:普通 2:盲拧
 	Status     int       `json:"status"`                          // 完成状态 1:未完成 2:已完成
 	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
 	UpdatedAt  time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
@@ -0,0 +1,20 @@
+package models
+
+// SolutionAnalysisReq 解法分析请求模型
+type SolutionAnalysisReq struct {
+	RecordId    int64  `json:"-"`        // 记录ID
+	RecordIdStr string `json:"recordId"` // 记录ID
+}
+
+// SolutionAnalysisResp 解法分析响应模型
+type SolutionAnalysisResp struct {
+	RecordId             string `json:"recordId"`             // 记录ID
+	RawStep              int    `json:"rawStep"`              // 原始步数
+	CleanedStep          int    `json:"cleanedStep"`          // 删除冗余移动后的步数
+	InvalidStep          int    `json:"invalidStep"`          // 不移动的点击次数 仅旧版打乱
+	CancelledStep        int    `json:"cancelledStep"`        // 往返抵消与循环删除的步数
+	MergedStep           int    `json:"mergedStep"`           // 合并同一方向的连续移动减少的步数
+	Solution             string `json:"solution"`             // 删除冗余移动后的解法(点击数字)
+	DirectionSolution    string `json:"directionSolution"`    // 删除冗余移动后的解法(方向记法)
+	RawDirectionSolution string `json:"rawDirectionSolution"` // 原始解法(方向记法)
+}
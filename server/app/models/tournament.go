@@ -0,0 +1,227 @@
+package models
+
+import (
+	"puzzle/utils"
+	"time"
+)
+
+// Tournament 赛事模型, 由管理员创建, 选手报名后开赛
+// 淘汰赛在开赛时生成完整的对阵表, 瑞士制每轮结束后按积分生成下一轮对阵
+type Tournament struct {
+	Id           int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	Name         string    `json:"name"`                            // 名称
+	Format       int       `json:"format"`                          // 赛制 1:单败淘汰 2:双败淘汰 3:瑞士制
+	SeedMethod   int       `json:"seedMethod"`                      // 种子排序 1:最佳平均 2:最佳单次 3:报名顺序
+	Dimension    int       `json:"dimension"`                       // 阶数(方形边长) 0:非方形
+	Width        int       `json:"width"`                           // 宽度(列数)
+	Height       int       `json:"height"`                          // 高度(行数)
+	MaxPlayers   int       `json:"maxPlayers"`                      // 最多选手数量
+	BestOf       int       `json:"bestOf"`                          // 每场比赛的局数 先赢过半数局的选手获胜
+	SwissRounds  int       `json:"swissRounds"`                     // 瑞士制轮数 0:按人数自动计算
+	CurrentRound int       `json:"currentRound"`                    // 瑞士制当前轮次
+	WinnerId     int64     `json:"winnerId"`                        // 冠军用户ID
+	Status       int       `json:"status" gorm:"default 1"`         // 状态 1:报名中 2:进行中 3:已结束 4:已取消
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// TournamentPlayer 赛事选手
+type TournamentPlayer struct {
+	Id           int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	TournamentId int64     `json:"tournamentId"`                    // 赛事ID
+	UserId       int64     `json:"userId"`                          // 用户ID
+	Seed         int       `json:"seed"`                            // 种子序号 从1开始, 开赛前为0
+	Points       int       `json:"points"`                          // 胜场(瑞士制积分)
+	Losses       int       `json:"losses"`                          // 负场
+	Status       int       `json:"status" gorm:"default 1"`         // 状态 1:参赛中 2:已淘汰
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// TournamentMatch 赛事中的一场比赛
+// 淘汰赛的比赛通过 NextMatchId 与 LoserMatchId 记录胜者与败者进入的下一场比赛
+type TournamentMatch struct {
+	Id            int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	TournamentId  int64     `json:"tournamentId"`                    // 赛事ID
+	Bracket       int       `json:"bracket"`                         // 分组 1:胜者组 2:败者组 3:总决赛 4:瑞士制
+	Round         int       `json:"round"`                           // 分组内的轮次 从1开始 总决赛第2轮为加赛
+	Position      int       `json:"position"`                        // 轮次内的序号 从0开始
+	Player1Id     int64     `json:"player1Id"`                       // 选手1用户ID
+	Player2Id     int64     `json:"player2Id"`                       // 选手2用户ID
+	Player1Status int       `json:"player1Status"`                   // 选手1状态 1:待定 2:已确定 3:轮空
+	Player2Status int       `json:"player2Status"`                   // 选手2状态 1:待定 2:已确定 3:轮空
+	Player1Wins   int       `json:"player1Wins"`                     // 选手1胜局数
+	Player2Wins   int       `json:"player2Wins"`                     // 选手2胜局数
+	WinnerId      int64     `json:"winnerId"`                        // 胜者用户ID 0:无
+	NextMatchId   int64     `json:"nextMatchId"`                     // 胜者进入的比赛ID 0:无
+	NextSlot      int       `json:"nextSlot"`                        // 胜者在下一场比赛中的位置 1:选手1 2:选手2
+	LoserMatchId  int64     `json:"loserMatchId"`                    // 败者进入的比赛ID 0:无
+	LoserSlot     int       `json:"loserSlot"`                       // 败者在下一场比赛中的位置 1:选手1 2:选手2
+	Status        int       `json:"status" gorm:"default 1"`         // 状态 1:等待选手 2:进行中 3:已结束
+	CreatedAt     time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// TournamentGame 比赛中的一局, 两名选手使用同一个打乱
+type TournamentGame struct {
+	Id              int64     `json:"id" gorm:"primaryKey"`            // 主键ID
+	MatchId         int64     `json:"matchId"`                         // 比赛ID
+	Seq             int       `json:"seq"`                             // 局数 从1开始
+	Idx             int64     `json:"idx"`                             // 打乱随机数
+	Scramble        string    `json:"scramble"`                        // 打乱公式
+	ScrambleVersion int       `json:"scrambleVersion"`                 // 打乱生成器版本 1:旧版 2:均匀
+	Player1Duration int       `json:"player1Duration"`                 // 选手1耗时
+	Player1Step     int       `json:"player1Step"`                     // 选手1步数
+	Player1Solution string    `json:"player1Solution"`                 // 选手1解法
+	Player1Status   int       `json:"player1Status"`                   // 选手1状态 1:未提交 2:完成 3:放弃
+	Player2Duration int       `json:"player2Duration"`                 // 选手2耗时
+	Player2Step     int       `json:"player2Step"`                     // 选手2步数
+	Player2Solution string    `json:"player2Solution"`                 // 选手2解法
+	Player2Status   int       `json:"player2Status"`                   // 选手2状态 1:未提交 2:完成 3:放弃
+	WinnerId        int64     `json:"winnerId"`                        // 胜者用户ID 0:无(平局或双方放弃, 需要重赛)
+	Status          int       `json:"status" gorm:"default 1"`         // 状态 1:进行中 2:已结束
+	CreatedAt       time.Time `json:"createdAt" gorm:"autoCreateTime"` // 创建时间
+	UpdatedAt       time.Time `json:"updatedAt" gorm:"autoUpdateTime"` // 更新时间
+}
+
+// TournamentCreateReq 创建赛事请求模型
+type TournamentCreateReq struct {
+	Name        string `json:"name"`        // 名称
+	Format      int    `json:"format"`      // 赛制 1:单败淘汰 2:双败淘汰 3:瑞士制
+	SeedMethod  int    `json:"seedMethod"`  // 种子排序 1:最佳平均 2:最佳单次 3:报名顺序
+	Dimension   int    `json:"dimension"`   // 阶数(方形边长) 0:非方形
+	Width       int    `json:"width"`       // 宽度(列数)
+	Height      int    `json:"height"`      // 高度(行数)
+	MaxPlayers  int    `json:"maxPlayers"`  // 最多选手数量
+	BestOf      int    `json:"bestOf"`      // 每场比赛的局数 1、3、5、7
+	SwissRounds int    `json:"swissRounds"` // 瑞士制轮数 0:按人数自动计算
+}
+
+// TournamentReq 赛事请求模型
+type TournamentReq struct {
+	Id         int64            `json:"-"`        // 主键ID
+	UserId     int64            `json:"-"`        // 用户ID
+	IdStr      string           `json:"id"`       // 主键ID
+	Format     int              `json:"format"`   // 赛制 1:单败淘汰 2:双败淘汰 3:瑞士制
+	Status     int              `json:"status"`   // 状态 1:报名中 2:进行中 3:已结束 4:已取消
+	Pagination utils.Pagination `gorm:"embedded"` // 分页
+	Sorted     string           `json:"sorted"`   // 排序
+}
+
+// TournamentMatchReq 比赛请求模型
+type TournamentMatchReq struct {
+	Id          int64  `json:"-"`        // 比赛ID
+	UserId      int64  `json:"-"`        // 用户ID
+	WinnerId    int64  `json:"-"`        // 胜者用户ID 仅管理员判定
+	IdStr       string `json:"id"`       // 比赛ID
+	WinnerIdStr string `json:"winnerId"` // 胜者用户ID 仅管理员判定
+}
+
+// TournamentSubmitReq 提交一局成绩请求模型
+type TournamentSubmitReq struct {
+	MatchId    int64  `json:"-"`        // 比赛ID
+	UserId     int64  `json:"-"`        // 用户ID
+	MatchIdStr string `json:"matchId"`  // 比赛ID
+	Seq        int    `json:"seq"`      // 局数
+	Solution   string `json:"solution"` // 解法 放弃时为空
+	Duration   int    `json:"duration"` // 耗时
+	Step       int    `json:"step"`     // 步数
+	Status     int    `json:"status"`   // 状态 2:完成 3:放弃
+}
+
+// TournamentGameResp 比赛中一局的响应模型
+type TournamentGameResp struct {
+	Id              string    `json:"id"`              // 主键ID
+	MatchId         string    `json:"matchId"`         // 比赛ID
+	Seq             int       `json:"seq"`             // 局数
+	Scramble        string    `json:"scramble"`        // 打乱公式
+	ScrambleVersion int       `json:"scrambleVersion"` // 打乱生成器版本 1:旧版 2:均匀
+	Player1Duration int       `json:"player1Duration"` // 选手1耗时
+	Player1Step     int       `json:"player1Step"`     // 选手1步数
+	Player1Status   int       `json:"player1Status"`   // 选手1状态 1:未提交 2:完成 3:放弃
+	Player2Duration int       `json:"player2Duration"` // 选手2耗时
+	Player2Step     int       `json:"player2Step"`     // 选手2步数
+	Player2Status   int       `json:"player2Status"`   // 选手2状态 1:未提交 2:完成 3:放弃
+	WinnerId        string    `json:"winnerId"`        // 胜者用户ID
+	Status          int       `json:"status"`          // 状态 1:进行中 2:已结束
+	CreatedAt       time.Time `json:"createdAt"`       // 创建时间
+	UpdatedAt       time.Time `json:"updatedAt"`       // 更新时间
+}
+
+func (TournamentGameResp) TableName() string {
+	return "tournament_game"
+}
+
+// TournamentMatchResp 比赛响应模型
+type TournamentMatchResp struct {
+	Id            string               `json:"id"`                                            // 主键ID
+	TournamentId  string               `json:"tournamentId"`                                  // 赛事ID
+	Bracket       int                  `json:"bracket"`                                       // 分组 1:胜者组 2:败者组 3:总决赛 4:瑞士制
+	Round         int                  `json:"round"`                                         // 分组内的轮次
+	Position      int                  `json:"position"`                                      // 轮次内的序号
+	Player1Id     string               `json:"player1Id"`                                     // 选手1用户ID
+	Player2Id     string               `json:"player2Id"`                                     // 选手2用户ID
+	Player1Status int                  `json:"player1Status"`                                 // 选手1状态 1:待定 2:已确定 3:轮空
+	Player2Status int                  `json:"player2Status"`                                 // 选手2状态 1:待定 2:已确定 3:轮空
+	Player1Wins   int                  `json:"player1Wins"`                                   // 选手1胜局数
+	Player2Wins   int                  `json:"player2Wins"`                                   // 选手2胜局数
+	WinnerId      string               `json:"winnerId"`                                      // 胜者用户ID
+	NextMatchId   string               `json:"nextMatchId"`                                   // 胜者进入的比赛ID
+	LoserMatchId  string               `json:"loserMatchId"`                                  // 败者进入的比赛ID
+	Status        int                  `json:"status"`                                        // 状态 1:等待选手 2:进行中 3:已结束
+	Games         []TournamentGameResp `json:"games" gorm:"foreignKey:MatchId;references:Id"` // 各局, 按局数排列
+	CreatedAt     time.Time            `json:"createdAt"`                                     // 创建时间
+	UpdatedAt     time.Time            `json:"updatedAt"`                                     // 更新时间
+}
+
+func (TournamentMatchResp) TableName() string {
+	return "tournament_match"
+}
+
+// TournamentPlayerResp 赛事选手响应模型
+type TournamentPlayerResp struct {
+	Id           string    `json:"id"`                                              // 主键ID
+	TournamentId string    `json:"tournamentId"`                                    // 赛事ID
+	UserId       string    `json:"userId"`                                          // 用户ID
+	UserInfo     UserResp  `json:"userInfo" gorm:"foreignKey:Id;references:UserId"` // 用户信息
+	Seed         int       `json:"seed"`                                            // 种子序号
+	Points       int       `json:"points"`                                          // 胜场(瑞士制积分)
+	Losses       int       `json:"losses"`                                          // 负场
+	Status       int       `json:"status"`                                          // 状态 1:参赛中 2:已淘汰
+	CreatedAt    time.Time `json:"createdAt"`                                       // 创建时间
+}
+
+func (TournamentPlayerResp) TableName() string {
+	return "tournament_player"
+}
+
+// TournamentResp 赛事响应模型, 列表中不包含选手与比赛
+type TournamentResp struct {
+	Id           string                 `json:"id"`                                                   // 主键ID
+	Name         string                 `json:"name"`                                                 // 名称
+	Format       int                    `json:"format"`                                               // 赛制 1:单败淘汰 2:双败淘汰 3:瑞士制
+	SeedMethod   int                    `json:"seedMethod"`                                           // 种子排序 1:最佳平均 2:最佳单次 3:报名顺序
+	Dimension    int                    `json:"dimension"`                                            // 阶数(方形边长) 0:非方形
+	Width        int                    `json:"width"`                                                // 宽度(列数)
+	Height       int                    `json:"height"`                                               // 高度(行数)
+	MaxPlayers   int                    `json:"maxPlayers"`                                           // 最多选手数量
+	BestOf       int                    `json:"bestOf"`                                               // 每场比赛的局数
+	SwissRounds  int                    `json:"swissRounds"`                                          // 瑞士制轮数
+	CurrentRound int                    `json:"currentRound"`                                         // 瑞士制当前轮次
+	WinnerId     string                 `json:"winnerId"`                                             // 冠军用户ID
+	Status       int                    `json:"status"`                                               // 状态 1:报名中 2:进行中 3:已结束 4:已取消
+	Players      []TournamentPlayerResp `json:"players" gorm:"foreignKey:TournamentId;references:Id"` // 选手, 按种子排列
+	Matches      []TournamentMatchResp  `json:"matches" gorm:"foreignKey:TournamentId;references:Id"` // 比赛, 按分组、轮次与序号排列
+	CreatedAt    time.Time              `json:"createdAt"`                                            // 创建时间
+	UpdatedAt    time.Time              `json:"updatedAt"`                                            // 更新时间
+}
+
+func (TournamentResp) TableName() string {
+	return "tournament"
+}
+
+// TournamentListResp 赛事列表响应模型
+type TournamentListResp struct {
+	Total   int64            `json:"total"`   // 总数
+	Records []TournamentResp `json:"records"` // 赛事列表
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"puzzle/app/middlewares/websocket"
+	"puzzle/app/models"
+	"puzzle/config"
+	"puzzle/database"
+	"puzzle/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	chatLobbyChannel      = "lobby" // 大厅频道
+	chatContentMaxLength  = 200     // 消息最大长度
+	defaultChatRateLimit  = 5       // 默认计数周期内最多发送的消息数量
+	defaultChatRateWindow = 10      // 默认发送频率计数周期(秒)
+)
+
+var (
+	ErrChatRateLimited = errors.New("发送太频繁, 请稍后再试")
+	ErrChatBanned      = errors.New("你已被禁止使用聊天")
+)
+
+type ChatService interface {
+	Join(ctx *websocket.Context) error
+	Leave(ctx *websocket.Context) error
+	Send(ctx *websocket.Context) error
+	History(messageReq *models.ChatMessageReq) (models.ChatMessageListResp, error)
+	List(messageReq *models.ChatMessageReq) (models.ChatMessageListResp, error)
+	Update(messageReq *models.ChatMessageReq) error
+	Restrict(restrictionReq *models.ChatRestrictionReq) error
+	ListRestriction(restrictionReq *models.ChatRestrictionReq) (models.ChatRestrictionListResp, error)
+	UpdateRestriction(restrictionReq *models.ChatRestrictionReq) error
+	send(client *websocket.Client, sendReq *models.ChatSendReq) (models.ChatMessageResp, error)
+	checkChannel(channel string) error
+	checkRestriction(userId int64) (*models.ChatRestriction, error)
+	checkRateLimit(clientId string) error
+	filter(content string) string
+}
+
+type ChatImpl struct{}
+
+// Join 加入大厅频道, 消息内容为 ChatChannelReq, 需要登录
+// 竞速房间与直播频道随加入房间、观看直播自动加入
+func (ChatImpl) Join(ctx *websocket.Context) error {
+	client := ctx.Client
+
+	var channelReq models.ChatChannelReq
+	if err := ctx.Bind(&channelReq); err != nil {
+		return err
+	}
+
+	if channelReq.Channel != chatLobbyChannel {
+		return errors.New("只能加入大厅频道")
+	}
+
+	// 未登录的连接不能停留在大厅频道, 避免匿名连接长期占用
+	if client.BindUserId == "" {
+		return websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	userId, _ := strconv.ParseInt(client.BindUserId, 10, 64)
+	restriction, err := Chat.checkRestriction(userId)
+	if err != nil {
+		return err
+	}
+
+	if restriction != nil && restriction.Type == 2 {
+		return ErrChatBanned
+	}
+
+	client.JoinGroupById(chatLobbyChannel)
+	ctx.Reply("chat-joined", channelReq)
+
+	return nil
+}
+
+// Leave 退出大厅频道, 消息内容为 ChatChannelReq
+func (ChatImpl) Leave(ctx *websocket.Context) error {
+	var channelReq models.ChatChannelReq
+	if err := ctx.Bind(&channelReq); err != nil {
+		return err
+	}
+
+	if channelReq.Channel == chatLobbyChannel {
+		ctx.Client.LeaveGroupById(chatLobbyChannel)
+	}
+
+	return nil
+}
+
+// Send 发送聊天消息, 消息内容为 ChatSendReq, 需要已加入该频道, 成功后向频道推送 chat-message
+func (ChatImpl) Send(ctx *websocket.Context) error {
+	var sendReq models.ChatSendReq
+	if err := ctx.Bind(&sendReq); err != nil {
+		return err
+	}
+
+	messageResp, err := Chat.send(ctx.Client, &sendReq)
+	if err != nil {
+		return err
+	}
+
+	message, _ := json.Marshal(messageResp)
+	websocket.SendToGroup(messageResp.Channel, "chat-message", string(message))
+
+	return nil
+}
+
+// send 校验并保存聊天消息
+func (ChatImpl) send(client *websocket.Client, sendReq *models.ChatSendReq) (models.ChatMessageResp, error) {
+	if client.BindUserId == "" {
+		return models.ChatMessageResp{}, websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	if !client.InGroup(sendReq.Channel) {
+		return models.ChatMessageResp{}, errors.New("请先加入该频道")
+	}
+
+	sendReq.Content = strings.TrimSpace(sendReq.Content)
+	if sendReq.Content == "" {
+		return models.ChatMessageResp{}, errors.New("消息内容不能为空")
+	}
+
+	if len([]rune(sendReq.Content)) > chatContentMaxLength {
+		return models.ChatMessageResp{}, fmt.Errorf("消息内容不能超过%d字", chatContentMaxLength)
+	}
+
+	userId, _ := strconv.ParseInt(client.BindUserId, 10, 64)
+
+	restriction, err := Chat.checkRestriction(userId)
+	if err != nil {
+		return models.ChatMessageResp{}, err
+	}
+
+	if restriction != nil {
+		if restriction.Type == 2 {
+			return models.ChatMessageResp{}, ErrChatBanned
+		}
+
+		if restriction.ExpiredAt == nil {
+			return models.ChatMessageResp{}, errors.New("你已被禁言")
+		}
+
+		return models.ChatMessageResp{}, fmt.Errorf("你已被禁言至 %s", restriction.ExpiredAt.Format(time.DateTime))
+	}
+
+	if err = Chat.checkRateLimit(client.ID); err != nil {
+		return models.ChatMessageResp{}, err
+	}
+
+	snowflake := utils.Snowflake{}
+
+	message := models.ChatMessage{
+		Id:      snowflake.NextVal(),
+		Channel: sendReq.Channel,
+		UserId:  userId,
+		Content: Chat.filter(sendReq.Content),
+		Status:  1,
+	}
+
+	if err = database.GetMySQL().Create(&message).Error; err != nil {
+		return models.ChatMessageResp{}, errors.New("发送失败")
+	}
+
+	userInfo, err := User.GetUserById(userId)
+	if err != nil {
+		return models.ChatMessageResp{}, err
+	}
+
+	return models.ChatMessageResp{
+		Id:        strconv.FormatInt(message.Id, 10),
+		Channel:   message.Channel,
+		UserId:    client.BindUserId,
+		Content:   message.Content,
+		Status:    message.Status,
+		CreatedAt: message.CreatedAt,
+		UserInfo:  userInfo,
+	}, nil
+}
+
+// History 频道的历史消息, 按时间倒序, 竞速房间频道只有房间中的选手可以查看
+func (ChatImpl) History(messageReq *models.ChatMessageReq) (models.ChatMessageListResp, error) {
+	var messageListResp models.ChatMessageListResp
+
+	if err := Chat.checkChannel(messageReq.Channel); err != nil {
+		return messageListResp, err
+	}
+
+	if id, ok := strings.CutPrefix(messageReq.Channel, "race:"); ok {
+		room, err := RaceRoom.get(id)
+		if err != nil {
+			return messageListResp, err
+		}
+
+		userId := strconv.FormatInt(messageReq.UserId, 10)
+		if !slices.ContainsFunc(room.Players, func(player models.RaceRoomPlayer) bool { return player.UserId == userId }) {
+			return messageListResp, errors.New("不在该房间中")
+		}
+	}
+
+	if messageReq.BeforeIdStr != "" {
+		messageReq.BeforeId, _ = strconv.ParseInt(messageReq.BeforeIdStr, 10, 64)
+	}
+
+	db := database.GetMySQL().Table("chat_message").
+		Where("channel = ? AND status = ?", messageReq.Channel, 1).
+		Order("id desc")
+
+	if messageReq.BeforeId != 0 {
+		db.Where("id < ?", messageReq.BeforeId)
+	}
+
+	err := db.Count(&messageListResp.Total).Error
+	if err != nil {
+		return messageListResp, errors.New("查询失败")
+	}
+
+	err = db.Scopes(utils.Paginate(&messageReq.Pagination)).Preload("UserInfo").Find(&messageListResp.Records).Error
+	if err != nil {
+		return messageListResp, errors.New("查询失败")
+	}
+
+	return messageListResp, nil
+}
+
+// List 聊天消息列表, 管理员使用
+func (ChatImpl) List(messageReq *models.ChatMessageReq) (models.ChatMessageListResp, error) {
+	var messageListResp models.ChatMessageListResp
+
+	if messageReq.IdStr != "" {
+		messageReq.Id, _ = strconv.ParseInt(messageReq.IdStr, 10, 64)
+	}
+
+	if messageReq.UserIdStr != "" {
+		messageReq.UserId, _ = strconv.ParseInt(messageReq.UserIdStr, 10, 64)
+	}
+
+	db := database.GetMySQL().Table("chat_message").Order("id desc")
+
+	if messageReq.Id != 0 {
+		db.Where("id = ?", messageReq.Id)
+	}
+
+	if messageReq.UserId != 0 {
+		db.Where("user_id = ?", messageReq.UserId)
+	}
+
+	if messageReq.Channel != "" {
+		db.Where("channel = ?", messageReq.Channel)
+	}
+
+	if messageReq.Content != "" {
+		db.Where("content Like ?", "%"+messageReq.Content+"%")
+	}
+
+	if messageReq.Status != 0 {
+		db.Where("status = ?", messageReq.Status)
+	}
+
+	if len(messageReq.DateRange) == 2 && !messageReq.DateRange[0].IsZero() && !messageReq.DateRange[1].IsZero() {
+		db.Where("created_at >= ? AND created_at <= ?", messageReq.DateRange[0], messageReq.DateRange[1])
+	}
+
+	err := db.Count(&messageListResp.Total).Error
+	if err != nil {
+		return messageListResp, errors.New("查询失败")
+	}
+
+	if messageReq.Pagination.Page > 0 && messageReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&messageReq.Pagination))
+	}
+
+	err = db.Preload("UserInfo").Find(&messageListResp.Records).Error
+	if err != nil {
+		return messageListResp, errors.New("查询失败")
+	}
+
+	return messageListResp, nil
+}
+
+// Update 更新聊天消息状态, 管理员用于删除或恢复消息
+func (ChatImpl) Update(messageReq *models.ChatMessageReq) error {
+	if messageReq.IdStr != "" {
+		messageReq.Id, _ = strconv.ParseInt(messageReq.IdStr, 10, 64)
+	}
+
+	if messageReq.Id == 0 {
+		return errors.New("消息ID不能为空")
+	}
+
+	if messageReq.Status != 1 && messageReq.Status != 2 {
+		return errors.New("状态不合法")
+	}
+
+	result := database.GetMySQL().Table("chat_message").Where("id = ?", messageReq.Id).Update("status", messageReq.Status)
+	if result.Error != nil {
+		return errors.New("更新失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("消息不存在")
+	}
+
+	return nil
+}
+
+// Restrict 禁言或封禁用户, 管理员使用, 封禁的用户不能加入大厅也不能发送消息
+func (ChatImpl) Restrict(restrictionReq *models.ChatRestrictionReq) error {
+	if restrictionReq.UserIdStr != "" {
+		restrictionReq.UserId, _ = strconv.ParseInt(restrictionReq.UserIdStr, 10, 64)
+	}
+
+	if restrictionReq.UserId == 0 {
+		return errors.New("用户ID不能为空")
+	}
+
+	if restrictionReq.Type != 1 && restrictionReq.Type != 2 {
+		return errors.New("类型不合法")
+	}
+
+	if restrictionReq.Duration < 0 {
+		return errors.New("时长不合法")
+	}
+
+	restrictionReq.Reason = strings.TrimSpace(restrictionReq.Reason)
+	if len([]rune(restrictionReq.Reason)) > 200 {
+		return errors.New("原因不能超过200字")
+	}
+
+	if _, err := User.GetUserById(restrictionReq.UserId); err != nil {
+		return err
+	}
+
+	snowflake := utils.Snowflake{}
+
+	restriction := models.ChatRestriction{
+		Id:     snowflake.NextVal(),
+		UserId: restrictionReq.UserId,
+		Type:   restrictionReq.Type,
+		Reason: restrictionReq.Reason,
+		Status: 1,
+	}
+
+	if restrictionReq.Duration > 0 {
+		expiredAt := time.Now().Add(time.Duration(restrictionReq.Duration) * time.Second)
+		restriction.ExpiredAt = &expiredAt
+	}
+
+	if err := database.GetMySQL().Create(&restriction).Error; err != nil {
+		return errors.New("新增聊天限制失败")
+	}
+
+	return nil
+}
+
+// ListRestriction 聊天限制列表, 管理员使用
+func (ChatImpl) ListRestriction(restrictionReq *models.ChatRestrictionReq) (models.ChatRestrictionListResp, error) {
+	var restrictionListResp models.ChatRestrictionListResp
+
+	if restrictionReq.UserIdStr != "" {
+		restrictionReq.UserId, _ = strconv.ParseInt(restrictionReq.UserIdStr, 10, 64)
+	}
+
+	db := database.GetMySQL().Table("chat_restriction").Order("id desc")
+
+	if restrictionReq.UserId != 0 {
+		db.Where("user_id = ?", restrictionReq.UserId)
+	}
+
+	if restrictionReq.Type != 0 {
+		db.Where("type = ?", restrictionReq.Type)
+	}
+
+	if restrictionReq.Status != 0 {
+		db.Where("status = ?", restrictionReq.Status)
+	}
+
+	err := db.Count(&restrictionListResp.Total).Error
+	if err != nil {
+		return restrictionListResp, errors.New("查询失败")
+	}
+
+	if restrictionReq.Pagination.Page > 0 && restrictionReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&restrictionReq.Pagination))
+	}
+
+	err = db.Preload("UserInfo").Find(&restrictionListResp.Records).Error
+	if err != nil {
+		return restrictionListResp, errors.New("查询失败")
+	}
+
+	return restrictionListResp, nil
+}
+
+// UpdateRestriction 解除聊天限制, 管理员使用
+func (ChatImpl) UpdateRestriction(restrictionReq *models.ChatRestrictionReq) error {
+	if restrictionReq.IdStr != "" {
+		restrictionReq.Id, _ = strconv.ParseInt(restrictionReq.IdStr, 10, 64)
+	}
+
+	if restrictionReq.Id == 0 {
+		return errors.New("聊天限制ID不能为空")
+	}
+
+	result := database.GetMySQL().Table("chat_restriction").
+		Where("id = ? AND status = ?", restrictionReq.Id, 1).
+		Update("status", 2)
+	if result.Error != nil {
+		return errors.New("更新失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("聊天限制不存在或已解除")
+	}
+
+	return nil
+}
+
+// checkChannel 校验频道格式
+func (ChatImpl) checkChannel(channel string) error {
+	if channel == chatLobbyChannel {
+		return nil
+	}
+
+	for _, prefix := range []string{"race:", "live:"} {
+		if id, ok := strings.CutPrefix(channel, prefix); ok {
+			if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("频道不存在")
+}
+
+// checkRestriction 获取用户生效中的聊天限制, 同时存在时优先返回封禁
+func (ChatImpl) checkRestriction(userId int64) (*models.ChatRestriction, error) {
+	var restrictions []models.ChatRestriction
+	err := database.GetMySQL().Table("chat_restriction").
+		Where("user_id = ? AND status = ? AND (expired_at IS NULL OR expired_at > ?)", userId, 1, time.Now()).
+		Order("type desc").
+		Limit(1).
+		Find(&restrictions).Error
+	if err != nil {
+		return nil, errors.New("查询聊天限制失败")
+	}
+
+	if len(restrictions) == 0 {
+		return nil, nil
+	}
+
+	return &restrictions[0], nil
+}
+
+// checkRateLimit 检查客户端发送频率
+func (ChatImpl) checkRateLimit(clientId string) error {
+	limit := config.Settings.Chat.RateLimit
+	if limit == 0 {
+		limit = defaultChatRateLimit
+	}
+
+	window := config.Settings.Chat.RateWindow
+	if window == 0 {
+		window = defaultChatRateWindow
+	}
+
+	key := "chat:limit:" + clientId
+
+	count, err := incrRateLimit(key, time.Duration(window)*time.Second)
+	if err != nil {
+		return errors.New("发送频率校验失败")
+	}
+
+	if count > int64(limit) {
+		return ErrChatRateLimited
+	}
+
+	return nil
+}
+
+// filter 将消息中的敏感词替换为 *, 不区分大小写
+func (ChatImpl) filter(content string) string {
+	runes := []rune(content)
+	lower := []rune(strings.Map(unicode.ToLower, content))
+
+	for _, word := range config.Settings.Chat.SensitiveWords {
+		target := []rune(strings.Map(unicode.ToLower, word))
+		if len(target) == 0 {
+			continue
+		}
+
+		for i := 0; i+len(target) <= len(lower); i++ {
+			if !slices.Equal(lower[i:i+len(target)], target) {
+				continue
+			}
+
+			for j := i; j < i+len(target); j++ {
+				runes[j] = '*'
+			}
+			i += len(target) - 1
+		}
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"puzzle/app/middlewares/websocket"
+	"testing"
+)
+
+func TestChatJoinRequiresLogin(t *testing.T) {
+	client := &websocket.Client{ID: "test-client"}
+	ctx := &websocket.Context{
+		Client:   client,
+		Envelope: &websocket.Envelope{Payload: json.RawMessage(`{"channel":"lobby"}`)},
+	}
+
+	err := Chat.Join(ctx)
+
+	var wsErr *websocket.Error
+	if !errors.As(err, &wsErr) || wsErr.Code != websocket.ErrCodeUnauthorized {
+		t.Fatalf("Join() error = %v, want unauthorized", err)
+	}
+	if len(client.JoinGroup) != 0 {
+		t.Errorf("Join() groups = %v, want none", client.JoinGroup)
+	}
+}
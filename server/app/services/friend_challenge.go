@@ -0,0 +1,568 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const friendChallengeNotificationTypeId = 4 // 挑战通知类型ID
+
+const (
+	defaultFriendChallengeHours = 24              // 默认截止时长(小时)
+	maxFriendChallengeHours     = 168             // 最长截止时长(小时)
+	friendChallengeSettlePeriod = 1 * time.Minute // 结算过期挑战的间隔
+)
+
+type FriendChallengeService interface {
+	Create(createReq *models.FriendChallengeCreateReq) (models.FriendChallengeResp, error)
+	Decline(challengeReq *models.FriendChallengeReq) error
+	Cancel(challengeReq *models.FriendChallengeReq) error
+	Submit(submitReq *models.FriendChallengeSubmitReq) (models.FriendChallengeResp, error)
+	List(challengeReq *models.FriendChallengeReq) (models.FriendChallengeListResp, error)
+	Get(challengeReq *models.FriendChallengeReq) (models.FriendChallengeResp, error)
+	SettleExpired(userId int64) error
+	Watch()
+	get(id int64) (models.FriendChallenge, error)
+	settle(challenge *models.FriendChallenge) error
+	hide(challengeResp *models.FriendChallengeResp, userId int64)
+	notify(userId int64, content string)
+}
+
+type FriendChallengeImpl struct{}
+
+// Create 向其他用户发起挑战, 双方使用同一个打乱
+func (FriendChallengeImpl) Create(createReq *models.FriendChallengeCreateReq) (models.FriendChallengeResp, error) {
+	if createReq.OpponentIdStr != "" {
+		createReq.OpponentId, _ = strconv.ParseInt(createReq.OpponentIdStr, 10, 64)
+	}
+
+	if createReq.RecordIdStr != "" {
+		createReq.RecordId, _ = strconv.ParseInt(createReq.RecordIdStr, 10, 64)
+	}
+
+	if createReq.OpponentId == 0 {
+		return models.FriendChallengeResp{}, errors.New("被挑战者不能为空")
+	}
+
+	if createReq.OpponentId == createReq.UserId {
+		return models.FriendChallengeResp{}, errors.New("不能挑战自己")
+	}
+
+	challenger, err := User.GetUserById(createReq.UserId)
+	if err != nil {
+		return models.FriendChallengeResp{}, err
+	}
+
+	if _, err = User.GetUserById(createReq.OpponentId); err != nil {
+		return models.FriendChallengeResp{}, errors.New("被挑战者不存在")
+	}
+
+	if createReq.DeadlineHours == 0 {
+		createReq.DeadlineHours = defaultFriendChallengeHours
+	}
+
+	if createReq.DeadlineHours < 0 || createReq.DeadlineHours > maxFriendChallengeHours {
+		return models.FriendChallengeResp{}, fmt.Errorf("截止时长需要在1到%d小时之间", maxFriendChallengeHours)
+	}
+
+	createReq.Message = strings.TrimSpace(createReq.Message)
+	if len([]rune(createReq.Message)) > 100 {
+		return models.FriendChallengeResp{}, errors.New("留言不能超过100字")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	challenge := models.FriendChallenge{
+		Id:               snowflake.NextVal(),
+		ChallengerId:     createReq.UserId,
+		OpponentId:       createReq.OpponentId,
+		Message:          createReq.Message,
+		Deadline:         time.Now().Add(time.Duration(createReq.DeadlineHours) * time.Hour),
+		ChallengerStatus: 1,
+		OpponentStatus:   1,
+		Status:           1,
+	}
+
+	// 指定记录时使用该记录的打乱, 否则下发新的打乱
+	if createReq.RecordId != 0 {
+		record, err := RecordChallenge.getRecord(createReq.RecordId, createReq.UserId)
+		if err != nil {
+			return models.FriendChallengeResp{}, err
+		}
+
+		challenge.Dimension = record.Dimension
+		challenge.Width = record.Width
+		challenge.Height = record.Height
+		challenge.Idx = record.Idx
+		challenge.Scramble = record.Scramble
+		challenge.ScrambleVersion = record.ScrambleVersion
+
+		// 双方都不能已经复原过该打乱, 否则已知解法的一方可以直接复现成绩
+		var count int64
+		err = database.GetMySQL().Table("record").
+			Where("user_id IN ? AND width = ? AND height = ? AND idx = ? AND scramble = ?",
+				[]int64{challenge.ChallengerId, challenge.OpponentId},
+				challenge.Width, challenge.Height, challenge.Idx, challenge.Scramble).
+			Count(&count).Error
+		if err != nil {
+			return models.FriendChallengeResp{}, errors.New("查询记录失败")
+		}
+
+		if count > 0 {
+			return models.FriendChallengeResp{}, errors.New("双方已有人复原过该打乱")
+		}
+
+		// 同一用户同一打乱只能有一条好友挑战记录
+		err = database.GetMySQL().Table("friend_challenge").
+			Where("idx = ? AND status IN ?", challenge.Idx, []int{1, 2}).
+			Where("challenger_id IN ? OR opponent_id IN ?",
+				[]int64{challenge.ChallengerId, challenge.OpponentId},
+				[]int64{challenge.ChallengerId, challenge.OpponentId}).
+			Count(&count).Error
+		if err != nil {
+			return models.FriendChallengeResp{}, errors.New("查询好友挑战失败")
+		}
+
+		if count > 0 {
+			return models.FriendChallengeResp{}, errors.New("双方已有人挑战过该打乱")
+		}
+	} else {
+		createReq.Dimension, createReq.Width, createReq.Height = utils.NormalizeSize(createReq.Dimension, createReq.Width, createReq.Height)
+
+		if createReq.Width < minScrambleSize || createReq.Width > maxScrambleSize ||
+			createReq.Height < minScrambleSize || createReq.Height > maxScrambleSize {
+			return models.FriendChallengeResp{}, errors.New("尺寸不合法")
+		}
+
+		idx, scramble, _ := Scramble.generate(createReq.Width, createReq.Height)
+
+		challenge.Dimension = createReq.Dimension
+		challenge.Width = createReq.Width
+		challenge.Height = createReq.Height
+		challenge.Idx = idx
+		challenge.Scramble = puzzle.FormatScramble(scramble)
+		challenge.ScrambleVersion = puzzle.CurrentVersion
+	}
+
+	if err = database.GetMySQL().Create(&challenge).Error; err != nil {
+		return models.FriendChallengeResp{}, errors.New("发起挑战失败")
+	}
+
+	FriendChallenge.notify(challenge.OpponentId, fmt.Sprintf("%s向你发起了%d×%d的挑战, 请在%s前完成",
+		challenger.Nickname, challenge.Width, challenge.Height, challenge.Deadline.Format("2006-01-02 15:04")))
+
+	return FriendChallenge.Get(&models.FriendChallengeReq{Id: challenge.Id, UserId: createReq.UserId})
+}
+
+// Decline 被挑战者在提交成绩前拒绝挑战
+func (FriendChallengeImpl) Decline(challengeReq *models.FriendChallengeReq) error {
+	if challengeReq.IdStr != "" {
+		challengeReq.Id, _ = strconv.ParseInt(challengeReq.IdStr, 10, 64)
+	}
+
+	challenge, err := FriendChallenge.get(challengeReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if challenge.OpponentId != challengeReq.UserId {
+		return errors.New("只有被挑战者可以拒绝挑战")
+	}
+
+	result := database.GetMySQL().Table("friend_challenge").
+		Where("id = ? AND status = ? AND opponent_status = ?", challenge.Id, 1, 1).
+		Update("status", 3)
+	if result.Error != nil {
+		return errors.New("拒绝挑战失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("挑战已结束或已提交成绩")
+	}
+
+	opponent, err := User.GetUserById(challenge.OpponentId)
+	if err == nil {
+		FriendChallenge.notify(challenge.ChallengerId, fmt.Sprintf("%s拒绝了你的挑战", opponent.Nickname))
+	}
+
+	return nil
+}
+
+// Cancel 发起者在被挑战者提交成绩前取消挑战
+func (FriendChallengeImpl) Cancel(challengeReq *models.FriendChallengeReq) error {
+	if challengeReq.IdStr != "" {
+		challengeReq.Id, _ = strconv.ParseInt(challengeReq.IdStr, 10, 64)
+	}
+
+	challenge, err := FriendChallenge.get(challengeReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if challenge.ChallengerId != challengeReq.UserId {
+		return errors.New("只有发起者可以取消挑战")
+	}
+
+	result := database.GetMySQL().Table("friend_challenge").
+		Where("id = ? AND status = ? AND opponent_status = ?", challenge.Id, 1, 1).
+		Update("status", 4)
+	if result.Error != nil {
+		return errors.New("取消挑战失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("挑战已结束或对方已提交成绩")
+	}
+
+	return nil
+}
+
+// Submit 提交成绩, 每人只能提交一次, 双方都提交后立即结算
+func (FriendChallengeImpl) Submit(submitReq *models.FriendChallengeSubmitReq) (models.FriendChallengeResp, error) {
+	if submitReq.IdStr != "" {
+		submitReq.Id, _ = strconv.ParseInt(submitReq.IdStr, 10, 64)
+	}
+
+	challenge, err := FriendChallenge.get(submitReq.Id)
+	if err != nil {
+		return models.FriendChallengeResp{}, err
+	}
+
+	var prefix string
+	switch submitReq.UserId {
+	case challenge.ChallengerId:
+		prefix = "challenger_"
+	case challenge.OpponentId:
+		prefix = "opponent_"
+	default:
+		return models.FriendChallengeResp{}, errors.New("不是该挑战的参与者")
+	}
+
+	if challenge.Status != 1 {
+		return models.FriendChallengeResp{}, errors.New("挑战已结束")
+	}
+
+	// 超过截止时间的挑战直接结算
+	if time.Now().After(challenge.Deadline) {
+		if err = FriendChallenge.settle(&challenge); err != nil {
+			return models.FriendChallengeResp{}, err
+		}
+
+		return models.FriendChallengeResp{}, errors.New("挑战已截止")
+	}
+
+	switch submitReq.Status {
+	case 2:
+		if submitReq.Duration <= 0 || submitReq.Step <= 0 || submitReq.Solution == "" {
+			return models.FriendChallengeResp{}, errors.New("成绩不完整")
+		}
+
+		encryptionParams := utils.EncryptionParams{
+			Dimension: challenge.Dimension,
+			Width:     challenge.Width,
+			Height:    challenge.Height,
+			RandomIdx: challenge.Idx,
+			StepCount: submitReq.Step,
+			Scramble:  challenge.Scramble,
+			Solution:  submitReq.Solution,
+			Version:   challenge.ScrambleVersion,
+		}
+
+		if !encryptionParams.VerifyScramble() {
+			return models.FriendChallengeResp{}, ErrRecordVerify
+		}
+	case 3:
+		submitReq.Duration, submitReq.Step, submitReq.Solution = 0, 0, ""
+	default:
+		return models.FriendChallengeResp{}, errors.New("状态不合法")
+	}
+
+	// 只更新未提交的成绩, 避免重复提交
+	result := database.GetMySQL().Table("friend_challenge").
+		Where("id = ? AND status = ? AND "+prefix+"status = ?", challenge.Id, 1, 1).
+		Updates(map[string]any{
+			prefix + "duration": submitReq.Duration,
+			prefix + "step":     submitReq.Step,
+			prefix + "solution": submitReq.Solution,
+			prefix + "status":   submitReq.Status,
+		})
+	if result.Error != nil {
+		return models.FriendChallengeResp{}, errors.New("提交失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return models.FriendChallengeResp{}, errors.New("已提交过成绩, 请勿重复提交")
+	}
+
+	challenge, err = FriendChallenge.get(challenge.Id)
+	if err != nil {
+		return models.FriendChallengeResp{}, err
+	}
+
+	if challenge.ChallengerStatus != 1 && challenge.OpponentStatus != 1 {
+		if err = FriendChallenge.settle(&challenge); err != nil {
+			return models.FriendChallengeResp{}, err
+		}
+	}
+
+	return FriendChallenge.Get(&models.FriendChallengeReq{Id: challenge.Id, UserId: submitReq.UserId})
+}
+
+// List 当前用户发起或收到的挑战列表
+func (FriendChallengeImpl) List(challengeReq *models.FriendChallengeReq) (models.FriendChallengeListResp, error) {
+	var challengeListResp models.FriendChallengeListResp
+
+	// 先结算已过期的挑战, 保证列表中的状态与结果是最新的
+	if err := FriendChallenge.SettleExpired(challengeReq.UserId); err != nil {
+		return challengeListResp, err
+	}
+
+	db := database.GetMySQL().Table("friend_challenge").Order("id " + utils.SortDirection(challengeReq.Sorted, "desc"))
+
+	switch challengeReq.Role {
+	case 1:
+		db.Where("challenger_id = ?", challengeReq.UserId)
+	case 2:
+		db.Where("opponent_id = ?", challengeReq.UserId)
+	default:
+		db.Where("challenger_id = ? OR opponent_id = ?", challengeReq.UserId, challengeReq.UserId)
+	}
+
+	if challengeReq.Status != 0 {
+		db.Where("status = ?", challengeReq.Status)
+	}
+
+	// 查询总数
+	err := db.Count(&challengeListResp.Total).Error
+	if err != nil {
+		return challengeListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if challengeReq.Pagination.Page > 0 && challengeReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&challengeReq.Pagination))
+	}
+
+	err = db.Preload("ChallengerInfo").Preload("OpponentInfo").Find(&challengeListResp.Records).Error
+	if err != nil {
+		return challengeListResp, errors.New("查询失败")
+	}
+
+	for i := range challengeListResp.Records {
+		FriendChallenge.hide(&challengeListResp.Records[i], challengeReq.UserId)
+	}
+
+	return challengeListResp, nil
+}
+
+// Get 挑战详情, 仅参与者可以查看
+func (FriendChallengeImpl) Get(challengeReq *models.FriendChallengeReq) (models.FriendChallengeResp, error) {
+	var challengeResp models.FriendChallengeResp
+
+	if challengeReq.IdStr != "" {
+		challengeReq.Id, _ = strconv.ParseInt(challengeReq.IdStr, 10, 64)
+	}
+
+	challenge, err := FriendChallenge.get(challengeReq.Id)
+	if err != nil {
+		return challengeResp, err
+	}
+
+	if challenge.ChallengerId != challengeReq.UserId && challenge.OpponentId != challengeReq.UserId {
+		return challengeResp, errors.New("挑战不存在")
+	}
+
+	if challenge.Status == 1 && time.Now().After(challenge.Deadline) {
+		if err = FriendChallenge.settle(&challenge); err != nil {
+			return challengeResp, err
+		}
+	}
+
+	err = database.GetMySQL().Table("friend_challenge").
+		Where("id = ?", challenge.Id).
+		Preload("ChallengerInfo").
+		Preload("OpponentInfo").
+		First(&challengeResp).Error
+	if err != nil {
+		return challengeResp, errors.New("挑战不存在")
+	}
+
+	FriendChallenge.hide(&challengeResp, challengeReq.UserId)
+
+	return challengeResp, nil
+}
+
+// SettleExpired 结算已超过截止时间的挑战, userId 为0时结算所有用户的挑战
+func (FriendChallengeImpl) SettleExpired(userId int64) error {
+	var challenges []models.FriendChallenge
+
+	db := database.GetMySQL().Table("friend_challenge").
+		Where("status = ? AND deadline <= ?", 1, time.Now())
+
+	if userId != 0 {
+		db.Where("challenger_id = ? OR opponent_id = ?", userId, userId)
+	}
+
+	if err := db.Find(&challenges).Error; err != nil {
+		return errors.New("查询过期挑战失败")
+	}
+
+	for i := range challenges {
+		if err := FriendChallenge.settle(&challenges[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Watch 定时结算过期的挑战, 使双方在不在线时也能收到结果通知
+func (FriendChallengeImpl) Watch() {
+	ticker := time.NewTicker(friendChallengeSettlePeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := FriendChallenge.SettleExpired(0); err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+}
+
+// get 获取挑战
+func (FriendChallengeImpl) get(id int64) (models.FriendChallenge, error) {
+	var challenge models.FriendChallenge
+	err := database.GetMySQL().Table("friend_challenge").Where("id = ?", id).First(&challenge).Error
+	if err != nil {
+		return challenge, errors.New("挑战不存在")
+	}
+
+	return challenge, nil
+}
+
+// settle 结算挑战并通知双方, 完成的一方胜过未完成或放弃的一方
+// 双方都完成时耗时少者获胜, 耗时相同时步数少者获胜
+// 完成的成绩作为好友挑战记录保存, 不计入最佳记录
+func (FriendChallengeImpl) settle(challenge *models.FriendChallenge) error {
+	winnerId := int64(0)
+	switch {
+	case challenge.ChallengerStatus == 2 && challenge.OpponentStatus != 2:
+		winnerId = challenge.ChallengerId
+	case challenge.OpponentStatus == 2 && challenge.ChallengerStatus != 2:
+		winnerId = challenge.OpponentId
+	case challenge.ChallengerStatus == 2 && challenge.OpponentStatus == 2:
+		switch {
+		case challenge.ChallengerDuration < challenge.OpponentDuration:
+			winnerId = challenge.ChallengerId
+		case challenge.OpponentDuration < challenge.ChallengerDuration:
+			winnerId = challenge.OpponentId
+		case challenge.ChallengerStep < challenge.OpponentStep:
+			winnerId = challenge.ChallengerId
+		case challenge.OpponentStep < challenge.ChallengerStep:
+			winnerId = challenge.OpponentId
+		}
+	}
+
+	// 只结算进行中的挑战, 避免同时提交或多个节点定时结算时重复结算
+	result := database.GetMySQL().Table("friend_challenge").
+		Where("id = ? AND status = ?", challenge.Id, 1).
+		Updates(map[string]any{"winner_id": winnerId, "status": 2})
+	if result.Error != nil {
+		return errors.New("结算挑战失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	challenge.WinnerId = winnerId
+	challenge.Status = 2
+
+	players := []struct {
+		userId     int64
+		opponentId int64
+		duration   int
+		step       int
+		solution   string
+		status     int
+	}{
+		{challenge.ChallengerId, challenge.OpponentId, challenge.ChallengerDuration, challenge.ChallengerStep, challenge.ChallengerSolution, challenge.ChallengerStatus},
+		{challenge.OpponentId, challenge.ChallengerId, challenge.OpponentDuration, challenge.OpponentStep, challenge.OpponentSolution, challenge.OpponentStatus},
+	}
+
+	for _, player := range players {
+		// 保存记录失败不影响结算
+		if player.status == 2 {
+			err := Record.Insert(&models.Record{
+				UserId:    player.userId,
+				Dimension: challenge.Dimension,
+				Width:     challenge.Width,
+				Height:    challenge.Height,
+				Type:      7,
+				Duration:  player.duration,
+				Step:      player.step,
+				Scramble:  challenge.Scramble,
+				Solution:  player.solution,
+				Idx:       challenge.Idx,
+			})
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		}
+
+		nickname := ""
+		if opponent, err := User.GetUserById(player.opponentId); err == nil {
+			nickname = opponent.Nickname
+		}
+
+		switch winnerId {
+		case 0:
+			FriendChallenge.notify(player.userId, fmt.Sprintf("你与%s的挑战已结束, 双方打平", nickname))
+		case player.userId:
+			FriendChallenge.notify(player.userId, fmt.Sprintf("你赢得了与%s的挑战", nickname))
+		default:
+			FriendChallenge.notify(player.userId, fmt.Sprintf("你在与%s的挑战中落败", nickname))
+		}
+	}
+
+	return nil
+}
+
+// hide 结算前隐藏对方的成绩, 只保留是否已提交
+func (FriendChallengeImpl) hide(challengeResp *models.FriendChallengeResp, userId int64) {
+	if challengeResp.Status != 1 {
+		return
+	}
+
+	userIdStr := strconv.FormatInt(userId, 10)
+
+	if challengeResp.ChallengerId != userIdStr {
+		challengeResp.ChallengerDuration = 0
+		challengeResp.ChallengerStep = 0
+		challengeResp.ChallengerSolution = ""
+	}
+
+	if challengeResp.OpponentId != userIdStr {
+		challengeResp.OpponentDuration = 0
+		challengeResp.OpponentStep = 0
+		challengeResp.OpponentSolution = ""
+	}
+}
+
+// notify 通过通知服务通知用户
+func (FriendChallengeImpl) notify(userId int64, content string) {
+	err := Notification.Insert(&models.NotificationReq{
+		UserId:  userId,
+		TypeId:  friendChallengeNotificationTypeId,
+		Content: content,
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
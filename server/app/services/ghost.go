@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"puzzle/app/middlewares/websocket"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ghostStreams 正在推送的幽灵, 键为客户端ID, 值为停止推送的通道
+var ghostStreams sync.Map
+
+type GhostService interface {
+	Get(ghostReq *models.GhostReq) (models.GhostResp, error)
+	Stream(ctx *websocket.Context) error
+	Stop(ctx *websocket.Context) error
+	parseMoveTimes(moveTimes string, step, duration int) ([]int, error)
+}
+
+type GhostImpl struct{}
+
+// Get 获取幽灵, 包括记录的打乱与每一步的时间, 没有回放时间的记录按总耗时平均分配每一步
+func (GhostImpl) Get(ghostReq *models.GhostReq) (models.GhostResp, error) {
+	var ghostResp models.GhostResp
+
+	if ghostReq.RecordIdStr != "" {
+		ghostReq.RecordId, _ = strconv.ParseInt(ghostReq.RecordIdStr, 10, 64)
+	}
+
+	ghostReq.Dimension, ghostReq.Width, ghostReq.Height = utils.NormalizeSize(ghostReq.Dimension, ghostReq.Width, ghostReq.Height)
+
+	// 未指定记录时使用最佳单次记录
+	if ghostReq.RecordId == 0 {
+		if ghostReq.Width == 0 || ghostReq.Height == 0 {
+			return ghostResp, errors.New("尺寸不能为空")
+		}
+
+		recordBestSingle, err := RecordBestSingle.List(&models.RecordBestSingleReq{
+			UserId:    ghostReq.UserId,
+			Dimension: ghostReq.Dimension,
+			Width:     ghostReq.Width,
+			Height:    ghostReq.Height,
+			Pagination: utils.Pagination{
+				Page:     1,
+				PageSize: 1,
+			},
+		})
+		if err != nil {
+			return ghostResp, errors.New("获取最佳单次记录失败")
+		}
+
+		if recordBestSingle.Total == 0 {
+			return ghostResp, errors.New("该尺寸还没有最佳单次记录")
+		}
+
+		ghostReq.RecordId, _ = strconv.ParseInt(recordBestSingle.Records[0].RecordId, 10, 64)
+	}
+
+	var record models.Record
+	err := database.GetMySQL().Table("record").Where("id = ? AND status = ?", ghostReq.RecordId, 1).First(&record).Error
+	if err != nil {
+		return ghostResp, errors.New("记录不存在")
+	}
+
+	if err = ScrambleSet.checkVisible(&record, ghostReq.UserId); err != nil {
+		return ghostResp, err
+	}
+
+	if ghostReq.Width != 0 && (record.Width != ghostReq.Width || record.Height != ghostReq.Height) {
+		return ghostResp, errors.New("记录的尺寸不一致")
+	}
+
+	board, err := puzzle.ParseScramble(record.Width, record.Height, record.Scramble)
+	if err != nil {
+		return ghostResp, errors.New("记录的打乱有误")
+	}
+
+	tiles, err := board.DecodeSolution(record.Solution)
+	if err != nil || len(tiles) == 0 {
+		return ghostResp, errors.New("记录的解法有误")
+	}
+
+	times, err := Ghost.parseMoveTimes(record.MoveTimes, len(tiles), record.Duration)
+	if err != nil {
+		times = nil
+	}
+
+	// 没有回放时间, 按总耗时平均分配
+	if times == nil {
+		times = make([]int, len(tiles))
+		for i := range tiles {
+			times[i] = record.Duration * (i + 1) / len(tiles)
+		}
+	}
+
+	userInfo, err := User.GetUserById(record.UserId)
+	if err != nil {
+		return ghostResp, errors.New("查询用户信息失败")
+	}
+
+	ghostResp = models.GhostResp{
+		RecordId:  strconv.FormatInt(record.Id, 10),
+		UserInfo:  userInfo,
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+		Scramble:  record.Scramble,
+		Duration:  record.Duration,
+		Step:      record.Step,
+		Moves:     make([]models.GhostMove, len(tiles)),
+	}
+
+	for i, tile := range tiles {
+		ghostResp.Moves[i] = models.GhostMove{
+			Seq:  i + 1,
+			Tile: tile,
+			Time: times[i],
+		}
+	}
+
+	return ghostResp, nil
+}
+
+// Stream 按记录的时间实时推送幽灵的每一步, 消息内容为 GhostReq
+// 回复 ghost-start(不含每一步的幽灵信息)后依次推送 ghost-move(每一步)、ghost-end
+func (GhostImpl) Stream(ctx *websocket.Context) error {
+	client := ctx.Client
+	if client.BindUserId == "" {
+		return websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	var ghostReq models.GhostReq
+	if err := ctx.Bind(&ghostReq); err != nil {
+		return err
+	}
+
+	ghostReq.UserId, _ = strconv.ParseInt(client.BindUserId, 10, 64)
+
+	ghost, err := Ghost.Get(&ghostReq)
+	if err != nil {
+		return err
+	}
+
+	// 同一客户端同时只推送一个幽灵
+	stop := make(chan struct{})
+	if previous, ok := ghostStreams.Swap(client.ID, stop); ok {
+		close(previous.(chan struct{}))
+	}
+
+	moves := ghost.Moves
+	ghost.Moves = nil
+
+	go func() {
+		defer ghostStreams.CompareAndDelete(client.ID, stop)
+
+		if !ctx.Reply("ghost-start", ghost) {
+			return
+		}
+
+		startedAt := time.Now()
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for _, move := range moves {
+			timer.Reset(time.Until(startedAt.Add(time.Duration(move.Time) * time.Millisecond)))
+
+			select {
+			case <-stop:
+				return
+			case <-client.Done:
+				return
+			case <-timer.C:
+			}
+
+			if !client.SendJSON("ghost-move", move) {
+				return
+			}
+		}
+
+		client.SendJSON("ghost-end", map[string]any{
+			"recordId": ghost.RecordId,
+			"duration": ghost.Duration,
+			"step":     ghost.Step,
+		})
+	}()
+
+	return nil
+}
+
+// Stop 停止推送幽灵
+func (GhostImpl) Stop(ctx *websocket.Context) error {
+	if stop, ok := ghostStreams.LoadAndDelete(ctx.Client.ID); ok {
+		close(stop.(chan struct{}))
+	}
+
+	return nil
+}
+
+// parseMoveTimes 解析每一步距开始的耗时, 数量需要与步数一致, 且不递减、不超过总耗时
+func (GhostImpl) parseMoveTimes(moveTimes string, step, duration int) ([]int, error) {
+	if moveTimes == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(moveTimes, ",")
+	if len(fields) != step {
+		return nil, errors.New("回放的步数与记录不一致")
+	}
+
+	times := make([]int, len(fields))
+	for i, field := range fields {
+		t, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || t < 0 || t > duration || (i > 0 && t < times[i-1]) {
+			return nil, errors.New("回放的时间有误")
+		}
+
+		times[i] = t
+	}
+
+	return times, nil
+}
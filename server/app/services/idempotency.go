@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"puzzle/app/common/result"
+	"puzzle/database"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyInvalid  = errors.New("幂等键不合法")
+	ErrIdempotencyKeyConflict = errors.New("幂等键已被其他请求内容使用")
+	ErrIdempotencyInProgress  = errors.New("相同的请求正在处理中, 请稍后重试")
+)
+
+// 幂等键保存时长
+var idempotencyExpiration = 24 * time.Hour
+
+// 幂等键最大长度
+const idempotencyKeyMaxLength = 64
+
+type IdempotencyService interface {
+	Acquire(scope string, userId int64, key string, fingerprint string) (*result.HttpResult, error)
+	Save(scope string, userId int64, key string, fingerprint string, resp result.HttpResult) error
+	Release(scope string, userId int64, key string)
+}
+
+type IdempotencyImpl struct{}
+
+// idempotencyEntry 幂等记录
+type idempotencyEntry struct {
+	Fingerprint string            `json:"fingerprint"` // 请求指纹
+	Status      int               `json:"status"`      // 状态 1:处理中 2:已完成
+	Response    result.HttpResult `json:"response"`    // 已保存的响应
+}
+
+// redisKey 生成幂等键在redis中的key
+func (IdempotencyImpl) redisKey(scope string, userId int64, key string) string {
+	return fmt.Sprintf("idempotency:%s:%d:%s", scope, userId, key)
+}
+
+// Acquire 占用幂等键, 若该键已完成则返回保存的响应
+func (IdempotencyImpl) Acquire(scope string, userId int64, key string, fingerprint string) (*result.HttpResult, error) {
+	if key == "" || len(key) > idempotencyKeyMaxLength {
+		return nil, ErrIdempotencyKeyInvalid
+	}
+
+	redisKey := Idempotency.redisKey(scope, userId, key)
+
+	pending, _ := json.Marshal(idempotencyEntry{
+		Fingerprint: fingerprint,
+		Status:      1,
+	})
+
+	// 尝试占用幂等键
+	ok, err := database.GetRedis().SetNX(context.Background(), redisKey, pending, idempotencyExpiration).Result()
+	if err != nil {
+		return nil, errors.New("幂等键存入redis失败")
+	}
+
+	if ok {
+		return nil, nil
+	}
+
+	// 幂等键已存在, 读取已保存的内容
+	value, err := database.GetRedis().Get(context.Background(), redisKey).Bytes()
+	if err != nil {
+		return nil, errors.New("获取幂等键失败")
+	}
+
+	var entry idempotencyEntry
+	if err = json.Unmarshal(value, &entry); err != nil {
+		return nil, errors.New("幂等键解析失败")
+	}
+
+	if entry.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyConflict
+	}
+
+	if entry.Status != 2 {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	return &entry.Response, nil
+}
+
+// Save 保存幂等键对应的响应
+func (IdempotencyImpl) Save(scope string, userId int64, key string, fingerprint string, resp result.HttpResult) error {
+	value, err := json.Marshal(idempotencyEntry{
+		Fingerprint: fingerprint,
+		Status:      2,
+		Response:    resp,
+	})
+	if err != nil {
+		return errors.New("幂等响应序列化失败")
+	}
+
+	err = database.GetRedis().Set(context.Background(), Idempotency.redisKey(scope, userId, key), value, idempotencyExpiration).Err()
+	if err != nil {
+		return errors.New("幂等响应存入redis失败")
+	}
+
+	return nil
+}
+
+// Release 释放幂等键, 用于请求未能完成时允许客户端重试
+func (IdempotencyImpl) Release(scope string, userId int64, key string) {
+	database.GetRedis().Del(context.Background(), Idempotency.redisKey(scope, userId, key))
+}
@@ -0,0 +1,600 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"puzzle/app/middlewares/websocket"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 直播对局保存时长, 选手每次推送时刷新, 选手断线后到期自动结束
+var liveExpiration = 10 * time.Minute
+
+// 对战最多选手数量, 包括发起者
+const maxLivePlayers = 8
+
+const (
+	liveGamesKey   = "live:games" // 直播中的对局ID集合
+	liveMaxRetries = 5            // 并发更新对局冲突时的最大重试次数
+)
+
+type LiveService interface {
+	Start(ctx *websocket.Context) error
+	Scramble(ctx *websocket.Context) error
+	Move(ctx *websocket.Context) error
+	Result(ctx *websocket.Context) error
+	End(ctx *websocket.Context) error
+	Accept(ctx *websocket.Context) error
+	Decline(ctx *websocket.Context) error
+	Join(ctx *websocket.Context) error
+	Leave(ctx *websocket.Context) error
+	List(listReq *models.LiveListReq) (models.LiveListResp, error)
+	Running(groupId string) bool
+	start(userId string, startReq *models.LiveStartReq) (models.LiveGame, error)
+	getGame(id string) (models.LiveGame, error)
+	getPlayerGame(client *websocket.Client, id string) (models.LiveGame, error)
+	saveGame(game *models.LiveGame) error
+	updateGame(id string, fn func(game *models.LiveGame) error) (models.LiveGame, error)
+	getResp(games []models.LiveGame) ([]models.LiveResp, error)
+	broadcastSpectators(id string)
+}
+
+type LiveImpl struct{}
+
+// gameKey 对局在redis中的key
+func (LiveImpl) gameKey(id string) string {
+	return "live:game:" + id
+}
+
+// groupId 对局观众的 WebSocket 群组
+func (LiveImpl) groupId(id string) string {
+	return "live:" + id
+}
+
+// Start 开始直播或对战, 消息内容为 LiveStartReq, 成功后向发起者推送 live-started
+// 对战的其他选手需要接受邀请才能加入, 向被邀请的用户推送 live-invited
+func (LiveImpl) Start(ctx *websocket.Context) error {
+	if ctx.Client.BindUserId == "" {
+		return websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	var startReq models.LiveStartReq
+	if err := ctx.Bind(&startReq); err != nil {
+		return err
+	}
+
+	game, err := Live.start(ctx.Client.BindUserId, &startReq)
+	if err != nil {
+		return err
+	}
+
+	message, _ := json.Marshal(game)
+	for _, playerId := range game.PlayerIds {
+		websocket.SendToUser(playerId, "live-started", string(message))
+	}
+
+	for _, inviteeId := range game.InviteeIds {
+		websocket.SendToUser(inviteeId, "live-invited", string(message))
+	}
+
+	return nil
+}
+
+// start 校验并保存对局
+func (LiveImpl) start(userId string, startReq *models.LiveStartReq) (models.LiveGame, error) {
+	playerIds := []string{userId}
+	inviteeIds := make([]string, 0)
+
+	switch startReq.Type {
+	case 1:
+		if len(startReq.PlayerIds) == 0 {
+			return models.LiveGame{}, errors.New("对战至少需要一名其他选手")
+		}
+
+		userIds := make([]int64, 0, len(startReq.PlayerIds))
+		for _, playerId := range startReq.PlayerIds {
+			if playerId == userId || slices.Contains(inviteeIds, playerId) {
+				return models.LiveGame{}, errors.New("选手不能重复")
+			}
+
+			id, err := strconv.ParseInt(playerId, 10, 64)
+			if err != nil {
+				return models.LiveGame{}, errors.New("选手ID有误")
+			}
+
+			inviteeIds = append(inviteeIds, playerId)
+			userIds = append(userIds, id)
+		}
+
+		if len(playerIds)+len(inviteeIds) > maxLivePlayers {
+			return models.LiveGame{}, errors.New("选手数量超过上限")
+		}
+
+		users, err := User.GetUserByIds(userIds)
+		if err != nil {
+			return models.LiveGame{}, errors.New("获取选手信息失败")
+		}
+
+		if len(users.Records) != len(userIds) {
+			return models.LiveGame{}, errors.New("选手不存在")
+		}
+	case 2:
+	default:
+		return models.LiveGame{}, errors.New("类型不合法")
+	}
+
+	startReq.Dimension, startReq.Width, startReq.Height = utils.NormalizeSize(startReq.Dimension, startReq.Width, startReq.Height)
+
+	if startReq.Width < minScrambleSize || startReq.Width > maxScrambleSize ||
+		startReq.Height < minScrambleSize || startReq.Height > maxScrambleSize {
+		return models.LiveGame{}, errors.New("尺寸不合法")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	game := models.LiveGame{
+		Id:         strconv.FormatInt(snowflake.NextVal(), 10),
+		Type:       startReq.Type,
+		HostId:     userId,
+		PlayerIds:  playerIds,
+		InviteeIds: inviteeIds,
+		Dimension:  startReq.Dimension,
+		Width:      startReq.Width,
+		Height:     startReq.Height,
+		StartedAt:  time.Now(),
+	}
+
+	if err := Live.saveGame(&game); err != nil {
+		return models.LiveGame{}, err
+	}
+
+	if err := database.GetRedis().SAdd(context.Background(), liveGamesKey, game.Id).Err(); err != nil {
+		return models.LiveGame{}, errors.New("对局存入redis失败")
+	}
+
+	return game, nil
+}
+
+// Scramble 选手开始新的打乱, 消息内容为 LiveScrambleReq, 向观众推送 live-scramble
+func (LiveImpl) Scramble(ctx *websocket.Context) error {
+	var scrambleReq models.LiveScrambleReq
+	if err := ctx.Bind(&scrambleReq); err != nil {
+		return err
+	}
+
+	game, err := Live.getPlayerGame(ctx.Client, scrambleReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if _, err = puzzle.ParseScramble(game.Width, game.Height, scrambleReq.Scramble); err != nil {
+		return errors.New("打乱有误")
+	}
+
+	game, err = Live.updateGame(game.Id, func(game *models.LiveGame) error {
+		game.Scramble = scrambleReq.Scramble
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	scrambleReq.UserId = ctx.Client.BindUserId
+	message, _ := json.Marshal(scrambleReq)
+	websocket.SendToGroup(Live.groupId(game.Id), "live-scramble", string(message))
+
+	return nil
+}
+
+// Move 选手的一步, 消息内容为 LiveMoveReq, 向观众推送 live-move
+func (LiveImpl) Move(ctx *websocket.Context) error {
+	var moveReq models.LiveMoveReq
+	if err := ctx.Bind(&moveReq); err != nil {
+		return err
+	}
+
+	game, err := Live.getPlayerGame(ctx.Client, moveReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if moveReq.Seq <= 0 || moveReq.Time < 0 || moveReq.Tile <= 0 || moveReq.Tile >= game.Width*game.Height {
+		return websocket.NewError(websocket.ErrCodeBadMessage, "参数错误")
+	}
+
+	// 刷新对局保存时长
+	database.GetRedis().Expire(context.Background(), Live.gameKey(game.Id), liveExpiration)
+
+	moveReq.UserId = ctx.Client.BindUserId
+	message, _ := json.Marshal(moveReq)
+	websocket.SendToGroup(Live.groupId(game.Id), "live-move", string(message))
+
+	return nil
+}
+
+// Result 选手的最终成绩, 消息内容为 LiveResultReq, 向观众推送 live-result
+func (LiveImpl) Result(ctx *websocket.Context) error {
+	var resultReq models.LiveResultReq
+	if err := ctx.Bind(&resultReq); err != nil {
+		return err
+	}
+
+	game, err := Live.getPlayerGame(ctx.Client, resultReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if resultReq.Status != 1 && resultReq.Status != 2 {
+		return errors.New("状态不合法")
+	}
+
+	if resultReq.Status == 1 && (resultReq.Duration <= 0 || resultReq.Step <= 0) {
+		return errors.New("成绩不合法")
+	}
+
+	resultReq.UserId = ctx.Client.BindUserId
+	message, _ := json.Marshal(resultReq)
+	websocket.SendToGroup(Live.groupId(game.Id), "live-result", string(message))
+
+	return nil
+}
+
+// End 发起者结束直播, 消息内容为 LiveReq, 向观众推送 live-end 后解散观众群组
+func (LiveImpl) End(ctx *websocket.Context) error {
+	var liveReq models.LiveReq
+	if err := ctx.Bind(&liveReq); err != nil {
+		return err
+	}
+
+	game, err := Live.getPlayerGame(ctx.Client, liveReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if game.HostId != ctx.Client.BindUserId {
+		return errors.New("只有发起者可以结束直播")
+	}
+
+	database.GetRedis().Del(context.Background(), Live.gameKey(game.Id))
+	database.GetRedis().SRem(context.Background(), liveGamesKey, game.Id)
+
+	message, _ := json.Marshal(liveReq)
+	websocket.SendToGroup(Live.groupId(game.Id), "live-end", string(message))
+	for _, userId := range append(game.PlayerIds, game.InviteeIds...) {
+		websocket.SendToUser(userId, "live-end", string(message))
+	}
+
+	websocket.DeleteGroup(Live.groupId(game.Id))
+
+	return nil
+}
+
+// Accept 接受对战邀请, 消息内容为 LiveReq, 成为选手后向所有选手推送 live-accepted
+func (LiveImpl) Accept(ctx *websocket.Context) error {
+	userId := ctx.Client.BindUserId
+	if userId == "" {
+		return websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	var liveReq models.LiveReq
+	if err := ctx.Bind(&liveReq); err != nil {
+		return err
+	}
+
+	game, err := Live.updateGame(liveReq.Id, func(game *models.LiveGame) error {
+		index := slices.Index(game.InviteeIds, userId)
+		if index < 0 {
+			return errors.New("没有收到该对战的邀请")
+		}
+
+		game.InviteeIds = slices.Delete(game.InviteeIds, index, index+1)
+		game.PlayerIds = append(game.PlayerIds, userId)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	message, _ := json.Marshal(game)
+	for _, playerId := range game.PlayerIds {
+		websocket.SendToUser(playerId, "live-accepted", string(message))
+	}
+
+	return nil
+}
+
+// Decline 拒绝对战邀请, 消息内容为 LiveReq, 向所有选手推送 live-declined
+func (LiveImpl) Decline(ctx *websocket.Context) error {
+	userId := ctx.Client.BindUserId
+	if userId == "" {
+		return websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	var liveReq models.LiveReq
+	if err := ctx.Bind(&liveReq); err != nil {
+		return err
+	}
+
+	game, err := Live.updateGame(liveReq.Id, func(game *models.LiveGame) error {
+		index := slices.Index(game.InviteeIds, userId)
+		if index < 0 {
+			return errors.New("没有收到该对战的邀请")
+		}
+
+		game.InviteeIds = slices.Delete(game.InviteeIds, index, index+1)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	message, _ := json.Marshal(models.LiveDeclinedResp{Id: game.Id, UserId: userId})
+	for _, playerId := range game.PlayerIds {
+		websocket.SendToUser(playerId, "live-declined", string(message))
+	}
+
+	return nil
+}
+
+// Join 观看直播, 消息内容为 LiveReq, 未登录也可以观看
+// 回复 live-joined(对局信息与当前打乱), 并向观众推送 live-spectators
+func (LiveImpl) Join(ctx *websocket.Context) error {
+	var liveReq models.LiveReq
+	if err := ctx.Bind(&liveReq); err != nil {
+		return err
+	}
+
+	game, err := Live.getGame(liveReq.Id)
+	if err != nil {
+		return err
+	}
+
+	ctx.Client.JoinGroupById(Live.groupId(game.Id))
+
+	liveResp, err := Live.getResp([]models.LiveGame{game})
+	if err != nil {
+		return err
+	}
+
+	ctx.Reply("live-joined", liveResp[0])
+	Live.broadcastSpectators(game.Id)
+
+	return nil
+}
+
+// Leave 退出观看, 消息内容为 LiveReq
+func (LiveImpl) Leave(ctx *websocket.Context) error {
+	var liveReq models.LiveReq
+	if err := ctx.Bind(&liveReq); err != nil {
+		return err
+	}
+
+	ctx.Client.LeaveGroupById(Live.groupId(liveReq.Id))
+	Live.broadcastSpectators(liveReq.Id)
+
+	return nil
+}
+
+// List 直播列表, 按开始时间倒序
+func (LiveImpl) List(listReq *models.LiveListReq) (models.LiveListResp, error) {
+	var liveListResp models.LiveListResp
+
+	ids, err := database.GetRedis().SMembers(context.Background(), liveGamesKey).Result()
+	if err != nil {
+		return liveListResp, errors.New("获取直播列表失败")
+	}
+
+	games := make([]models.LiveGame, 0, len(ids))
+	for _, id := range ids {
+		game, err := Live.getGame(id)
+		if err != nil {
+			// 已到期的对局
+			database.GetRedis().SRem(context.Background(), liveGamesKey, id)
+			continue
+		}
+
+		if listReq.Type != 0 && game.Type != listReq.Type {
+			continue
+		}
+
+		games = append(games, game)
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].StartedAt.After(games[j].StartedAt)
+	})
+
+	liveListResp.Total = int64(len(games))
+
+	page, pageSize := listReq.Page, listReq.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	switch {
+	case pageSize > 100:
+		pageSize = 100
+	case pageSize <= 0:
+		pageSize = 10
+	}
+
+	start := min((page-1)*pageSize, len(games))
+	end := min(start+pageSize, len(games))
+
+	liveListResp.Records, err = Live.getResp(games[start:end])
+	if err != nil {
+		return liveListResp, err
+	}
+
+	return liveListResp, nil
+}
+
+// Running 直播群组对应的对局是否仍在进行, 结束后未登录的观众需要认证
+func (LiveImpl) Running(groupId string) bool {
+	id := strings.TrimPrefix(groupId, Live.groupId(""))
+
+	n, err := database.GetRedis().Exists(context.Background(), Live.gameKey(id)).Result()
+
+	return err == nil && n > 0
+}
+
+// getGame 获取直播中的对局
+func (LiveImpl) getGame(id string) (models.LiveGame, error) {
+	var game models.LiveGame
+
+	value, err := database.GetRedis().Get(context.Background(), Live.gameKey(id)).Bytes()
+	if err != nil {
+		return game, errors.New("直播不存在或已结束")
+	}
+
+	if err = json.Unmarshal(value, &game); err != nil {
+		return game, errors.New("直播解析失败")
+	}
+
+	return game, nil
+}
+
+// getPlayerGame 获取客户端用户参与的对局
+func (LiveImpl) getPlayerGame(client *websocket.Client, id string) (models.LiveGame, error) {
+	if client.BindUserId == "" {
+		return models.LiveGame{}, websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	game, err := Live.getGame(id)
+	if err != nil {
+		return game, err
+	}
+
+	if !slices.Contains(game.PlayerIds, client.BindUserId) {
+		return game, errors.New("不是该直播的选手")
+	}
+
+	return game, nil
+}
+
+// saveGame 保存对局并刷新保存时长
+func (LiveImpl) saveGame(game *models.LiveGame) error {
+	value, err := json.Marshal(game)
+	if err != nil {
+		return errors.New("对局序列化失败")
+	}
+
+	err = database.GetRedis().Set(context.Background(), Live.gameKey(game.Id), value, liveExpiration).Err()
+	if err != nil {
+		return errors.New("对局存入redis失败")
+	}
+
+	return nil
+}
+
+// updateGame 读取并修改对局, 通过 WATCH 保证并发修改时不丢失更新
+func (LiveImpl) updateGame(id string, fn func(game *models.LiveGame) error) (models.LiveGame, error) {
+	ctx := context.Background()
+	key := Live.gameKey(id)
+
+	var game models.LiveGame
+	for i := 0; i < liveMaxRetries; i++ {
+		err := database.GetRedis().Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.Get(ctx, key).Bytes()
+			if err == redis.Nil {
+				return errors.New("直播不存在或已结束")
+			}
+			if err != nil {
+				return errors.New("获取对局失败")
+			}
+
+			game = models.LiveGame{}
+			if err = json.Unmarshal(value, &game); err != nil {
+				return errors.New("直播解析失败")
+			}
+
+			if err = fn(&game); err != nil {
+				return err
+			}
+
+			value, err = json.Marshal(game)
+			if err != nil {
+				return errors.New("对局序列化失败")
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, value, liveExpiration)
+				return nil
+			})
+
+			return err
+		}, key)
+
+		if err == redis.TxFailedErr {
+			continue
+		}
+
+		return game, err
+	}
+
+	return game, errors.New("对局繁忙, 请稍后重试")
+}
+
+// getResp 补充选手信息与观众人数
+func (LiveImpl) getResp(games []models.LiveGame) ([]models.LiveResp, error) {
+	if len(games) == 0 {
+		return []models.LiveResp{}, nil
+	}
+
+	userIds := make([]int64, 0)
+	for _, game := range games {
+		for _, playerId := range game.PlayerIds {
+			id, _ := strconv.ParseInt(playerId, 10, 64)
+			userIds = append(userIds, id)
+		}
+	}
+
+	users, err := User.GetUserByIds(userIds)
+	if err != nil {
+		return nil, errors.New("获取选手信息失败")
+	}
+
+	userMap := make(map[string]models.UserResp, len(users.Records))
+	for _, user := range users.Records {
+		userMap[user.Id] = user
+	}
+
+	liveResp := make([]models.LiveResp, len(games))
+	for i, game := range games {
+		players := make([]models.UserResp, 0, len(game.PlayerIds))
+		for _, playerId := range game.PlayerIds {
+			if user, ok := userMap[playerId]; ok {
+				players = append(players, user)
+			}
+		}
+
+		liveResp[i] = models.LiveResp{
+			LiveGame:   game,
+			Players:    players,
+			Spectators: websocket.GroupClientCount(Live.groupId(game.Id)),
+		}
+	}
+
+	return liveResp, nil
+}
+
+// broadcastSpectators 向观众推送观众人数
+func (LiveImpl) broadcastSpectators(id string) {
+	message, _ := json.Marshal(models.LiveSpectatorResp{
+		Id:         id,
+		Spectators: websocket.GroupClientCount(Live.groupId(id)),
+	})
+	websocket.SendToGroup(Live.groupId(id), "live-spectators", string(message))
+}
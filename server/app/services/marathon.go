@@ -0,0 +1,483 @@
+package services
+
+import (
+	"errors"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	marathonTargets   = []int{10, 42, 100}  // 马拉松可选的打乱数量
+	timeAttackTargets = []int{60, 120, 300} // 限时可选的时限(秒)
+)
+
+// 限时模式截止后仍然接受提交的时间, 用于抵消网络延迟
+const timeAttackGracePeriod = 3 * time.Second
+
+type MarathonService interface {
+	Start(startReq *models.MarathonStartReq) (models.MarathonResp, error)
+	Submit(submitReq *models.MarathonSubmitReq) (models.MarathonResp, error)
+	Finish(marathonReq *models.MarathonReq) (models.MarathonResp, error)
+	List(marathonReq *models.MarathonReq) (models.MarathonListResp, error)
+	getMarathon(marathonId, userId int64) (models.Marathon, error)
+	expired(marathon *models.Marathon) bool
+	getResp(marathonId int64) (models.MarathonResp, error)
+	issue(marathon *models.Marathon, seq int) error
+	finish(marathon *models.Marathon, status int) error
+	updateBest(marathon *models.Marathon) error
+}
+
+type MarathonImpl struct{}
+
+// Start 开始耐力模式并下发第一个打乱, 用户未完成的耐力模式会被放弃
+func (MarathonImpl) Start(startReq *models.MarathonStartReq) (models.MarathonResp, error) {
+	switch startReq.Mode {
+	case 1:
+		if !slices.Contains(marathonTargets, startReq.Target) {
+			return models.MarathonResp{}, errors.New("打乱数量不合法")
+		}
+	case 2:
+		if !slices.Contains(timeAttackTargets, startReq.Target) {
+			return models.MarathonResp{}, errors.New("时限不合法")
+		}
+	default:
+		return models.MarathonResp{}, errors.New("模式不合法")
+	}
+
+	startReq.Dimension, startReq.Width, startReq.Height = utils.NormalizeSize(startReq.Dimension, startReq.Width, startReq.Height)
+
+	if startReq.Width < minScrambleSize || startReq.Width > maxScrambleSize ||
+		startReq.Height < minScrambleSize || startReq.Height > maxScrambleSize {
+		return models.MarathonResp{}, errors.New("尺寸不合法")
+	}
+
+	// 已超过截止时间的限时模式按已完成的打乱结束, 不视为放弃
+	var ongoing []models.Marathon
+	err := database.GetMySQL().Table("marathon").
+		Where("user_id = ? AND status = ?", startReq.UserId, 1).
+		Find(&ongoing).Error
+	if err != nil {
+		return models.MarathonResp{}, errors.New("查询耐力模式失败")
+	}
+
+	for i := range ongoing {
+		if Marathon.expired(&ongoing[i]) {
+			err = Marathon.finish(&ongoing[i], 2)
+			if err != nil {
+				return models.MarathonResp{}, err
+			}
+		}
+	}
+
+	// 放弃未完成的耐力模式
+	err = database.GetMySQL().Table("marathon").
+		Where("user_id = ? AND status = ?", startReq.UserId, 1).
+		Update("status", 3).Error
+	if err != nil {
+		return models.MarathonResp{}, errors.New("更新耐力模式失败")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	marathon := models.Marathon{
+		Id:        snowflake.NextVal(),
+		UserId:    startReq.UserId,
+		Mode:      startReq.Mode,
+		Dimension: startReq.Dimension,
+		Width:     startReq.Width,
+		Height:    startReq.Height,
+		Target:    startReq.Target,
+		Current:   1,
+		Status:    1,
+	}
+
+	// 限时模式从下发第一个打乱开始计时
+	if marathon.Mode == 2 {
+		expiredAt := time.Now().Add(time.Duration(marathon.Target) * time.Second)
+		marathon.ExpiredAt = &expiredAt
+	}
+
+	err = database.GetMySQL().Create(&marathon).Error
+	if err != nil {
+		return models.MarathonResp{}, errors.New("新增耐力模式失败")
+	}
+
+	err = Marathon.issue(&marathon, 1)
+	if err != nil {
+		return models.MarathonResp{}, err
+	}
+
+	return Marathon.getResp(marathon.Id)
+}
+
+// Submit 提交当前打乱的成绩, 校验通过后下发下一个打乱
+// 限时模式超过截止时间后提交的成绩不计入, 直接结束
+func (MarathonImpl) Submit(submitReq *models.MarathonSubmitReq) (models.MarathonResp, error) {
+	if submitReq.IdStr != "" {
+		submitReq.Id, _ = strconv.ParseInt(submitReq.IdStr, 10, 64)
+	}
+
+	marathon, err := Marathon.getMarathon(submitReq.Id, submitReq.UserId)
+	if err != nil {
+		return models.MarathonResp{}, err
+	}
+
+	// 限时模式已超过截止时间, 获取时已结束
+	if marathon.Status != 1 {
+		return Marathon.getResp(marathon.Id)
+	}
+
+	if submitReq.Duration <= 0 || submitReq.Step <= 0 || submitReq.Solution == "" {
+		return models.MarathonResp{}, errors.New("成绩不完整")
+	}
+
+	var solve models.MarathonSolve
+	err = database.GetMySQL().Table("marathon_solve").
+		Where("marathon_id = ? AND seq = ? AND status = ?", marathon.Id, marathon.Current, 1).
+		First(&solve).Error
+	if err != nil {
+		return models.MarathonResp{}, errors.New("当前打乱不存在")
+	}
+
+	encryptionParams := utils.EncryptionParams{
+		Dimension: marathon.Dimension,
+		Width:     marathon.Width,
+		Height:    marathon.Height,
+		RandomIdx: solve.Idx,
+		StepCount: submitReq.Step,
+		Scramble:  solve.Scramble,
+		Solution:  submitReq.Solution,
+		Version:   solve.ScrambleVersion,
+	}
+
+	if !encryptionParams.VerifyScramble() {
+		return models.MarathonResp{}, ErrRecordVerify
+	}
+
+	// 只更新未完成的打乱, 避免重复提交
+	result := database.GetMySQL().Table("marathon_solve").
+		Where("id = ? AND status = ?", solve.Id, 1).
+		Updates(map[string]any{
+			"duration": submitReq.Duration,
+			"step":     submitReq.Step,
+			"solution": submitReq.Solution,
+			"status":   2,
+		})
+	if result.Error != nil {
+		return models.MarathonResp{}, errors.New("提交失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return models.MarathonResp{}, errors.New("该打乱已提交过成绩, 请勿重复提交")
+	}
+
+	marathon.SolvedCount++
+	marathon.Duration += submitReq.Duration
+	marathon.Step += submitReq.Step
+
+	err = database.GetMySQL().Table("marathon").Where("id = ?", marathon.Id).Updates(map[string]any{
+		"solved_count": marathon.SolvedCount,
+		"duration":     marathon.Duration,
+		"step":         marathon.Step,
+	}).Error
+	if err != nil {
+		return models.MarathonResp{}, errors.New("更新耐力模式失败")
+	}
+
+	if marathon.Mode == 1 && marathon.SolvedCount >= marathon.Target {
+		// 马拉松完成所有打乱
+		err = Marathon.finish(&marathon, 2)
+	} else {
+		err = Marathon.issue(&marathon, marathon.Current+1)
+	}
+	if err != nil {
+		return models.MarathonResp{}, err
+	}
+
+	return Marathon.getResp(marathon.Id)
+}
+
+// Finish 主动结束耐力模式, 限时模式按已完成的打乱计入成绩, 未完成的马拉松视为放弃
+func (MarathonImpl) Finish(marathonReq *models.MarathonReq) (models.MarathonResp, error) {
+	if marathonReq.IdStr != "" {
+		marathonReq.Id, _ = strconv.ParseInt(marathonReq.IdStr, 10, 64)
+	}
+
+	marathon, err := Marathon.getMarathon(marathonReq.Id, marathonReq.UserId)
+	if err != nil {
+		return models.MarathonResp{}, err
+	}
+
+	status := 3
+	if marathon.Mode == 2 {
+		status = 2
+	}
+
+	err = Marathon.finish(&marathon, status)
+	if err != nil {
+		return models.MarathonResp{}, err
+	}
+
+	return Marathon.getResp(marathon.Id)
+}
+
+// List 耐力模式列表
+func (MarathonImpl) List(marathonReq *models.MarathonReq) (models.MarathonListResp, error) {
+	var marathonListResp models.MarathonListResp
+
+	if marathonReq.IdStr != "" {
+		marathonReq.Id, _ = strconv.ParseInt(marathonReq.IdStr, 10, 64)
+	}
+
+	if marathonReq.UserIdStr != "" {
+		marathonReq.UserId, _ = strconv.ParseInt(marathonReq.UserIdStr, 10, 64)
+	}
+
+	db := database.GetMySQL().Table("marathon").Order("id " + utils.SortDirection(marathonReq.Sorted, "desc"))
+
+	if marathonReq.Id != 0 {
+		db.Where("id = ?", marathonReq.Id)
+	}
+
+	if marathonReq.UserId != 0 {
+		db.Where("user_id = ?", marathonReq.UserId)
+	}
+
+	if marathonReq.Mode != 0 {
+		db.Where("mode = ?", marathonReq.Mode)
+	}
+
+	_, marathonReq.Width, marathonReq.Height = utils.NormalizeSize(marathonReq.Dimension, marathonReq.Width, marathonReq.Height)
+
+	if marathonReq.Width != 0 {
+		db.Where("width = ?", marathonReq.Width)
+	}
+
+	if marathonReq.Height != 0 {
+		db.Where("height = ?", marathonReq.Height)
+	}
+
+	if marathonReq.Target != 0 {
+		db.Where("target = ?", marathonReq.Target)
+	}
+
+	if marathonReq.Status != 0 {
+		db.Where("status = ?", marathonReq.Status)
+	}
+
+	// 查询总数
+	err := db.Count(&marathonListResp.Total).Error
+	if err != nil {
+		return marathonListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if marathonReq.Pagination.Page > 0 && marathonReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&marathonReq.Pagination))
+	}
+
+	err = db.Preload("UserInfo").
+		Preload("Solves", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq asc")
+		}).
+		Find(&marathonListResp.Records).Error
+	if err != nil {
+		return marathonListResp, errors.New("查询失败")
+	}
+
+	return marathonListResp, nil
+}
+
+// getMarathon 获取用户进行中的耐力模式, 已超过截止时间的限时模式会先结束, 返回的状态为已完成
+func (MarathonImpl) getMarathon(marathonId, userId int64) (models.Marathon, error) {
+	var marathon models.Marathon
+	err := database.GetMySQL().Table("marathon").
+		Where("id = ? AND user_id = ?", marathonId, userId).
+		First(&marathon).Error
+	if err != nil {
+		return marathon, errors.New("耐力模式不存在")
+	}
+
+	if marathon.Status != 1 {
+		return marathon, errors.New("耐力模式已结束")
+	}
+
+	// 限时模式超过截止时间后按已完成的打乱结束
+	if Marathon.expired(&marathon) {
+		err = Marathon.finish(&marathon, 2)
+		if err != nil {
+			return marathon, err
+		}
+	}
+
+	return marathon, nil
+}
+
+// expired 判断限时模式是否已超过截止时间(含宽限时间)
+func (MarathonImpl) expired(marathon *models.Marathon) bool {
+	return marathon.Mode == 2 && marathon.ExpiredAt != nil && time.Now().After(marathon.ExpiredAt.Add(timeAttackGracePeriod))
+}
+
+// getResp 获取耐力模式详情
+func (MarathonImpl) getResp(marathonId int64) (models.MarathonResp, error) {
+	marathonList, err := Marathon.List(&models.MarathonReq{Id: marathonId})
+	if err != nil || marathonList.Total == 0 {
+		return models.MarathonResp{}, errors.New("查询耐力模式失败")
+	}
+
+	return marathonList.Records[0], nil
+}
+
+// issue 下发第 seq 个打乱
+func (MarathonImpl) issue(marathon *models.Marathon, seq int) error {
+	idx, scramble, difficulty := Scramble.generate(marathon.Width, marathon.Height)
+
+	scrambleModel := &models.Scramble{
+		Dimension:  marathon.Dimension,
+		Width:      marathon.Width,
+		Height:     marathon.Height,
+		Idx:        idx,
+		Scramble:   puzzle.FormatScramble(scramble),
+		Version:    puzzle.CurrentVersion,
+		Difficulty: difficulty,
+	}
+
+	err := Scramble.Insert(scrambleModel)
+	if err != nil {
+		return errors.New("新增打乱失败")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	err = database.GetMySQL().Create(&models.MarathonSolve{
+		Id:              snowflake.NextVal(),
+		MarathonId:      marathon.Id,
+		Seq:             seq,
+		ScrambleId:      scrambleModel.Id,
+		Idx:             scrambleModel.Idx,
+		Scramble:        scrambleModel.Scramble,
+		ScrambleVersion: scrambleModel.Version,
+		Status:          1,
+	}).Error
+	if err != nil {
+		return errors.New("下发打乱失败")
+	}
+
+	if seq != marathon.Current {
+		err = database.GetMySQL().Table("marathon").Where("id = ?", marathon.Id).Update("current", seq).Error
+		if err != nil {
+			return errors.New("更新耐力模式失败")
+		}
+
+		marathon.Current = seq
+	}
+
+	return nil
+}
+
+// finish 结束耐力模式, 完成时更新最佳记录
+func (MarathonImpl) finish(marathon *models.Marathon, status int) error {
+	result := database.GetMySQL().Table("marathon").
+		Where("id = ? AND status = ?", marathon.Id, 1).
+		Update("status", status)
+	if result.Error != nil {
+		return errors.New("更新耐力模式失败")
+	}
+
+	// 已被其他请求结束
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	marathon.Status = status
+
+	if status == 2 && marathon.SolvedCount > 0 {
+		return Marathon.updateBest(marathon)
+	}
+
+	return nil
+}
+
+// updateBest 更新最佳耐力模式记录, 马拉松比较总耗时, 限时比较完成数量, 数量相同时比较总耗时
+func (MarathonImpl) updateBest(marathon *models.Marathon) error {
+	recordBestMarathon, err := RecordBestMarathon.List(&models.RecordBestMarathonReq{
+		UserId: marathon.UserId,
+		Mode:   marathon.Mode,
+		Width:  marathon.Width,
+		Height: marathon.Height,
+		Target: marathon.Target,
+		Pagination: utils.Pagination{
+			Page:     1,
+			PageSize: 1,
+		},
+	})
+	if err != nil {
+		return errors.New("获取最佳耐力模式记录失败")
+	}
+
+	// 若无最佳记录, 则直接插入
+	if recordBestMarathon.Total == 0 {
+		snowflake := utils.Snowflake{}
+
+		err = RecordBestMarathon.Insert(&models.RecordBestMarathon{
+			Id:             snowflake.NextVal(),
+			UserId:         marathon.UserId,
+			Mode:           marathon.Mode,
+			Dimension:      marathon.Dimension,
+			Width:          marathon.Width,
+			Height:         marathon.Height,
+			Target:         marathon.Target,
+			MarathonId:     marathon.Id,
+			SolvedCount:    marathon.SolvedCount,
+			RecordDuration: marathon.Duration,
+			RecordStep:     marathon.Step,
+		})
+		if err != nil {
+			return errors.New("新增最佳耐力模式记录失败")
+		}
+
+		return nil
+	}
+
+	best := recordBestMarathon.Records[0]
+
+	broken := marathon.Duration < best.RecordDuration
+	if marathon.Mode == 2 {
+		broken = marathon.SolvedCount > best.SolvedCount ||
+			(marathon.SolvedCount == best.SolvedCount && marathon.Duration < best.RecordDuration)
+	}
+
+	// 没有打破记录
+	if !broken {
+		return nil
+	}
+
+	id, _ := strconv.ParseInt(best.Id, 10, 64)
+
+	err = RecordBestMarathon.Update(&models.RecordBestMarathon{
+		Id:               id,
+		UserId:           marathon.UserId,
+		Mode:             marathon.Mode,
+		Dimension:        marathon.Dimension,
+		Width:            marathon.Width,
+		Height:           marathon.Height,
+		Target:           marathon.Target,
+		MarathonId:       marathon.Id,
+		SolvedCount:      marathon.SolvedCount,
+		RecordDuration:   marathon.Duration,
+		RecordStep:       marathon.Step,
+		RecordBreakCount: best.RecordBreakCount + 1,
+	})
+	if err != nil {
+		return errors.New("更新最佳耐力模式记录失败")
+	}
+
+	return nil
+}
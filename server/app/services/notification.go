@@ -48,28 +48,11 @@ func (NotificationImpl) publishMessage(notificationMsg handlers.NotificationMsg)
 
 // sendWebsocketMessage 发送消息至websocket
 func (NotificationImpl) sendWebsocketMessage(userId int64, content string) {
-
-	// 编辑消息
-	message, _ := json.Marshal(websocket.Message{
-		Type:    "notification",
-		Content: content,
-	})
-
 	// 全体消息
 	if userId == 0 {
-		websocket.ClientManagerInstance.Broadcast <- message
+		websocket.Broadcast("notification", content)
 	} else { // 单体消息
-		websocketUser, ok := websocket.GatewayUser.Load(strconv.FormatInt(userId, 10))
-		if ok {
-			userBase := websocketUser.(*websocket.WebSocketUser)
-			for _, v := range userBase.ClientID {
-				client, ok := websocket.ClientManagerInstance.Clients.Load(v)
-				if ok {
-					clientInfo := client.(*websocket.Client)
-					clientInfo.Send <- message
-				}
-			}
-		}
+		websocket.SendToUser(strconv.FormatInt(userId, 10), "notification", content)
 	}
 }
 
@@ -0,0 +1,787 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"puzzle/app/middlewares/websocket"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	raceRoomExpiration         = 2 * time.Hour    // 房间保存时长, 每次更新时刷新
+	raceRoomFinishedExpiration = 30 * time.Minute // 已结束房间保存时长, 用于查看最终积分
+	raceRoundTimeout           = 10 * time.Minute // 每轮时限, 超时未提交的选手记为超时
+)
+
+const (
+	maxRaceRoomPlayers = 8  // 房间最多选手数量, 包括房主
+	maxRaceRoomRounds  = 20 // 最多轮数
+	raceRoomMaxRetries = 5  // 并发更新房间冲突时的最大重试次数
+)
+
+var errRaceRoomNotFound = errors.New("房间不存在或已解散")
+
+type RaceRoomService interface {
+	Create(ctx *websocket.Context) error
+	Invite(ctx *websocket.Context) error
+	Join(ctx *websocket.Context) error
+	Leave(ctx *websocket.Context) error
+	Ready(ctx *websocket.Context) error
+	Start(ctx *websocket.Context) error
+	Submit(ctx *websocket.Context) error
+	Get(ctx *websocket.Context) error
+	get(id string) (models.RaceRoom, error)
+	create(userId string, createReq *models.RaceRoomCreateReq) (models.RaceRoom, error)
+	update(id string, fn func(room *models.RaceRoom) error) (models.RaceRoom, error)
+	checkInvitees(room *models.RaceRoom, inviteeIds []string) error
+	newRound(room *models.RaceRoom) (models.RaceRound, error)
+	nextRound(room *models.RaceRoom, round models.RaceRound)
+	startTimer(room *models.RaceRoom)
+	settleRound(room *models.RaceRoom, force bool) bool
+	advance(room models.RaceRoom) models.RaceRoom
+	timeout(id string, seq int)
+	broadcast(room *models.RaceRoom, settled bool)
+}
+
+type RaceRoomImpl struct{}
+
+// roomKey 房间在redis中的key
+func (RaceRoomImpl) roomKey(id string) string {
+	return "race:room:" + id
+}
+
+// groupId 房间成员的 WebSocket 群组
+func (RaceRoomImpl) groupId(id string) string {
+	return "race:" + id
+}
+
+// Create 创建房间, 消息内容为 RaceRoomCreateReq, 创建者为房主, 向被邀请的用户推送 race-invited
+func (RaceRoomImpl) Create(ctx *websocket.Context) error {
+	client := ctx.Client
+
+	if client.BindUserId == "" {
+		return websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	var createReq models.RaceRoomCreateReq
+	if err := ctx.Bind(&createReq); err != nil {
+		return err
+	}
+
+	room, err := RaceRoom.create(client.BindUserId, &createReq)
+	if err != nil {
+		return err
+	}
+
+	client.JoinGroupById(RaceRoom.groupId(room.Id))
+	ctx.Reply("race-room", room)
+
+	for _, inviteeId := range room.InviteeIds {
+		websocket.SendToUser(inviteeId, "race-invited", string(marshalContent(room)))
+	}
+
+	return nil
+}
+
+// create 校验并保存房间
+func (RaceRoomImpl) create(userId string, createReq *models.RaceRoomCreateReq) (models.RaceRoom, error) {
+	createReq.Dimension, createReq.Width, createReq.Height = utils.NormalizeSize(createReq.Dimension, createReq.Width, createReq.Height)
+
+	if createReq.Width < minScrambleSize || createReq.Width > maxScrambleSize ||
+		createReq.Height < minScrambleSize || createReq.Height > maxScrambleSize {
+		return models.RaceRoom{}, errors.New("尺寸不合法")
+	}
+
+	if createReq.Rounds <= 0 || createReq.Rounds > maxRaceRoomRounds {
+		return models.RaceRoom{}, errors.New("轮数不合法")
+	}
+
+	if createReq.MaxPlayers == 0 {
+		createReq.MaxPlayers = maxRaceRoomPlayers
+	}
+
+	if createReq.MaxPlayers < 2 || createReq.MaxPlayers > maxRaceRoomPlayers {
+		return models.RaceRoom{}, errors.New("选手数量不合法")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	room := models.RaceRoom{
+		Id:         strconv.FormatInt(snowflake.NextVal(), 10),
+		HostId:     userId,
+		Dimension:  createReq.Dimension,
+		Width:      createReq.Width,
+		Height:     createReq.Height,
+		Rounds:     createReq.Rounds,
+		MaxPlayers: createReq.MaxPlayers,
+		Status:     1,
+		InviteeIds: make([]string, 0),
+		Players: []models.RaceRoomPlayer{
+			{UserId: userId, Ready: true, Status: 1},
+		},
+		RoundList: make([]models.RaceRound, 0),
+		CreatedAt: time.Now(),
+	}
+
+	if err := RaceRoom.checkInvitees(&room, createReq.InviteeIds); err != nil {
+		return models.RaceRoom{}, err
+	}
+
+	room.InviteeIds = append(room.InviteeIds, createReq.InviteeIds...)
+
+	err := database.GetRedis().Set(context.Background(), RaceRoom.roomKey(room.Id), marshalContent(room), raceRoomExpiration).Err()
+	if err != nil {
+		return models.RaceRoom{}, errors.New("房间存入redis失败")
+	}
+
+	return room, nil
+}
+
+// Invite 房主邀请选手, 消息内容为 RaceRoomInviteReq, 仅等待中的房间可以邀请
+func (RaceRoomImpl) Invite(ctx *websocket.Context) error {
+	var inviteReq models.RaceRoomInviteReq
+	if err := ctx.Bind(&inviteReq); err != nil {
+		return err
+	}
+
+	room, err := RaceRoom.update(inviteReq.Id, func(room *models.RaceRoom) error {
+		if room.HostId != ctx.Client.BindUserId {
+			return errors.New("只有房主可以邀请选手")
+		}
+
+		if room.Status != 1 {
+			return errors.New("房间已开始")
+		}
+
+		if err := RaceRoom.checkInvitees(room, inviteReq.InviteeIds); err != nil {
+			return err
+		}
+
+		room.InviteeIds = append(room.InviteeIds, inviteReq.InviteeIds...)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	RaceRoom.broadcast(&room, false)
+
+	for _, inviteeId := range inviteReq.InviteeIds {
+		websocket.SendToUser(inviteeId, "race-invited", string(marshalContent(room)))
+	}
+
+	return nil
+}
+
+// checkInvitees 校验被邀请的用户, 已加入与已邀请的人数不能超过房间上限
+func (RaceRoomImpl) checkInvitees(room *models.RaceRoom, inviteeIds []string) error {
+	if len(inviteeIds) == 0 {
+		return nil
+	}
+
+	if len(room.Players)+len(room.InviteeIds)+len(inviteeIds) > room.MaxPlayers {
+		return errors.New("邀请人数超过房间上限")
+	}
+
+	userIds := make([]int64, 0, len(inviteeIds))
+	for i, inviteeId := range inviteeIds {
+		if slices.Contains(inviteeIds[:i], inviteeId) || slices.Contains(room.InviteeIds, inviteeId) ||
+			slices.ContainsFunc(room.Players, func(player models.RaceRoomPlayer) bool { return player.UserId == inviteeId }) {
+			return errors.New("该用户已被邀请或已在房间中")
+		}
+
+		id, err := strconv.ParseInt(inviteeId, 10, 64)
+		if err != nil {
+			return errors.New("用户ID有误")
+		}
+
+		userIds = append(userIds, id)
+	}
+
+	users, err := User.GetUserByIds(userIds)
+	if err != nil {
+		return errors.New("获取用户信息失败")
+	}
+
+	if len(users.Records) != len(userIds) {
+		return errors.New("用户不存在")
+	}
+
+	return nil
+}
+
+// Join 被邀请的用户加入房间, 消息内容为 RaceRoomReq
+func (RaceRoomImpl) Join(ctx *websocket.Context) error {
+	client := ctx.Client
+
+	if client.BindUserId == "" {
+		return websocket.NewError(websocket.ErrCodeUnauthorized, "请先登录")
+	}
+
+	var roomReq models.RaceRoomReq
+	if err := ctx.Bind(&roomReq); err != nil {
+		return err
+	}
+
+	room, err := RaceRoom.update(roomReq.Id, func(room *models.RaceRoom) error {
+		if room.Status != 1 {
+			return errors.New("房间已开始")
+		}
+
+		index := slices.Index(room.InviteeIds, client.BindUserId)
+		if index < 0 {
+			return errors.New("没有收到该房间的邀请")
+		}
+
+		if len(room.Players) >= room.MaxPlayers {
+			return errors.New("房间已满")
+		}
+
+		room.InviteeIds = slices.Delete(room.InviteeIds, index, index+1)
+		room.Players = append(room.Players, models.RaceRoomPlayer{UserId: client.BindUserId, Status: 1})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	client.JoinGroupById(RaceRoom.groupId(room.Id))
+	RaceRoom.broadcast(&room, false)
+
+	return nil
+}
+
+// Leave 离开房间, 消息内容为 RaceRoomReq
+// 等待中房主离开时解散房间; 进行中离开的选手不再参与之后的轮次, 当前轮次记为放弃
+func (RaceRoomImpl) Leave(ctx *websocket.Context) error {
+	client := ctx.Client
+
+	var roomReq models.RaceRoomReq
+	if err := ctx.Bind(&roomReq); err != nil {
+		return err
+	}
+
+	var settled bool
+	room, err := RaceRoom.update(roomReq.Id, func(room *models.RaceRoom) error {
+		settled = false
+
+		index := slices.IndexFunc(room.Players, func(player models.RaceRoomPlayer) bool {
+			return player.UserId == client.BindUserId && player.Status == 1
+		})
+		if index < 0 {
+			return errors.New("不在该房间中")
+		}
+
+		switch room.Status {
+		case 1:
+			if room.HostId == client.BindUserId {
+				room.Status = 4
+				return nil
+			}
+
+			room.Players = slices.Delete(room.Players, index, index+1)
+		case 2:
+			room.Players[index].Status = 2
+
+			// 房主离开时转交给其他选手
+			active := slices.IndexFunc(room.Players, func(player models.RaceRoomPlayer) bool { return player.Status == 1 })
+			if active < 0 {
+				room.Status = 4
+				return nil
+			}
+
+			if room.HostId == client.BindUserId {
+				room.HostId = room.Players[active].UserId
+			}
+
+			round := &room.RoundList[room.CurrentRound-1]
+			if round.Status == 1 && !slices.ContainsFunc(round.Results, func(result models.RaceRoundResult) bool { return result.UserId == client.BindUserId }) {
+				round.Results = append(round.Results, models.RaceRoundResult{UserId: client.BindUserId, Status: 2})
+			}
+
+			settled = RaceRoom.settleRound(room, false)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	client.LeaveGroupById(RaceRoom.groupId(room.Id))
+	if settled {
+		room = RaceRoom.advance(room)
+	}
+
+	RaceRoom.broadcast(&room, settled)
+
+	return nil
+}
+
+// Ready 准备或取消准备, 消息内容为 RaceRoomReadyReq
+func (RaceRoomImpl) Ready(ctx *websocket.Context) error {
+	var readyReq models.RaceRoomReadyReq
+	if err := ctx.Bind(&readyReq); err != nil {
+		return err
+	}
+
+	room, err := RaceRoom.update(readyReq.Id, func(room *models.RaceRoom) error {
+		if room.Status != 1 {
+			return errors.New("房间已开始")
+		}
+
+		index := slices.IndexFunc(room.Players, func(player models.RaceRoomPlayer) bool { return player.UserId == ctx.Client.BindUserId })
+		if index < 0 {
+			return errors.New("不在该房间中")
+		}
+
+		room.Players[index].Ready = readyReq.Ready
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	RaceRoom.broadcast(&room, false)
+
+	return nil
+}
+
+// Start 房主开始比赛, 消息内容为 RaceRoomReq, 需要至少两名选手且全部已准备, 未加入的邀请失效
+func (RaceRoomImpl) Start(ctx *websocket.Context) error {
+	client := ctx.Client
+
+	var roomReq models.RaceRoomReq
+	if err := ctx.Bind(&roomReq); err != nil {
+		return err
+	}
+
+	room, err := RaceRoom.get(roomReq.Id)
+	if err != nil {
+		return err
+	}
+
+	// 生成打乱的开销较大, 先排除没有权限的请求
+	if room.HostId != client.BindUserId {
+		return errors.New("只有房主可以开始比赛")
+	}
+
+	round, err := RaceRoom.newRound(&room)
+	if err != nil {
+		return err
+	}
+
+	room, err = RaceRoom.update(roomReq.Id, func(room *models.RaceRoom) error {
+		if room.HostId != client.BindUserId {
+			return errors.New("只有房主可以开始比赛")
+		}
+
+		if room.Status != 1 {
+			return errors.New("房间已开始")
+		}
+
+		if len(room.Players) < 2 {
+			return errors.New("至少需要两名选手")
+		}
+
+		for _, player := range room.Players {
+			if !player.Ready && player.UserId != room.HostId {
+				return errors.New("还有选手未准备")
+			}
+		}
+
+		room.Status = 2
+		room.InviteeIds = make([]string, 0)
+		RaceRoom.nextRound(room, round)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	RaceRoom.startTimer(&room)
+
+	RaceRoom.broadcast(&room, false)
+	websocket.SendToGroup(RaceRoom.groupId(room.Id), "race-round", string(marshalContent(room.RoundList[0])))
+
+	return nil
+}
+
+// Submit 提交当前轮次的成绩, 消息内容为 RaceRoomSubmitReq, 所有选手提交后结算本轮
+func (RaceRoomImpl) Submit(ctx *websocket.Context) error {
+	client := ctx.Client
+
+	var submitReq models.RaceRoomSubmitReq
+	if err := ctx.Bind(&submitReq); err != nil {
+		return err
+	}
+
+	var settled bool
+	room, err := RaceRoom.update(submitReq.Id, func(room *models.RaceRoom) error {
+		settled = false
+
+		if room.Status != 2 {
+			return errors.New("比赛未在进行中")
+		}
+
+		if submitReq.Round != room.CurrentRound {
+			return errors.New("该轮次已结束")
+		}
+
+		if !slices.ContainsFunc(room.Players, func(player models.RaceRoomPlayer) bool {
+			return player.UserId == client.BindUserId && player.Status == 1
+		}) {
+			return errors.New("不在该房间中")
+		}
+
+		round := &room.RoundList[room.CurrentRound-1]
+		if round.Status != 1 {
+			return errors.New("该轮次已结束")
+		}
+
+		if slices.ContainsFunc(round.Results, func(result models.RaceRoundResult) bool { return result.UserId == client.BindUserId }) {
+			return errors.New("本轮已提交过成绩, 请勿重复提交")
+		}
+
+		result := models.RaceRoundResult{UserId: client.BindUserId, Status: submitReq.Status}
+
+		switch submitReq.Status {
+		case 1:
+			if submitReq.Duration <= 0 || submitReq.Step <= 0 || submitReq.Solution == "" {
+				return errors.New("成绩不完整")
+			}
+
+			encryptionParams := utils.EncryptionParams{
+				Dimension: room.Dimension,
+				Width:     room.Width,
+				Height:    room.Height,
+				RandomIdx: round.Idx,
+				StepCount: submitReq.Step,
+				Scramble:  round.Scramble,
+				Solution:  submitReq.Solution,
+				Version:   round.Version,
+			}
+
+			if !encryptionParams.VerifyScramble() {
+				return ErrRecordVerify
+			}
+
+			result.Duration = submitReq.Duration
+			result.Step = submitReq.Step
+		case 2:
+		default:
+			return errors.New("状态不合法")
+		}
+
+		round.Results = append(round.Results, result)
+		settled = RaceRoom.settleRound(room, false)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if settled {
+		room = RaceRoom.advance(room)
+	}
+
+	RaceRoom.broadcast(&room, settled)
+
+	return nil
+}
+
+// Get 获取房间状态, 消息内容为 RaceRoomReq, 房间中的选手断线重连后通过该消息重新接收房间消息
+func (RaceRoomImpl) Get(ctx *websocket.Context) error {
+	client := ctx.Client
+
+	var roomReq models.RaceRoomReq
+	if err := ctx.Bind(&roomReq); err != nil {
+		return err
+	}
+
+	room, err := RaceRoom.get(roomReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(room.Players, func(player models.RaceRoomPlayer) bool {
+		return player.UserId == client.BindUserId && player.Status == 1
+	}) {
+		client.JoinGroupById(RaceRoom.groupId(room.Id))
+	}
+
+	ctx.Reply("race-room", room)
+
+	return nil
+}
+
+// get 读取房间
+func (RaceRoomImpl) get(id string) (models.RaceRoom, error) {
+	var room models.RaceRoom
+
+	value, err := database.GetRedis().Get(context.Background(), RaceRoom.roomKey(id)).Bytes()
+	if err != nil {
+		return room, errRaceRoomNotFound
+	}
+
+	if err = json.Unmarshal(value, &room); err != nil {
+		return room, errors.New("房间解析失败")
+	}
+
+	return room, nil
+}
+
+// update 读取并修改房间, 通过 WATCH 保证并发修改时不丢失更新, 状态为已解散时删除房间
+func (RaceRoomImpl) update(id string, fn func(room *models.RaceRoom) error) (models.RaceRoom, error) {
+	ctx := context.Background()
+	key := RaceRoom.roomKey(id)
+
+	var room models.RaceRoom
+	for i := 0; i < raceRoomMaxRetries; i++ {
+		err := database.GetRedis().Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.Get(ctx, key).Bytes()
+			if err == redis.Nil {
+				return errRaceRoomNotFound
+			}
+			if err != nil {
+				return errors.New("获取房间失败")
+			}
+
+			room = models.RaceRoom{}
+			if err = json.Unmarshal(value, &room); err != nil {
+				return errors.New("房间解析失败")
+			}
+
+			if err = fn(&room); err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				switch room.Status {
+				case 3:
+					pipe.Set(ctx, key, marshalContent(room), raceRoomFinishedExpiration)
+				case 4:
+					pipe.Del(ctx, key)
+				default:
+					pipe.Set(ctx, key, marshalContent(room), raceRoomExpiration)
+				}
+				return nil
+			})
+
+			return err
+		}, key)
+
+		if err == redis.TxFailedErr {
+			continue
+		}
+
+		return room, err
+	}
+
+	return room, errors.New("房间繁忙, 请稍后重试")
+}
+
+// newRound 生成新一轮的打乱, 在修改房间之前调用, 避免 WATCH 冲突重试时重复生成
+func (RaceRoomImpl) newRound(room *models.RaceRoom) (models.RaceRound, error) {
+	idx, scramble, _ := Scramble.generate(room.Width, room.Height)
+	if scramble == nil {
+		return models.RaceRound{}, errors.New("生成打乱失败")
+	}
+
+	return models.RaceRound{
+		Idx:      idx,
+		Scramble: puzzle.FormatScramble(scramble),
+		Version:  puzzle.CurrentVersion,
+		Status:   1,
+		Results:  make([]models.RaceRoundResult, 0),
+	}, nil
+}
+
+// nextRound 以预先生成的 round 开始下一轮, 所有选手使用同一个打乱
+func (RaceRoomImpl) nextRound(room *models.RaceRoom, round models.RaceRound) {
+	room.CurrentRound++
+	round.Seq = room.CurrentRound
+	room.RoundList = append(room.RoundList, round)
+	room.RoundStartedAt = time.Now()
+}
+
+// startTimer 房间保存后开始当前轮次的计时, 超时后强制结算
+func (RaceRoomImpl) startTimer(room *models.RaceRoom) {
+	seq := room.CurrentRound
+	id := room.Id
+	time.AfterFunc(raceRoundTimeout, func() {
+		RaceRoom.timeout(id, seq)
+	})
+}
+
+// settleRound 所有在房间的选手都已提交时结算当前轮次, force 为 true 时未提交的选手记为超时
+// 完成的选手按耗时、步数排名, 第一名得到与本轮选手数量相同的积分, 依次递减, 放弃与超时不得分
+// 最后一轮结算后结束比赛, 其余轮次结算后由 advance 在保存之后开始下一轮
+func (RaceRoomImpl) settleRound(room *models.RaceRoom, force bool) bool {
+	round := &room.RoundList[room.CurrentRound-1]
+	if round.Status != 1 {
+		return false
+	}
+
+	for _, player := range room.Players {
+		if player.Status != 1 || slices.ContainsFunc(round.Results, func(result models.RaceRoundResult) bool { return result.UserId == player.UserId }) {
+			continue
+		}
+
+		if !force {
+			return false
+		}
+
+		round.Results = append(round.Results, models.RaceRoundResult{UserId: player.UserId, Status: 3})
+	}
+
+	sort.SliceStable(round.Results, func(i, j int) bool {
+		a, b := round.Results[i], round.Results[j]
+		if (a.Status == 1) != (b.Status == 1) {
+			return a.Status == 1
+		}
+		if a.Duration != b.Duration {
+			return a.Duration < b.Duration
+		}
+		return a.Step < b.Step
+	})
+
+	for i := range round.Results {
+		if round.Results[i].Status != 1 {
+			continue
+		}
+
+		round.Results[i].Points = len(round.Results) - i
+
+		for j := range room.Players {
+			if room.Players[j].UserId == round.Results[i].UserId {
+				room.Players[j].Points += round.Results[i].Points
+			}
+		}
+	}
+
+	round.Status = 2
+
+	if room.CurrentRound >= room.Rounds {
+		room.Status = 3
+	}
+
+	return true
+}
+
+// advance 结算后开始下一轮: 在 WATCH 之外生成打乱, 保存后再开始计时
+// 生成打乱失败时提前结束比赛, 房间已被解散时返回已解散的状态
+func (RaceRoomImpl) advance(room models.RaceRoom) models.RaceRoom {
+	if room.Status != 2 {
+		return room
+	}
+
+	seq := room.CurrentRound
+	round, roundErr := RaceRoom.newRound(&room)
+
+	next, err := RaceRoom.update(room.Id, func(room *models.RaceRoom) error {
+		if room.Status != 2 || room.CurrentRound != seq || room.RoundList[seq-1].Status != 2 {
+			return errors.New("房间状态已变化")
+		}
+
+		if roundErr != nil {
+			room.Status = 3
+			return nil
+		}
+
+		RaceRoom.nextRound(room, round)
+
+		return nil
+	})
+	if err != nil {
+		current, err := RaceRoom.get(room.Id)
+		if err != nil {
+			room.Status = 4
+			return room
+		}
+
+		return current
+	}
+
+	if next.Status == 2 {
+		RaceRoom.startTimer(&next)
+	}
+
+	return next
+}
+
+// timeout 轮次超时后强制结算
+func (RaceRoomImpl) timeout(id string, seq int) {
+	var settled bool
+	room, err := RaceRoom.update(id, func(room *models.RaceRoom) error {
+		settled = false
+
+		if room.Status != 2 || room.CurrentRound != seq {
+			return nil
+		}
+
+		settled = RaceRoom.settleRound(room, true)
+
+		return nil
+	})
+	if err != nil || !settled {
+		return
+	}
+
+	if settled {
+		room = RaceRoom.advance(room)
+	}
+
+	RaceRoom.broadcast(&room, settled)
+}
+
+// broadcast 向房间成员推送房间状态, settled 为 true 时先推送已结算轮次的成绩
+// 依次为 race-round-result、race-round(新的轮次) 或 race-finish(最终积分), 最后推送 race-room
+func (RaceRoomImpl) broadcast(room *models.RaceRoom, settled bool) {
+	groupId := RaceRoom.groupId(room.Id)
+
+	if room.Status == 4 {
+		websocket.SendToGroup(groupId, "race-closed", string(marshalContent(models.RaceRoomReq{Id: room.Id})))
+		websocket.DeleteGroup(groupId)
+		return
+	}
+
+	if settled {
+		previous := room.CurrentRound
+		if room.Status == 2 {
+			previous--
+		}
+
+		websocket.SendToGroup(groupId, "race-round-result", string(marshalContent(room.RoundList[previous-1])))
+	}
+
+	switch {
+	case settled && room.Status == 2:
+		websocket.SendToGroup(groupId, "race-round", string(marshalContent(room.RoundList[room.CurrentRound-1])))
+	case settled && room.Status == 3:
+		websocket.SendToGroup(groupId, "race-finish", string(marshalContent(room.Players)))
+	}
+
+	websocket.SendToGroup(groupId, "race-room", string(marshalContent(room)))
+}
+
+// marshalContent 序列化推送内容, 推送的模型均可序列化, 忽略错误
+func marshalContent(v any) []byte {
+	value, _ := json.Marshal(v)
+	return value
+}
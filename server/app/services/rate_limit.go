@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"puzzle/database"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// rateLimitScript 计数加一, 计数没有过期时间时设置计数周期
+// 在同一个脚本中执行, 避免设置过期时间失败后计数永不过期
+var rateLimitScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if redis.call('TTL', KEYS[1]) < 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// incrRateLimit 增加频率计数并返回计数周期内的次数
+func incrRateLimit(key string, window time.Duration) (int64, error) {
+	return rateLimitScript.Run(context.Background(), database.GetRedis(), []string{key}, int64(window/time.Second)).Int64()
+}
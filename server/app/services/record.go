@@ -1,69 +1,363 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"puzzle/app/middlewares/rabbitmq/handlers"
 	"puzzle/app/models"
-
 	"puzzle/database"
+	"puzzle/puzzle"
 	"puzzle/utils"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRecordDuplicate = errors.New("该打乱已提交过记录, 请勿重复提交")
+	ErrRecordReplay    = errors.New("打乱不属于当前用户或已完成, 请获取新的打乱")
+	ErrRecordVerify    = errors.New("参数错误!")
+	ErrRecordInvalid   = errors.New("记录参数不合法")
+	ErrRecordBanned    = errors.New("账号已被封禁, 无法提交记录")
 )
 
+// recordInvalidError 记录参数校验失败, 相同的请求重试也会得到相同的结果
+// 保留具体的错误信息, 同时可以用 errors.Is(err, ErrRecordInvalid) 判断
+type recordInvalidError struct {
+	msg string
+}
+
+func (e recordInvalidError) Error() string {
+	return e.msg
+}
+
+func (recordInvalidError) Is(target error) bool {
+	return target == ErrRecordInvalid
+}
+
+// newRecordInvalidError 创建记录参数校验失败的错误
+func newRecordInvalidError(msg string) error {
+	return recordInvalidError{msg: msg}
+}
+
+// unrankedRecordTypes 不计入最佳记录的记录类型
+// 1:练习 4:打乱组 5:挑战 6:盲拧 7:好友挑战
+var unrankedRecordTypes = []int{1, 4, 5, 6, 7}
+
 type RecordService interface {
+	normalize(record *models.Record)
 	check(record *models.Record) error
+	checkUser(record *models.Record) error
+	checkDuplicate(record *models.Record) (bool, error)
+	checkScrambleOwner(record *models.Record) (int64, error)
+	checkChallenge(record *models.Record) error
+	checkFriendChallenge(record *models.Record) error
+	verify(record *models.Record) error
+	Fingerprint(record *models.Record) string
 	Insert(record *models.Record) error
 	List(recordReq *models.RecordReq) (models.RecordListResp, error)
 	GetRecordByIds(recordIds []int64) (models.RecordListResp, error)
 	Update(record *models.Record) error
-	updateRecordBestSingle(record *models.Record) error
-	updateRecordBestAverage5(record *models.Record) error
-	updateRecordBestAverage12(record *models.Record) error
-	updateRecordBestStep(record *models.Record) error
+	updateRecordBestSingle(tx *gorm.DB, record *models.Record, changes *[]recordBestChange) error
+	updateRecordBestAverage(tx *gorm.DB, record *models.Record, count int, changes *[]recordBestChange) error
+	updateRecordBestStep(tx *gorm.DB, record *models.Record, changes *[]recordBestChange) error
+	updateRecordBestBlindfold(tx *gorm.DB, record *models.Record, changes *[]recordBestChange) error
 	publishNotification(userId int64, content string) error
+	RecomputeBest(userId int64, width, height int) error
+	recomputeBestSingle(userId int64, width, height int, records []models.Record) error
+	recomputeBestStep(userId int64, width, height int, records []models.Record) error
+	recomputeBestAverage(userId int64, width, height int, count int, records []models.Record) error
+	recomputeBestBlindfold(userId int64, width, height int, records []models.Record) error
 }
 
 type RecordImpl struct{}
 
+// normalize 规范化记录参数, 新增与计算请求指纹前调用, 保证等价的请求得到相同的记录
+func (RecordImpl) normalize(record *models.Record) {
+	// 兼容只传阶数的方形记录
+	record.Dimension, record.Width, record.Height = utils.NormalizeSize(record.Dimension, record.Width, record.Height)
+
+	if record.SessionIdStr != "" {
+		record.SessionId, _ = strconv.ParseInt(record.SessionIdStr, 10, 64)
+	}
+
+	// 只有打乱组记录关联打乱组挑战
+	if record.Type != 4 {
+		record.SessionId = 0
+	}
+
+	if record.ChallengeIdStr != "" {
+		record.ChallengeId, _ = strconv.ParseInt(record.ChallengeIdStr, 10, 64)
+	}
+
+	// 只有挑战记录关联原记录
+	if record.Type != 5 {
+		record.ChallengeId = 0
+	}
+
+	// 盲拧分别记录记忆与执行耗时, 总耗时为两者之和
+	if record.Type == 6 {
+		record.Duration = record.MemoDuration + record.ExecDuration
+	} else {
+		record.MemoDuration = 0
+		record.ExecDuration = 0
+	}
+}
+
 // check 检查参数
 func (RecordImpl) check(record *models.Record) error {
 	if record.UserId == 0 {
-		return errors.New("用户ID不能为空")
+		return newRecordInvalidError("用户ID不能为空")
+	}
+
+	Record.normalize(record)
+
+	if record.Width == 0 || record.Height == 0 {
+		return newRecordInvalidError("尺寸不能为空")
 	}
 
-	if record.Dimension == 0 {
-		return errors.New("阶数不能为空")
+	if record.Width > maxScrambleSize || record.Height > maxScrambleSize {
+		return newRecordInvalidError("尺寸不合法")
 	}
 
 	if record.Type == 0 {
-		return errors.New("类型不能为空")
+		return newRecordInvalidError("类型不能为空")
+	}
+
+	if record.Type == 4 && record.SessionId == 0 {
+		return newRecordInvalidError("打乱组挑战ID不能为空")
+	}
+
+	if record.Type == 5 && record.ChallengeId == 0 {
+		return newRecordInvalidError("挑战的记录ID不能为空")
+	}
+
+	if record.Type == 6 && (record.MemoDuration <= 0 || record.ExecDuration <= 0) {
+		return newRecordInvalidError("记忆耗时与执行耗时不能为空")
 	}
 
 	if record.Duration == 0 {
-		return errors.New("时长不能为空")
+		return newRecordInvalidError("时长不能为空")
 	}
 
 	if record.Step == 0 {
-		return errors.New("步数不能为空")
+		return newRecordInvalidError("步数不能为空")
 	}
 
 	if record.Scramble == "" {
-		return errors.New("打乱公式不能为空")
+		return newRecordInvalidError("打乱公式不能为空")
 	}
 
 	if record.Solution == "" {
-		return errors.New("解法不能为空")
+		return newRecordInvalidError("解法不能为空")
+	}
+
+	// 回放的每一步耗时需要与步数一致
+	if record.MoveTimes != "" {
+		if _, err := Ghost.parseMoveTimes(record.MoveTimes, record.Step, record.Duration); err != nil {
+			return newRecordInvalidError(err.Error())
+		}
 	}
 
 	if record.Idx == 0 {
-		return errors.New("打乱随机数不能为空")
+		return newRecordInvalidError("打乱随机数不能为空")
+	}
+
+	return nil
+}
+
+// checkDuplicate 检查同一用户同一打乱同一类型的记录是否已存在, 内容相同的重复提交返回 true 并填充已存在的记录
+func (RecordImpl) checkDuplicate(record *models.Record) (bool, error) {
+	var existing models.Record
+
+	err := database.GetMySQL().Table("record").
+		Where("user_id = ? AND idx = ? AND type = ?", record.UserId, record.Idx, record.Type).
+		Limit(1).
+		Find(&existing).Error
+	if err != nil {
+		return false, errors.New("查询记录失败")
+	}
+
+	if existing.Id == 0 {
+		return false, nil
+	}
+
+	// 内容相同的重复提交(如响应丢失后的重试)视为成功, 返回已存在的记录
+	if existing.Scramble == record.Scramble && existing.Solution == record.Solution &&
+		existing.Duration == record.Duration && existing.Step == record.Step {
+		*record = existing
+		return true, nil
+	}
+
+	return false, ErrRecordDuplicate
+}
+
+// checkUser 检查记录所属用户是否为启用状态, 封禁的用户不能提交记录
+func (RecordImpl) checkUser(record *models.Record) error {
+	var user models.User
+	err := database.GetMySQL().Table("user").Select("id", "status").Where("id = ?", record.UserId).First(&user).Error
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if user.Status != 1 {
+		return ErrRecordBanned
 	}
 
 	return nil
 }
 
+// checkScrambleOwner 检查非练习记录的打乱是否为用户当前未完成的打乱, 返回用户打乱状态ID
+// 记录的打乱生成器版本以下发的打乱为准
+func (RecordImpl) checkScrambleOwner(record *models.Record) (int64, error) {
+	// 盲拧记录使用单独下发的盲拧打乱
+	event := 1
+	if record.Type == 6 {
+		event = 2
+	}
+
+	scrambledUserStatus, err := ScrambledUserStatus.List(&models.ScrambledUserStatusReq{
+		UserId:    record.UserId,
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+		SessionId: record.SessionId,
+		Event:     event,
+		Pagination: utils.Pagination{
+			PageSize: 1,
+			Page:     1,
+		}})
+
+	if err != nil {
+		return 0, errors.New("获取用户打乱状态失败")
+	}
+
+	// 没有打乱或打乱已完成
+	if scrambledUserStatus.Total == 0 || scrambledUserStatus.Records[0].Status == 2 {
+		return 0, ErrRecordReplay
+	}
+
+	scrambleList, err := Scramble.List(&models.ScrambleReq{
+		Id: scrambledUserStatus.Records[0].ScrambleId,
+	})
+	if err != nil {
+		return 0, errors.New("查询打乱公式失败")
+	}
+
+	// 提交的打乱与下发的打乱不一致
+	if scrambleList.Total == 0 || scrambleList.Records[0].Idx != record.Idx {
+		return 0, ErrRecordReplay
+	}
+
+	record.ScrambleVersion = scrambleList.Records[0].Version
+
+	id, _ := strconv.ParseInt(scrambledUserStatus.Records[0].Id, 10, 64)
+
+	return id, nil
+}
+
+// checkChallenge 检查挑战记录的打乱是否与原记录一致, 记录的打乱生成器版本以原记录为准
+func (RecordImpl) checkChallenge(record *models.Record) error {
+	origin, err := RecordChallenge.getRecord(record.ChallengeId, record.UserId)
+	if err != nil {
+		return err
+	}
+
+	if origin.Width != record.Width || origin.Height != record.Height ||
+		origin.Idx != record.Idx || origin.Scramble != record.Scramble {
+		return newRecordInvalidError("打乱与挑战的记录不一致")
+	}
+
+	record.ScrambleVersion = origin.ScrambleVersion
+
+	return nil
+}
+
+// checkFriendChallenge 检查好友挑战记录是否来自已结算的好友挑战, 记录的打乱生成器版本以好友挑战为准
+func (RecordImpl) checkFriendChallenge(record *models.Record) error {
+	var challenge models.FriendChallenge
+	err := database.GetMySQL().Table("friend_challenge").
+		Where("idx = ? AND status = ?", record.Idx, 2).
+		Where("(challenger_id = ? AND challenger_status = ? AND challenger_solution = ?) OR (opponent_id = ? AND opponent_status = ? AND opponent_solution = ?)",
+			record.UserId, 2, record.Solution, record.UserId, 2, record.Solution).
+		First(&challenge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return newRecordInvalidError("好友挑战不存在或未结束")
+	}
+	if err != nil {
+		return errors.New("查询好友挑战失败")
+	}
+
+	if challenge.Width != record.Width || challenge.Height != record.Height || challenge.Scramble != record.Scramble {
+		return newRecordInvalidError("打乱与好友挑战不一致")
+	}
+
+	record.ScrambleVersion = challenge.ScrambleVersion
+
+	return nil
+}
+
+// verify 根据打乱生成器版本校验打乱与解法
+func (RecordImpl) verify(record *models.Record) error {
+	// 练习记录的打乱由客户端使用旧版生成器生成
+	if record.ScrambleVersion == 0 {
+		record.ScrambleVersion = puzzle.VersionLegacy
+	}
+
+	encryptionParams := utils.EncryptionParams{
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+		RandomIdx: record.Idx,
+		StepCount: record.Step,
+		Scramble:  record.Scramble,
+		Solution:  record.Solution,
+		Version:   record.ScrambleVersion,
+	}
+
+	if !encryptionParams.VerifyScramble() {
+		return ErrRecordVerify
+	}
+
+	return nil
+}
+
+// Fingerprint 计算记录的请求指纹, 用于幂等键校验
+// 指纹基于规范化后的副本计算, 包含新增时保存的所有客户端字段, 字符串字段加引号避免拼接后产生歧义
+func (RecordImpl) Fingerprint(record *models.Record) string {
+	normalized := *record
+	Record.normalize(&normalized)
+
+	content := fmt.Sprintf("%d|%d|%d|%d|%d|%d|%d|%d|%d|%d|%d|%d|%d|%q|%q|%q",
+		normalized.UserId,
+		normalized.Dimension,
+		normalized.Width,
+		normalized.Height,
+		normalized.Type,
+		normalized.Idx,
+		normalized.Duration,
+		normalized.MemoDuration,
+		normalized.ExecDuration,
+		normalized.Step,
+		normalized.SessionId,
+		normalized.ChallengeId,
+		normalized.ScrambleVersion,
+		normalized.Scramble,
+		normalized.Solution,
+		normalized.MoveTimes,
+	)
+
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
+
 // Insert 新增记录
 func (RecordImpl) Insert(record *models.Record) error {
 	// 检查参数
@@ -72,65 +366,136 @@ func (RecordImpl) Insert(record *models.Record) error {
 		return err
 	}
 
-	snowflake := utils.Snowflake{}
-
-	record.Id = snowflake.NextVal() // 生成ID
-	record.Status = 1               // 默认状态为1
+	// 检查用户状态
+	err = Record.checkUser(record)
+	if err != nil {
+		return err
+	}
 
-	// 插入记录
-	err = database.GetMySQL().Create(record).Error
+	// 检查是否重复提交
+	resubmitted, err := Record.checkDuplicate(record)
 	if err != nil {
-		return errors.New("新增失败")
+		return err
 	}
 
-	// 若记录为非练习记录, 则需要更新用户的记录
-	if record.Type != 1 {
-		// 更新用户的完成状态
-		scrambledUserStatus, err := ScrambledUserStatus.List(&models.ScrambledUserStatusReq{
-			UserId:    record.UserId,
-			Dimension: record.Dimension,
-			Pagination: utils.Pagination{
-				PageSize: 1,
-				Page:     1,
-			}})
+	if resubmitted {
+		return nil
+	}
 
+	// 挑战记录的打乱来自原记录, 好友挑战记录的打乱来自好友挑战, 其他非练习记录需要校验打乱归属
+	var scrambledUserStatusId int64
+	if record.Type == 5 {
+		err = Record.checkChallenge(record)
+		if err != nil {
+			return err
+		}
+	} else if record.Type == 7 {
+		err = Record.checkFriendChallenge(record)
+		if err != nil {
+			return err
+		}
+	} else if record.Type != 1 {
+		scrambledUserStatusId, err = Record.checkScrambleOwner(record)
 		if err != nil {
-			return errors.New("获取用户打乱状态失败")
+			return err
 		}
+	}
 
-		id, _ := strconv.ParseInt(scrambledUserStatus.Records[0].Id, 10, 64)
+	// 校验打乱与解法
+	err = Record.verify(record)
+	if err != nil {
+		return err
+	}
 
-		err = ScrambledUserStatus.Update(&models.ScrambledUserStatus{
-			Id:     id,
-			Status: 2,
-		})
+	snowflake := utils.Snowflake{}
 
+	record.Id = snowflake.NextVal() // 生成ID
+	record.Status = 1               // 默认状态为1
+
+	// 记录、打乱完成状态与最佳记录在同一事务中更新, 排名与通知在事务提交后发布
+	changes := make([]recordBestChange, 0)
+	err = database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		// 插入记录
+		err := tx.Create(record).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrRecordDuplicate
+		}
 		if err != nil {
-			return err
+			return errors.New("新增失败")
+		}
+
+		// 若记录为下发打乱的记录, 则需要更新用户的完成状态
+		if record.Type != 1 && record.Type != 5 && record.Type != 7 {
+			result := tx.Table("scrambled_user_status").
+				Where("id = ? AND status = ?", scrambledUserStatusId, 1).
+				Update("status", 2)
+			if result.Error != nil {
+				return errors.New("更新打乱状态失败")
+			}
+
+			// 并发提交同一打乱时只有一个请求能完成该打乱
+			if result.RowsAffected == 0 {
+				return ErrRecordReplay
+			}
+		}
+
+		// 盲拧只计入最佳盲拧记录
+		if record.Type == 6 {
+			return Record.updateRecordBestBlindfold(tx, record, &changes)
+		}
+
+		// 打乱组和挑战的打乱对所有用户公开, 好友挑战的打乱由发起者指定, 不计入最佳记录
+		if slices.Contains(unrankedRecordTypes, record.Type) {
+			return nil
 		}
 
 		// 更新用户最佳单次记录
-		err = Record.updateRecordBestSingle(record)
+		err = Record.updateRecordBestSingle(tx, record, &changes)
 		if err != nil {
 			return err
 		}
 
-		// 更新用户最佳5次平均记录
-		err = Record.updateRecordBestAverage5(record)
-		if err != nil {
-			return err
+		// 更新用户最佳5次与12次平均记录
+		for _, count := range []int{5, 12} {
+			err = Record.updateRecordBestAverage(tx, record, count, &changes)
+			if err != nil {
+				return err
+			}
 		}
 
-		// 更新用户最佳12次平均记录
-		err = Record.updateRecordBestAverage12(record)
+		// 更新用户最佳步数记录
+		return Record.updateRecordBestStep(tx, record, &changes)
+	})
+	if errors.Is(err, ErrRecordDuplicate) {
+		// 与并发的请求重复提交, 内容相同时返回已存在的记录
+		resubmitted, err = Record.checkDuplicate(record)
 		if err != nil {
 			return err
 		}
 
-		// 更新用户最佳步数记录
-		err = Record.updateRecordBestStep(record)
+		if !resubmitted {
+			return ErrRecordDuplicate
+		}
+
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 自动标记可疑记录, 标记失败不影响记录的新增
+	err = RecordModeration.AutoFlag(record)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+
+	// 更新排名并通知用户打破了最佳记录, 失败不影响记录的新增
+	for _, change := range changes {
+		change.publish()
+
+		err = Record.publishNotification(record.UserId, change.notification)
 		if err != nil {
-			return err
+			fmt.Println(err.Error())
 		}
 	}
 
@@ -187,8 +552,14 @@ func (RecordImpl) List(recordReq *models.RecordReq) (models.RecordListResp, erro
 		db.Where("user_id = ?", recordReq.UserId)
 	}
 
-	if recordReq.Dimension != 0 {
-		db.Where("dimension = ?", recordReq.Dimension)
+	_, recordReq.Width, recordReq.Height = utils.NormalizeSize(recordReq.Dimension, recordReq.Width, recordReq.Height)
+
+	if recordReq.Width != 0 {
+		db.Where("width = ?", recordReq.Width)
+	}
+
+	if recordReq.Height != 0 {
+		db.Where("height = ?", recordReq.Height)
 	}
 
 	if recordReq.Type != 0 {
@@ -250,6 +621,31 @@ func (RecordImpl) List(recordReq *models.RecordReq) (models.RecordListResp, erro
 		return recordListResp, errors.New("查询失败")
 	}
 
+	// 查询打乱难度
+	if recordReq.NeedDifficulty && len(recordListResp.Records) > 0 {
+		keys := make([]models.ScrambleKey, len(recordListResp.Records))
+		for i, record := range recordListResp.Records {
+			idx, _ := strconv.ParseInt(record.Idx, 10, 64)
+			keys[i] = models.ScrambleKey{
+				Idx:     idx,
+				Width:   record.Width,
+				Height:  record.Height,
+				Version: record.ScrambleVersion,
+			}
+		}
+
+		difficultyMap, err := Scramble.GetDifficultyByKeys(keys)
+		if err != nil {
+			return recordListResp, err
+		}
+
+		for i, key := range keys {
+			if difficulty, ok := difficultyMap[key]; ok {
+				recordListResp.Records[i].Difficulty = &difficulty
+			}
+		}
+	}
+
 	return recordListResp, nil
 }
 
@@ -267,7 +663,8 @@ func (RecordImpl) GetRecordByIds(recordIds []int64) (models.RecordListResp, erro
 
 // Update 更新记录
 func (RecordImpl) Update(record *models.Record) error {
-	err := database.GetMySQL().Model(&record).Updates(&record).Error
+	// 记录状态只能通过审核修改, 以便重新计算最佳记录并保留审核日志
+	err := database.GetMySQL().Model(&record).Omit("status").Updates(&record).Error
 	if err != nil {
 		return errors.New("更新失败")
 	}
@@ -275,107 +672,98 @@ func (RecordImpl) Update(record *models.Record) error {
 	return nil
 }
 
-// updateRecordBestSingle 更新最佳单次记录
-func (RecordImpl) updateRecordBestSingle(record *models.Record) error {
-
-	// 获取最佳单次记录
-	recordBestSingle, err := RecordBestSingle.List(&models.RecordBestSingleReq{
-		UserId:    record.UserId,
-		Dimension: record.Dimension,
+// recordBestChange 新增记录打破的最佳记录, 事务提交后更新排名并通知用户
+type recordBestChange struct {
+	publish      func()
+	notification string
+}
 
-		Pagination: utils.Pagination{
-			Page:     1,
-			PageSize: 1,
-		},
-	})
+// updateRecordBestSingle 更新最佳单次记录
+func (RecordImpl) updateRecordBestSingle(tx *gorm.DB, record *models.Record, changes *[]recordBestChange) error {
+	// 获取最佳单次记录, 锁定到事务结束, 避免并发提交互相覆盖
+	var recordBestSingle models.RecordBestSingle
+	err := tx.Table("record_best_single").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND width = ? AND height = ?", record.UserId, record.Width, record.Height).
+		Limit(1).
+		Find(&recordBestSingle).Error
 	if err != nil {
 		return errors.New("获取最佳单次记录失败")
 	}
 
-	// 若有最佳单次记录, 且当前记录的持续时间大于最佳单次记录, 则直接返回(没有打破记录)
-	if recordBestSingle.Total > 0 && record.Duration > recordBestSingle.Records[0].RecordDuration {
+	// 若有最佳单次记录, 且当前记录的耗时不小于最佳单次记录, 则直接返回(没有打破记录)
+	if recordBestSingle.Id != 0 && record.Duration >= recordBestSingle.RecordDuration {
 		return nil
 	}
 
-	// 若无最佳单次记录, 则直接插入
-	if recordBestSingle.Total == 0 {
+	if recordBestSingle.Id == 0 {
+		// 若无最佳单次记录, 则直接插入
 		snowflake := utils.Snowflake{}
 
-		err = RecordBestSingle.Insert(&models.RecordBestSingle{
+		err = tx.Create(&models.RecordBestSingle{
 			Id:               snowflake.NextVal(),
 			UserId:           record.UserId,
 			Dimension:        record.Dimension,
+			Width:            record.Width,
+			Height:           record.Height,
 			RecordId:         record.Id,
 			RecordDuration:   record.Duration,
 			RecordStep:       record.Step,
 			RecordBreakCount: 1,
-		})
-
+		}).Error
 		if err != nil {
 			return errors.New("新增最佳单次记录失败")
 		}
-
 	} else {
-		// 若有最佳单次记录, 则比较并更新
-		if record.Duration < recordBestSingle.Records[0].RecordDuration {
-			id, _ := strconv.ParseInt(recordBestSingle.Records[0].Id, 10, 64)
-
-			err = RecordBestSingle.Update(&models.RecordBestSingle{
-				Id:               id,
-				UserId:           record.UserId,
-				RecordId:         record.Id,
-				Dimension:        record.Dimension,
-				RecordDuration:   record.Duration,
-				RecordStep:       record.Step,
-				RecordBreakCount: recordBestSingle.Records[0].RecordBreakCount + 1,
-			})
-
-			if err != nil {
-				return errors.New("更新最佳单次记录失败")
-			}
+		err = tx.Table("record_best_single").Where("id = ?", recordBestSingle.Id).Updates(&models.RecordBestSingle{
+			RecordId:         record.Id,
+			RecordDuration:   record.Duration,
+			RecordStep:       record.Step,
+			RecordBreakCount: recordBestSingle.RecordBreakCount + 1,
+		}).Error
+		if err != nil {
+			return errors.New("更新最佳单次记录失败")
 		}
 	}
 
-	// 发布通知
-	err = Record.publishNotification(record.UserId, fmt.Sprintf("恭喜您打破了 %d 阶最佳单次记录, 用时 %.3f 秒, 步数 %d, 排名可前往排行榜查看", record.Dimension, float64(record.Duration)/1000, record.Step))
-	if err != nil {
-		return err
-	}
+	*changes = append(*changes, recordBestChange{
+		publish: func() {
+			RecordBestSingle.publishMessage(handlers.RankUpdate{
+				Dimension: record.Dimension,
+				Width:     record.Width,
+				Height:    record.Height,
+			})
+		},
+		notification: fmt.Sprintf("恭喜您打破了 %s 最佳单次记录, 用时 %.3f 秒, 步数 %d, 排名可前往排行榜查看", utils.SizeLabel(record.Width, record.Height), float64(record.Duration)/1000, record.Step),
+	})
 
 	return nil
 }
 
-// updateRecordBestAverag5e 更新最佳五次平均记录
-func (RecordImpl) updateRecordBestAverage5(record *models.Record) error {
-
-	// 获取用户最近5条记录
-	last5Records, err := Record.List(&models.RecordReq{
-		UserId:    record.UserId,
-		Dimension: record.Dimension,
-		Type:      record.Type,
-		Status:    1,
-		Pagination: utils.Pagination{
-			Page:     1,
-			PageSize: 5,
-		},
-		Sorted: "desc",
-	})
-
+// updateRecordBestAverage 更新最佳平均记录, count 为平均的次数(5 或 12)
+func (RecordImpl) updateRecordBestAverage(tx *gorm.DB, record *models.Record, count int, changes *[]recordBestChange) error {
+	// 获取用户最近的记录, 包含本次新增的记录
+	var lastRecords []models.Record
+	err := tx.Table("record").
+		Where("user_id = ? AND width = ? AND height = ? AND type = ? AND status = ?", record.UserId, record.Width, record.Height, record.Type, 1).
+		Order("id desc").
+		Limit(count).
+		Find(&lastRecords).Error
 	if err != nil {
-		return errors.New("获取最近5条记录失败")
+		return fmt.Errorf("获取最近%d条记录失败", count)
 	}
 
-	// 若记录数小于5, 则无法计算平均记录
-	if last5Records.Total < 5 {
+	// 若记录数不足, 则无法计算平均记录
+	if len(lastRecords) < count {
 		return nil
 	}
 
 	var totalDuration int
 
 	// 将记录的持续时间存储到一个切片中
-	durations := make([]int, 0, len(last5Records.Records))
-	for _, v := range last5Records.Records {
-		durations = append(durations, int(v.Duration))
+	durations := make([]int, 0, len(lastRecords))
+	for _, v := range lastRecords {
+		durations = append(durations, v.Duration)
 	}
 
 	// 对切片进行排序
@@ -389,281 +777,558 @@ func (RecordImpl) updateRecordBestAverage5(record *models.Record) error {
 	// 计算平均值
 	averageDuration := totalDuration / (len(durations) - 2)
 
-	// 获取最佳平均记录
-	recordBestAverage, err := RecordBestAverage.List(&models.RecordBestAverageReq{
-		UserId:    record.UserId,
-		Dimension: record.Dimension,
-		Type:      5,
-		Pagination: utils.Pagination{
-			Page:     1,
-			PageSize: 1,
-		},
-	})
-
+	// 获取最佳平均记录, 锁定到事务结束, 避免并发提交互相覆盖
+	var recordBestAverage models.RecordBestAverage
+	err = tx.Table("record_best_average").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND width = ? AND height = ? AND type = ?", record.UserId, record.Width, record.Height, count).
+		Limit(1).
+		Find(&recordBestAverage).Error
 	if err != nil {
 		return errors.New("获取最佳平均记录失败")
 	}
 
-	// 整合最近5条记录id
-	var recordIds []string
-	for _, v := range last5Records.Records {
-		recordIds = append(recordIds, v.Id)
+	// 若有最佳平均记录, 且当前记录的平均耗时不小于最佳平均记录, 则直接返回(没有打破记录)
+	if recordBestAverage.Id != 0 && averageDuration >= recordBestAverage.RecordAverageDuration {
+		return nil
 	}
 
-	recordIdsStr := strings.Trim(strings.Join(strings.Fields(fmt.Sprint(recordIds)), ","), "[]")
-
-	// 若有最佳平均记录, 且当前记录的平均持续时间大于最佳平均记录, 则直接返回(没有打破记录)
-	if recordBestAverage.Total > 0 && averageDuration > recordBestAverage.Records[0].RecordAverageDuration {
-		return nil
+	// 整合最近的记录id
+	recordIds := make([]string, 0, len(lastRecords))
+	for _, v := range lastRecords {
+		recordIds = append(recordIds, strconv.FormatInt(v.Id, 10))
 	}
 
-	// 若无最佳平均记录, 则直接插入
-	if recordBestAverage.Total == 0 {
+	if recordBestAverage.Id == 0 {
+		// 若无最佳平均记录, 则直接插入
 		snowflake := utils.Snowflake{}
 
-		err = RecordBestAverage.Insert(&models.RecordBestAverage{
+		err = tx.Create(&models.RecordBestAverage{
 			Id:                    snowflake.NextVal(),
 			UserId:                record.UserId,
 			Dimension:             record.Dimension,
-			Type:                  5,
-			RecordIds:             recordIdsStr,
+			Width:                 record.Width,
+			Height:                record.Height,
+			Type:                  count,
+			RecordIds:             strings.Join(recordIds, ","),
 			RecordAverageDuration: averageDuration,
 			RecordBreakCount:      1,
-		})
-
+		}).Error
 		if err != nil {
 			return errors.New("新增最佳平均记录失败")
 		}
 	} else {
+		err = tx.Table("record_best_average").Where("id = ?", recordBestAverage.Id).Updates(&models.RecordBestAverage{
+			RecordIds:             strings.Join(recordIds, ","),
+			RecordAverageDuration: averageDuration,
+			RecordBreakCount:      recordBestAverage.RecordBreakCount + 1,
+		}).Error
+		if err != nil {
+			return errors.New("更新最佳平均记录失败")
+		}
+	}
 
-		id, _ := strconv.ParseInt(recordBestAverage.Records[0].Id, 10, 64)
-
-		// 若有最佳平均记录, 则比较并更新
-		if averageDuration < recordBestAverage.Records[0].RecordAverageDuration {
-			err = RecordBestAverage.Update(&models.RecordBestAverage{
-				Id:                    id,
-				RecordIds:             recordIdsStr,
-				Dimension:             record.Dimension,
-				UserId:                record.UserId,
-				Type:                  5,
-				RecordAverageDuration: averageDuration,
-				RecordBreakCount:      recordBestAverage.Records[0].RecordBreakCount + 1,
+	*changes = append(*changes, recordBestChange{
+		publish: func() {
+			RecordBestAverage.publishMessage(handlers.RankUpdate{
+				Dimension: record.Dimension,
+				Width:     record.Width,
+				Height:    record.Height,
+				Type:      count,
 			})
+		},
+		notification: fmt.Sprintf("恭喜您打破了 %s 最佳%d次平均记录, 平均用时 %.3f 秒, 排名可前往排行榜查看", utils.SizeLabel(record.Width, record.Height), count, float64(averageDuration)/1000),
+	})
 
-			if err != nil {
-				return errors.New("更新最佳平均记录失败")
-			}
+	return nil
+}
+
+// updateRecordBestStep 更新最佳步数记录
+func (RecordImpl) updateRecordBestStep(tx *gorm.DB, record *models.Record, changes *[]recordBestChange) error {
+	// 获取用户最佳步数记录, 锁定到事务结束, 避免并发提交互相覆盖
+	var recordBestStep models.RecordBestStep
+	err := tx.Table("record_best_step").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND width = ? AND height = ?", record.UserId, record.Width, record.Height).
+		Limit(1).
+		Find(&recordBestStep).Error
+	if err != nil {
+		return errors.New("获取最佳步数记录失败")
+	}
+
+	// 若有最佳步数记录, 且当前记录的步数不小于最佳步数记录, 则直接返回(没有打破记录)
+	if recordBestStep.Id != 0 && record.Step >= recordBestStep.RecordStep {
+		return nil
+	}
+
+	if recordBestStep.Id == 0 {
+		// 若无最佳步数记录, 则直接插入
+		snowflake := utils.Snowflake{}
+
+		err = tx.Create(&models.RecordBestStep{
+			Id:               snowflake.NextVal(),
+			UserId:           record.UserId,
+			Dimension:        record.Dimension,
+			Width:            record.Width,
+			Height:           record.Height,
+			RecordId:         record.Id,
+			RecordStep:       record.Step,
+			RecordBreakCount: 1,
+		}).Error
+		if err != nil {
+			return errors.New("新增最佳步数记录失败")
+		}
+	} else {
+		err = tx.Table("record_best_step").Where("id = ?", recordBestStep.Id).Updates(&models.RecordBestStep{
+			RecordId:         record.Id,
+			RecordStep:       record.Step,
+			RecordBreakCount: recordBestStep.RecordBreakCount + 1,
+		}).Error
+		if err != nil {
+			return errors.New("更新最佳步数记录失败")
 		}
 	}
 
-	// 发布通知
-	err = Record.publishNotification(record.UserId, fmt.Sprintf("恭喜您打破了 %d 阶最佳5次平均记录, 平均用时 %.3f 秒, 排名可前往排行榜查看", record.Dimension, float64(averageDuration)/1000))
+	*changes = append(*changes, recordBestChange{
+		publish: func() {
+			RecordBestStep.publishMessage(handlers.RankUpdate{
+				Dimension: record.Dimension,
+				Width:     record.Width,
+				Height:    record.Height,
+			})
+		},
+		notification: fmt.Sprintf("恭喜您打破了 %s 最佳步数记录, 步数 %d 步, 排名可前往排行榜查看", utils.SizeLabel(record.Width, record.Height), record.Step),
+	})
+
+	return nil
+}
+
+// updateRecordBestBlindfold 更新最佳盲拧记录
+func (RecordImpl) updateRecordBestBlindfold(tx *gorm.DB, record *models.Record, changes *[]recordBestChange) error {
+	// 获取最佳盲拧记录, 锁定到事务结束, 避免并发提交互相覆盖
+	var recordBestBlindfold models.RecordBestBlindfold
+	err := tx.Table("record_best_blindfold").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND width = ? AND height = ?", record.UserId, record.Width, record.Height).
+		Limit(1).
+		Find(&recordBestBlindfold).Error
 	if err != nil {
-		return err
+		return errors.New("获取最佳盲拧记录失败")
+	}
+
+	// 若有最佳盲拧记录, 且当前记录的总耗时不小于最佳盲拧记录, 则直接返回(没有打破记录)
+	if recordBestBlindfold.Id != 0 && record.Duration >= recordBestBlindfold.RecordDuration {
+		return nil
 	}
 
+	if recordBestBlindfold.Id == 0 {
+		// 若无最佳盲拧记录, 则直接插入
+		snowflake := utils.Snowflake{}
+
+		err = tx.Create(&models.RecordBestBlindfold{
+			Id:                 snowflake.NextVal(),
+			UserId:             record.UserId,
+			Dimension:          record.Dimension,
+			Width:              record.Width,
+			Height:             record.Height,
+			RecordId:           record.Id,
+			RecordDuration:     record.Duration,
+			RecordMemoDuration: record.MemoDuration,
+			RecordExecDuration: record.ExecDuration,
+			RecordStep:         record.Step,
+			RecordBreakCount:   1,
+		}).Error
+		if err != nil {
+			return errors.New("新增最佳盲拧记录失败")
+		}
+	} else {
+		err = tx.Table("record_best_blindfold").Where("id = ?", recordBestBlindfold.Id).Updates(&models.RecordBestBlindfold{
+			RecordId:           record.Id,
+			RecordDuration:     record.Duration,
+			RecordMemoDuration: record.MemoDuration,
+			RecordExecDuration: record.ExecDuration,
+			RecordStep:         record.Step,
+			RecordBreakCount:   recordBestBlindfold.RecordBreakCount + 1,
+		}).Error
+		if err != nil {
+			return errors.New("更新最佳盲拧记录失败")
+		}
+	}
+
+	*changes = append(*changes, recordBestChange{
+		publish: func() {
+			RecordBestBlindfold.publishMessage(handlers.RankUpdate{
+				Dimension: record.Dimension,
+				Width:     record.Width,
+				Height:    record.Height,
+			})
+		},
+		notification: fmt.Sprintf("恭喜您打破了 %s 最佳盲拧记录, 用时 %.3f 秒(记忆 %.3f 秒), 排名可前往排行榜查看", utils.SizeLabel(record.Width, record.Height), float64(record.Duration)/1000, float64(record.MemoDuration)/1000),
+	})
+
 	return nil
 }
 
-// updateRecordBestAverage12 更新最佳12次平均记录
-func (RecordImpl) updateRecordBestAverage12(record *models.Record) error {
-	// 获取用户最近12条记录
-	last12Records, err := Record.List(&models.RecordReq{
-		UserId:    record.UserId,
-		Dimension: record.Dimension,
-		Type:      record.Type,
-		Status:    1,
-		Pagination: utils.Pagination{
-			Page:     1,
-			PageSize: 12,
-		},
-		Sorted: "desc",
+// publishNotification 发布通知
+func (RecordImpl) publishNotification(userId int64, content string) error {
+
+	err := Notification.Insert(&models.NotificationReq{
+		UserId:  userId,
+		TypeId:  1,
+		Content: content,
 	})
 
 	if err != nil {
-		return errors.New("获取最近12条记录失败")
+		return errors.New("发布通知失败")
+	}
+
+	return nil
+}
+
+// RecomputeBest 根据用户现存的有效记录重新计算最佳记录, 用于记录被冻结或恢复之后
+func (RecordImpl) RecomputeBest(userId int64, width, height int) error {
+	// 封禁用户的最佳记录在封禁时已移除, 不再重新计算
+	var user models.User
+	err := database.GetMySQL().Table("user").Select("id", "status").Where("id = ?", userId).First(&user).Error
+	if err != nil {
+		return errors.New("用户不存在")
 	}
 
-	// 若记录数小于12, 则无法计算平均记录
-	if last12Records.Total < 12 {
+	if user.Status != 1 {
 		return nil
 	}
 
-	var totalDuration int
+	// 按时间顺序获取用户该尺寸下所有计入最佳记录的有效记录
+	var records []models.Record
+	err = database.GetMySQL().Table("record").
+		Where("user_id = ? AND width = ? AND height = ? AND type NOT IN ? AND status = ?", userId, width, height, unrankedRecordTypes, 1).
+		Order("id asc").
+		Find(&records).Error
+	if err != nil {
+		return errors.New("查询用户记录失败")
+	}
 
-	// 将记录的持续时间存储到一个切片中
-	durations := make([]int, 0, len(last12Records.Records))
-	for _, v := range last12Records.Records {
-		durations = append(durations, int(v.Duration))
+	err = Record.recomputeBestSingle(userId, width, height, records)
+	if err != nil {
+		return err
 	}
 
-	// 对切片进行排序
-	sort.Ints(durations)
+	err = Record.recomputeBestStep(userId, width, height, records)
+	if err != nil {
+		return err
+	}
 
-	// 去掉最大和最小值
-	for i := 1; i < len(durations)-1; i++ {
-		totalDuration += durations[i]
+	for _, count := range []int{5, 12} {
+		err = Record.recomputeBestAverage(userId, width, height, count, records)
+		if err != nil {
+			return err
+		}
 	}
 
-	// 计算平均值
-	averageDuration := totalDuration / (len(durations) - 2)
+	// 盲拧记录单独计算
+	var blindfoldRecords []models.Record
+	err = database.GetMySQL().Table("record").
+		Where("user_id = ? AND width = ? AND height = ? AND type = ? AND status = ?", userId, width, height, 6, 1).
+		Order("id asc").
+		Find(&blindfoldRecords).Error
+	if err != nil {
+		return errors.New("查询用户记录失败")
+	}
 
-	// 获取最佳平均记录
-	recordBestAverage, err := RecordBestAverage.List(&models.RecordBestAverageReq{
-		UserId:    record.UserId,
-		Dimension: record.Dimension,
-		Type:      12,
+	return Record.recomputeBestBlindfold(userId, width, height, blindfoldRecords)
+}
+
+// recomputeBestSingle 重新计算最佳单次记录
+func (RecordImpl) recomputeBestSingle(userId int64, width, height int, records []models.Record) error {
+	dimension, _, _ := utils.NormalizeSize(0, width, height)
+
+	current, err := RecordBestSingle.List(&models.RecordBestSingleReq{
+		UserId:    userId,
+		Dimension: dimension,
+		Width:     width,
+		Height:    height,
 		Pagination: utils.Pagination{
 			Page:     1,
 			PageSize: 1,
 		},
 	})
-
 	if err != nil {
-		return errors.New("获取最佳平均记录失败")
+		return errors.New("获取最佳单次记录失败")
 	}
 
-	// 整合最近12条记录id
-	var recordIds []string
-	for _, v := range last12Records.Records {
-		recordIds = append(recordIds, v.Id)
+	var best *models.Record
+	for i := range records {
+		if best == nil || records[i].Duration < best.Duration {
+			best = &records[i]
+		}
 	}
 
-	recordIdsStr := strings.Trim(strings.Join(strings.Fields(fmt.Sprint(recordIds)), ","), "[]")
+	// 没有有效记录, 删除最佳单次记录
+	if best == nil {
+		if current.Total == 0 {
+			return nil
+		}
 
-	// 若有最佳平均记录, 且当前记录的平均持续时间大于最佳平均记录, 则直接返回(没有打破记录)
-	if recordBestAverage.Total > 0 && averageDuration > recordBestAverage.Records[0].RecordAverageDuration {
-		return nil
+		id, _ := strconv.ParseInt(current.Records[0].Id, 10, 64)
+
+		return RecordBestSingle.Delete(&models.RecordBestSingle{Id: id, Dimension: dimension, Width: width, Height: height})
 	}
 
-	// 若无最佳平均记录, 则直接插入
-	if recordBestAverage.Total == 0 {
+	if current.Total == 0 {
 		snowflake := utils.Snowflake{}
 
-		err = RecordBestAverage.Insert(&models.RecordBestAverage{
-			Id:                    snowflake.NextVal(),
-			UserId:                record.UserId,
-			Dimension:             record.Dimension,
-			Type:                  12,
-			RecordIds:             recordIdsStr,
-			RecordAverageDuration: averageDuration,
-			RecordBreakCount:      1,
+		return RecordBestSingle.Insert(&models.RecordBestSingle{
+			Id:               snowflake.NextVal(),
+			UserId:           userId,
+			Dimension:        dimension,
+			Width:            width,
+			Height:           height,
+			RecordId:         best.Id,
+			RecordDuration:   best.Duration,
+			RecordStep:       best.Step,
+			RecordBreakCount: 1,
 		})
+	}
 
-		if err != nil {
-			return errors.New("新增最佳平均记录失败")
-		}
-	} else {
+	id, _ := strconv.ParseInt(current.Records[0].Id, 10, 64)
+
+	return RecordBestSingle.Update(&models.RecordBestSingle{
+		Id:             id,
+		UserId:         userId,
+		Dimension:      dimension,
+		Width:          width,
+		Height:         height,
+		RecordId:       best.Id,
+		RecordDuration: best.Duration,
+		RecordStep:     best.Step,
+	})
+}
 
-		id, _ := strconv.ParseInt(recordBestAverage.Records[0].Id, 10, 64)
-
-		// 若有最佳平均记录, 则比较并更新
-		if averageDuration < recordBestAverage.Records[0].RecordAverageDuration {
-			err = RecordBestAverage.Update(&models.RecordBestAverage{
-				Id:                    id,
-				RecordIds:             recordIdsStr,
-				Dimension:             record.Dimension,
-				UserId:                record.UserId,
-				Type:                  12,
-				RecordAverageDuration: averageDuration,
-				RecordBreakCount:      recordBestAverage.Records[0].RecordBreakCount + 1,
-			})
+// recomputeBestStep 重新计算最佳步数记录
+func (RecordImpl) recomputeBestStep(userId int64, width, height int, records []models.Record) error {
+	dimension, _, _ := utils.NormalizeSize(0, width, height)
 
-			if err != nil {
-				return errors.New("更新最佳平均记录失败")
-			}
+	current, err := RecordBestStep.List(&models.RecordBestStepReq{
+		UserId:    userId,
+		Dimension: dimension,
+		Width:     width,
+		Height:    height,
+		Pagination: utils.Pagination{
+			Page:     1,
+			PageSize: 1,
+		},
+	})
+	if err != nil {
+		return errors.New("获取最佳步数记录失败")
+	}
+
+	var best *models.Record
+	for i := range records {
+		if best == nil || records[i].Step < best.Step {
+			best = &records[i]
 		}
 	}
 
-	// 发布通知
-	err = Record.publishNotification(record.UserId, fmt.Sprintf("恭喜您打破了 %d 阶最佳12次平均记录, 平均用时 %.3f 秒, 排名可前往排行榜查看", record.Dimension, float64(averageDuration)/1000))
-	if err != nil {
-		return err
+	// 没有有效记录, 删除最佳步数记录
+	if best == nil {
+		if current.Total == 0 {
+			return nil
+		}
+
+		id, _ := strconv.ParseInt(current.Records[0].Id, 10, 64)
+
+		return RecordBestStep.Delete(&models.RecordBestStep{Id: id, Dimension: dimension, Width: width, Height: height})
 	}
 
-	return nil
+	if current.Total == 0 {
+		snowflake := utils.Snowflake{}
+
+		return RecordBestStep.Insert(&models.RecordBestStep{
+			Id:               snowflake.NextVal(),
+			UserId:           userId,
+			Dimension:        dimension,
+			Width:            width,
+			Height:           height,
+			RecordId:         best.Id,
+			RecordStep:       best.Step,
+			RecordBreakCount: 1,
+		})
+	}
+
+	id, _ := strconv.ParseInt(current.Records[0].Id, 10, 64)
+
+	return RecordBestStep.Update(&models.RecordBestStep{
+		Id:         id,
+		UserId:     userId,
+		Dimension:  dimension,
+		Width:      width,
+		Height:     height,
+		RecordId:   best.Id,
+		RecordStep: best.Step,
+	})
 }
 
-// updateRecordBestStep 更新最佳步数记录
-func (RecordImpl) updateRecordBestStep(record *models.Record) error {
+// recomputeBestAverage 重新计算最佳平均记录, count为平均的次数
+func (RecordImpl) recomputeBestAverage(userId int64, width, height int, count int, records []models.Record) error {
+	dimension, _, _ := utils.NormalizeSize(0, width, height)
 
-	// 获取用户最佳步数记录
-	recordBestStep, err := RecordBestStep.List(&models.RecordBestStepReq{
-		UserId:    record.UserId,
-		Dimension: record.Dimension,
+	current, err := RecordBestAverage.List(&models.RecordBestAverageReq{
+		UserId:    userId,
+		Dimension: dimension,
+		Width:     width,
+		Height:    height,
+		Type:      count,
 		Pagination: utils.Pagination{
 			Page:     1,
 			PageSize: 1,
 		},
 	})
-
 	if err != nil {
-		return errors.New("获取最佳步数记录失败")
+		return errors.New("获取最佳平均记录失败")
 	}
 
-	// 若有最佳步数记录, 且当前记录的步数大于最佳步数记录, 则直接返回(没有打破记录)
-	if recordBestStep.Total > 0 && record.Step > recordBestStep.Records[0].RecordStep {
-		return nil
+	bestAverage := 0
+	bestRecordIds := ""
+
+	// 与新增记录时一致, 平均只在同一类型的连续记录中计算
+	recordsByType := make(map[int][]models.Record)
+	for _, record := range records {
+		recordsByType[record.Type] = append(recordsByType[record.Type], record)
 	}
 
-	// 若无最佳步数记录, 则直接插入
-	if recordBestStep.Total == 0 {
+	types := make([]int, 0, len(recordsByType))
+	for recordType := range recordsByType {
+		types = append(types, recordType)
+	}
+	sort.Ints(types)
 
-		snowflake := utils.Snowflake{}
+	for _, recordType := range types {
+		typeRecords := recordsByType[recordType]
 
-		err = RecordBestStep.Insert(&models.RecordBestStep{
-			Id:               snowflake.NextVal(),
-			UserId:           record.UserId,
-			Dimension:        record.Dimension,
-			RecordId:         record.Id,
-			RecordStep:       record.Step,
-			RecordBreakCount: 1,
-		})
+		// 滑动窗口计算每连续count条记录的去头尾平均值
+		for start := 0; start+count <= len(typeRecords); start++ {
+			window := typeRecords[start : start+count]
 
-		if err != nil {
-			return errors.New("新增最佳步数记录失败")
-		}
-	} else {
-		id, _ := strconv.ParseInt(recordBestStep.Records[0].Id, 10, 64)
-
-		// 若有最佳步数记录, 则比较并更新
-		if record.Step < recordBestStep.Records[0].RecordStep {
-			err = RecordBestStep.Update(&models.RecordBestStep{
-				Id:               id,
-				RecordId:         record.Id,
-				UserId:           record.UserId,
-				Dimension:        record.Dimension,
-				RecordStep:       record.Step,
-				RecordBreakCount: recordBestStep.Records[0].RecordBreakCount + 1,
-			})
+			durations := make([]int, 0, count)
+			for _, v := range window {
+				durations = append(durations, v.Duration)
+			}
+			sort.Ints(durations)
 
-			if err != nil {
-				return errors.New("更新最佳步数记录失败")
+			totalDuration := 0
+			for i := 1; i < len(durations)-1; i++ {
+				totalDuration += durations[i]
+			}
+			averageDuration := totalDuration / (len(durations) - 2)
+
+			if bestRecordIds == "" || averageDuration < bestAverage {
+				// 记录ID按时间倒序排列, 与新增记录时保持一致
+				recordIds := make([]string, 0, count)
+				for i := len(window) - 1; i >= 0; i-- {
+					recordIds = append(recordIds, strconv.FormatInt(window[i].Id, 10))
+				}
+
+				bestAverage = averageDuration
+				bestRecordIds = strings.Join(recordIds, ",")
 			}
 		}
 	}
 
-	// 发布通知
-	err = Record.publishNotification(record.UserId, fmt.Sprintf("恭喜您打破了 %d 阶最佳步数记录, 步数 %d 步, 排名可前往排行榜查看", record.Dimension, record.Step))
-	if err != nil {
-		return err
+	// 有效记录数不足, 删除最佳平均记录
+	if bestRecordIds == "" {
+		if current.Total == 0 {
+			return nil
+		}
+
+		id, _ := strconv.ParseInt(current.Records[0].Id, 10, 64)
+
+		return RecordBestAverage.Delete(&models.RecordBestAverage{Id: id, Dimension: dimension, Width: width, Height: height, Type: count})
 	}
 
-	return nil
-}
+	if current.Total == 0 {
+		snowflake := utils.Snowflake{}
 
-// publishNotification 发布通知
-func (RecordImpl) publishNotification(userId int64, content string) error {
+		return RecordBestAverage.Insert(&models.RecordBestAverage{
+			Id:                    snowflake.NextVal(),
+			UserId:                userId,
+			Dimension:             dimension,
+			Width:                 width,
+			Height:                height,
+			Type:                  count,
+			RecordIds:             bestRecordIds,
+			RecordAverageDuration: bestAverage,
+			RecordBreakCount:      1,
+		})
+	}
 
-	err := Notification.Insert(&models.NotificationReq{
-		UserId:  userId,
-		TypeId:  1,
-		Content: content,
+	return RecordBestAverage.Update(&models.RecordBestAverage{
+		UserId:                userId,
+		Dimension:             dimension,
+		Width:                 width,
+		Height:                height,
+		Type:                  count,
+		RecordIds:             bestRecordIds,
+		RecordAverageDuration: bestAverage,
 	})
+}
+
+// recomputeBestBlindfold 重新计算最佳盲拧记录
+func (RecordImpl) recomputeBestBlindfold(userId int64, width, height int, records []models.Record) error {
+	dimension, _, _ := utils.NormalizeSize(0, width, height)
 
+	current, err := RecordBestBlindfold.List(&models.RecordBestBlindfoldReq{
+		UserId:    userId,
+		Dimension: dimension,
+		Width:     width,
+		Height:    height,
+		Pagination: utils.Pagination{
+			Page:     1,
+			PageSize: 1,
+		},
+	})
 	if err != nil {
-		return errors.New("发布通知失败")
+		return errors.New("获取最佳盲拧记录失败")
 	}
 
-	return nil
+	var best *models.Record
+	for i := range records {
+		if best == nil || records[i].Duration < best.Duration {
+			best = &records[i]
+		}
+	}
+
+	// 没有有效记录, 删除最佳盲拧记录
+	if best == nil {
+		if current.Total == 0 {
+			return nil
+		}
+
+		id, _ := strconv.ParseInt(current.Records[0].Id, 10, 64)
+
+		return RecordBestBlindfold.Delete(&models.RecordBestBlindfold{Id: id, Dimension: dimension, Width: width, Height: height})
+	}
+
+	bestBlindfold := models.RecordBestBlindfold{
+		UserId:             userId,
+		Dimension:          dimension,
+		Width:              width,
+		Height:             height,
+		RecordId:           best.Id,
+		RecordDuration:     best.Duration,
+		RecordMemoDuration: best.MemoDuration,
+		RecordExecDuration: best.ExecDuration,
+		RecordStep:         best.Step,
+	}
+
+	if current.Total == 0 {
+		snowflake := utils.Snowflake{}
+		bestBlindfold.Id = snowflake.NextVal()
+		bestBlindfold.RecordBreakCount = 1
+
+		return RecordBestBlindfold.Insert(&bestBlindfold)
+	}
+
+	bestBlindfold.Id, _ = strconv.ParseInt(current.Records[0].Id, 10, 64)
+
+	return RecordBestBlindfold.Update(&bestBlindfold)
 }
@@ -21,6 +21,7 @@ type RecordBestAverageService interface {
 	Insert(record *models.RecordBestAverage) error
 	List(recordReq *models.RecordBestAverageReq) (models.RecordBestAverageListResp, error)
 	Update(record *models.RecordBestAverage) error
+	Delete(record *models.RecordBestAverage) error
 	publishMessage(rankUpdate handlers.RankUpdate)
 }
 
@@ -50,8 +51,8 @@ func (RecordBestAverageImpl) check(record *models.RecordBestAverage) error {
 		return errors.New("用户ID不能为空")
 	}
 
-	if record.Dimension == 0 {
-		return errors.New("阶数不能为空")
+	if record.Width == 0 || record.Height == 0 {
+		return errors.New("尺寸不能为空")
 	}
 
 	if record.Type == 0 {
@@ -85,6 +86,8 @@ func (RecordBestAverageImpl) Insert(record *models.RecordBestAverage) error {
 	// 往消息队列中发送消息
 	RecordBestAverage.publishMessage(handlers.RankUpdate{
 		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
 		Type:      record.Type,
 	})
 
@@ -126,8 +129,14 @@ func (RecordBestAverageImpl) List(recordReq *models.RecordBestAverageReq) (model
 		db.Where("user_id = ?", recordReq.UserId)
 	}
 
-	if recordReq.Dimension != 0 {
-		db.Where("dimension = ?", recordReq.Dimension)
+	_, recordReq.Width, recordReq.Height = utils.NormalizeSize(recordReq.Dimension, recordReq.Width, recordReq.Height)
+
+	if recordReq.Width != 0 {
+		db.Where("width = ?", recordReq.Width)
+	}
+
+	if recordReq.Height != 0 {
+		db.Where("height = ?", recordReq.Height)
 	}
 
 	if recordReq.Type != 0 {
@@ -207,12 +216,12 @@ func (RecordBestAverageImpl) List(recordReq *models.RecordBestAverageReq) (model
 		recordMap := make(map[string][]models.RecordResp)
 
 		for _, record := range recordList.Records {
-			key := record.UserId + "-" + strconv.Itoa(record.Dimension)
+			key := record.UserId + "-" + strconv.Itoa(record.Width) + "x" + strconv.Itoa(record.Height)
 			recordMap[key] = append(recordMap[key], record)
 		}
 
 		for i, record := range recordListResp.Records {
-			key := record.UserId + "-" + strconv.Itoa(record.Dimension)
+			key := record.UserId + "-" + strconv.Itoa(record.Width) + "x" + strconv.Itoa(record.Height)
 
 			recordSet := mapset.NewSet()
 
@@ -237,7 +246,7 @@ func (RecordBestAverageImpl) List(recordReq *models.RecordBestAverageReq) (model
 
 // Update 更新记录
 func (RecordBestAverageImpl) Update(record *models.RecordBestAverage) error {
-	db := database.GetMySQL().Table("record_best_average").Where("user_id = ? AND dimension = ? AND type = ?", record.UserId, record.Dimension, record.Type)
+	db := database.GetMySQL().Table("record_best_average").Where("user_id = ? AND width = ? AND height = ? AND type = ?", record.UserId, record.Width, record.Height, record.Type)
 
 	err := db.Updates(record).Error
 	if err != nil {
@@ -247,6 +256,26 @@ func (RecordBestAverageImpl) Update(record *models.RecordBestAverage) error {
 	// 往消息队列中发送消息
 	RecordBestAverage.publishMessage(handlers.RankUpdate{
 		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+		Type:      record.Type,
+	})
+
+	return nil
+}
+
+// Delete 删除记录
+func (RecordBestAverageImpl) Delete(record *models.RecordBestAverage) error {
+	err := database.GetMySQL().Table("record_best_average").Where("id = ?", record.Id).Delete(&models.RecordBestAverage{}).Error
+	if err != nil {
+		return errors.New("删除失败")
+	}
+
+	// 发送消息至消息队列
+	RecordBestAverage.publishMessage(handlers.RankUpdate{
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
 		Type:      record.Type,
 	})
 
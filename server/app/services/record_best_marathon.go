@@ -0,0 +1,206 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"puzzle/app/middlewares/rabbitmq"
+	"puzzle/app/middlewares/rabbitmq/handlers"
+	"puzzle/app/models"
+
+	"puzzle/database"
+	"puzzle/utils"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type RecordBestMarathonService interface {
+	check(record *models.RecordBestMarathon) error
+	Insert(record *models.RecordBestMarathon) error
+	List(recordReq *models.RecordBestMarathonReq) (models.RecordBestMarathonListResp, error)
+	Update(record *models.RecordBestMarathon) error
+	publishMessage(rankUpdate handlers.RankUpdate)
+}
+
+type RecordBestMarathonImpl struct{}
+
+// publishMessage 发送消息至消息队列
+func (RecordBestMarathonImpl) publishMessage(rankUpdate handlers.RankUpdate) {
+	mq := rabbitmq.NewRabbitMQ("best_marathon_rank_update_queue", "", "")
+	defer mq.Destory()
+
+	message := rabbitmq.RabbitMQMessage{
+		RankUpdate: rankUpdate,
+		Message:    "rank update",
+	}
+
+	messageByte, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+
+	mq.Publish(messageByte)
+}
+
+func (RecordBestMarathonImpl) check(record *models.RecordBestMarathon) error {
+	if record.UserId == 0 {
+		return errors.New("用户ID不能为空")
+	}
+
+	if record.Mode == 0 {
+		return errors.New("模式不能为空")
+	}
+
+	if record.Width == 0 || record.Height == 0 {
+		return errors.New("尺寸不能为空")
+	}
+
+	if record.Target == 0 {
+		return errors.New("目标不能为空")
+	}
+
+	if record.MarathonId == 0 {
+		return errors.New("耐力模式ID不能为空")
+	}
+
+	return nil
+}
+
+// Insert 插入一条记录
+func (RecordBestMarathonImpl) Insert(record *models.RecordBestMarathon) error {
+	// 校验参数
+	err := RecordBestMarathon.check(record)
+	if err != nil {
+		return err
+	}
+
+	record.RecordBreakCount = 1
+
+	err = database.GetMySQL().Create(record).Error
+	if err != nil {
+		return err
+	}
+
+	// 发送消息至消息队列
+	RecordBestMarathon.publishMessage(handlers.RankUpdate{
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+		Type:      record.Mode,
+		Target:    record.Target,
+	})
+
+	return nil
+}
+
+// List 查询记录列表
+func (RecordBestMarathonImpl) List(recordReq *models.RecordBestMarathonReq) (models.RecordBestMarathonListResp, error) {
+	var recordListResp models.RecordBestMarathonListResp
+
+	if recordReq.Username != "" || recordReq.Nickname != "" {
+		userInfo, err := User.GetUserByUsernameOrNickname(recordReq.Username, recordReq.Nickname)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return recordListResp, errors.New("查询用户信息失败")
+		}
+
+		if userInfo.Id == "" {
+			recordReq.UserIdStr = "-1"
+		} else {
+			recordReq.UserIdStr = userInfo.Id
+		}
+	}
+
+	if recordReq.IdStr != "" {
+		recordReq.Id, _ = strconv.ParseInt(recordReq.IdStr, 10, 64)
+	}
+
+	if recordReq.UserIdStr != "" {
+		recordReq.UserId, _ = strconv.ParseInt(recordReq.UserIdStr, 10, 64)
+	}
+
+	if recordReq.OrderBy == "" {
+		recordReq.OrderBy = "id"
+	}
+
+	db := database.GetMySQL().Table("record_best_marathon").Order(recordReq.OrderBy + " " + recordReq.Sorted)
+
+	if recordReq.Id != 0 {
+		db.Where("id = ?", recordReq.Id)
+	}
+
+	if recordReq.UserId != 0 {
+		db.Where("user_id = ?", recordReq.UserId)
+	}
+
+	if recordReq.Mode != 0 {
+		db.Where("mode = ?", recordReq.Mode)
+	}
+
+	_, recordReq.Width, recordReq.Height = utils.NormalizeSize(recordReq.Dimension, recordReq.Width, recordReq.Height)
+
+	if recordReq.Width != 0 {
+		db.Where("width = ?", recordReq.Width)
+	}
+
+	if recordReq.Height != 0 {
+		db.Where("height = ?", recordReq.Height)
+	}
+
+	if recordReq.Target != 0 {
+		db.Where("target = ?", recordReq.Target)
+	}
+
+	if len(recordReq.RankRange) == 2 {
+		if recordReq.RankRange[0] != 0 {
+			db.Where("ranked >= ?", recordReq.RankRange[0])
+		}
+		if recordReq.RankRange[1] != 0 {
+			db.Where("ranked <= ?", recordReq.RankRange[1])
+		}
+	}
+
+	// 查询总数
+	err := db.Count(&recordListResp.Total).Error
+	if err != nil {
+		return recordListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if recordReq.Pagination.Page > 0 && recordReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&recordReq.Pagination))
+	}
+
+	if recordReq.NeedUserInfo {
+		db.Preload("UserInfo")
+	}
+
+	// 查询列表
+	err = db.Find(&recordListResp.Records).Error
+	if err != nil {
+		return recordListResp, errors.New("查询失败")
+	}
+
+	return recordListResp, nil
+}
+
+// Update 更新记录
+func (RecordBestMarathonImpl) Update(record *models.RecordBestMarathon) error {
+	db := database.GetMySQL().Table("record_best_marathon")
+
+	err := db.Updates(record).Error
+
+	if err != nil {
+		return errors.New("更新失败")
+	}
+
+	// 发送消息至消息队列
+	RecordBestMarathon.publishMessage(handlers.RankUpdate{
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+		Type:      record.Mode,
+		Target:    record.Target,
+	})
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"puzzle/app/middlewares/rabbitmq"
+	"puzzle/app/middlewares/rabbitmq/handlers"
+	"puzzle/app/models"
+
+	"puzzle/database"
+	"puzzle/utils"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type RecordBestRelayService interface {
+	check(record *models.RecordBestRelay) error
+	Insert(record *models.RecordBestRelay) error
+	List(recordReq *models.RecordBestRelayReq) (models.RecordBestRelayListResp, error)
+	Update(record *models.RecordBestRelay) error
+	publishMessage(rankUpdate handlers.RankUpdate)
+}
+
+type RecordBestRelayImpl struct{}
+
+// publishMessage 发送消息至消息队列
+func (RecordBestRelayImpl) publishMessage(rankUpdate handlers.RankUpdate) {
+	mq := rabbitmq.NewRabbitMQ("best_relay_rank_update_queue", "", "")
+	defer mq.Destory()
+
+	message := rabbitmq.RabbitMQMessage{
+		RankUpdate: rankUpdate,
+		Message:    "rank update",
+	}
+
+	messageByte, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+
+	mq.Publish(messageByte)
+}
+
+func (RecordBestRelayImpl) check(record *models.RecordBestRelay) error {
+	if record.UserId == 0 {
+		return errors.New("用户ID不能为空")
+	}
+
+	if record.Event == "" {
+		return errors.New("项目不能为空")
+	}
+
+	if record.RecordDuration == 0 {
+		return errors.New("耗时不能为空")
+	}
+
+	if record.RelayId == 0 {
+		return errors.New("接力ID不能为空")
+	}
+
+	return nil
+}
+
+// Insert 插入一条记录
+func (RecordBestRelayImpl) Insert(record *models.RecordBestRelay) error {
+	// 校验参数
+	err := RecordBestRelay.check(record)
+	if err != nil {
+		return err
+	}
+
+	record.RecordBreakCount = 1
+
+	err = database.GetMySQL().Create(record).Error
+	if err != nil {
+		return err
+	}
+
+	// 发送消息至消息队列
+	RecordBestRelay.publishMessage(handlers.RankUpdate{
+		Event: record.Event,
+	})
+
+	return nil
+}
+
+// List 查询记录列表
+func (RecordBestRelayImpl) List(recordReq *models.RecordBestRelayReq) (models.RecordBestRelayListResp, error) {
+	var recordListResp models.RecordBestRelayListResp
+
+	if recordReq.Username != "" || recordReq.Nickname != "" {
+		userInfo, err := User.GetUserByUsernameOrNickname(recordReq.Username, recordReq.Nickname)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return recordListResp, errors.New("查询用户信息失败")
+		}
+
+		if userInfo.Id == "" {
+			recordReq.UserIdStr = "-1"
+		} else {
+			recordReq.UserIdStr = userInfo.Id
+		}
+	}
+
+	if recordReq.IdStr != "" {
+		recordReq.Id, _ = strconv.ParseInt(recordReq.IdStr, 10, 64)
+	}
+
+	if recordReq.UserIdStr != "" {
+		recordReq.UserId, _ = strconv.ParseInt(recordReq.UserIdStr, 10, 64)
+	}
+
+	if recordReq.OrderBy == "" {
+		recordReq.OrderBy = "id"
+	}
+
+	db := database.GetMySQL().Table("record_best_relay").Order(recordReq.OrderBy + " " + recordReq.Sorted)
+
+	if recordReq.Id != 0 {
+		db.Where("id = ?", recordReq.Id)
+	}
+
+	if recordReq.UserId != 0 {
+		db.Where("user_id = ?", recordReq.UserId)
+	}
+
+	if recordReq.Event != "" {
+		db.Where("event = ?", recordReq.Event)
+	}
+
+	if recordReq.RelayId != 0 {
+		db.Where("relay_id = ?", recordReq.RelayId)
+	}
+
+	if len(recordReq.DurationRange) == 2 {
+		if recordReq.DurationRange[0] != 0 {
+			db.Where("record_duration >= ?", recordReq.DurationRange[0])
+		}
+		if recordReq.DurationRange[1] != 0 {
+			db.Where("record_duration <= ?", recordReq.DurationRange[1])
+		}
+	}
+
+	if len(recordReq.RankRange) == 2 {
+		if recordReq.RankRange[0] != 0 {
+			db.Where("ranked >= ?", recordReq.RankRange[0])
+		}
+		if recordReq.RankRange[1] != 0 {
+			db.Where("ranked <= ?", recordReq.RankRange[1])
+		}
+	}
+
+	// 查询总数
+	err := db.Count(&recordListResp.Total).Error
+	if err != nil {
+		return recordListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if recordReq.Pagination.Page > 0 && recordReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&recordReq.Pagination))
+	}
+
+	if recordReq.NeedUserInfo {
+		db.Preload("UserInfo")
+	}
+
+	if recordReq.NeedDetail {
+		db.Preload("RelayDetail.Legs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq asc")
+		})
+	}
+
+	// 查询列表
+	err = db.Find(&recordListResp.Records).Error
+	if err != nil {
+		return recordListResp, errors.New("查询失败")
+	}
+
+	return recordListResp, nil
+}
+
+// Update 更新记录
+func (RecordBestRelayImpl) Update(record *models.RecordBestRelay) error {
+	db := database.GetMySQL().Table("record_best_relay")
+
+	err := db.Updates(record).Error
+
+	if err != nil {
+		return errors.New("更新失败")
+	}
+
+	// 发送消息至消息队列
+	RecordBestRelay.publishMessage(handlers.RankUpdate{
+		Event: record.Event,
+	})
+
+	return nil
+}
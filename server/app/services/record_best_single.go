@@ -19,6 +19,7 @@ type RecordBestSingleService interface {
 	Insert(record *models.RecordBestSingle) error
 	List(recordReq *models.RecordBestSingleReq) (models.RecordBestSingleListResp, error)
 	Update(record *models.RecordBestSingle) error
+	Delete(record *models.RecordBestSingle) error
 	publishMessage(rankUpdate handlers.RankUpdate)
 }
 
@@ -47,8 +48,8 @@ func (RecordBestSingleImpl) check(record *models.RecordBestSingle) error {
 		return errors.New("用户ID不能为空")
 	}
 
-	if record.Dimension == 0 {
-		return errors.New("阶数不能为空")
+	if record.Width == 0 || record.Height == 0 {
+		return errors.New("尺寸不能为空")
 	}
 
 	if record.RecordDuration == 0 {
@@ -84,6 +85,8 @@ func (RecordBestSingleImpl) Insert(record *models.RecordBestSingle) error {
 	// 发送消息至消息队列
 	RecordBestSingle.publishMessage(handlers.RankUpdate{
 		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
 	})
 
 	return nil
@@ -135,8 +138,14 @@ func (RecordBestSingleImpl) List(recordReq *models.RecordBestSingleReq) (models.
 		db.Where("user_id = ?", recordReq.UserId)
 	}
 
-	if recordReq.Dimension != 0 {
-		db.Where("dimension = ?", recordReq.Dimension)
+	_, recordReq.Width, recordReq.Height = utils.NormalizeSize(recordReq.Dimension, recordReq.Width, recordReq.Height)
+
+	if recordReq.Width != 0 {
+		db.Where("width = ?", recordReq.Width)
+	}
+
+	if recordReq.Height != 0 {
+		db.Where("height = ?", recordReq.Height)
 	}
 
 	if recordReq.RecordId != 0 {
@@ -224,6 +233,25 @@ func (RecordBestSingleImpl) Update(record *models.RecordBestSingle) error {
 	// 发送消息至消息队列
 	RecordBestSingle.publishMessage(handlers.RankUpdate{
 		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+	})
+
+	return nil
+}
+
+// Delete 删除记录
+func (RecordBestSingleImpl) Delete(record *models.RecordBestSingle) error {
+	err := database.GetMySQL().Table("record_best_single").Where("id = ?", record.Id).Delete(&models.RecordBestSingle{}).Error
+	if err != nil {
+		return errors.New("删除失败")
+	}
+
+	// 发送消息至消息队列
+	RecordBestSingle.publishMessage(handlers.RankUpdate{
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
 	})
 
 	return nil
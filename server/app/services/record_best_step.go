@@ -19,6 +19,7 @@ type RecordBestStepService interface {
 	List(recordReq *models.RecordBestStepReq) (models.RecordBestStepListResp, error)
 	ListWithUserInfo(recordReq *models.RecordBestStepReq) (models.RecordBestStepListResp, error)
 	Update(record *models.RecordBestStep) error
+	Delete(record *models.RecordBestStep) error
 	publishMessage(rankUpdate handlers.RankUpdate)
 }
 
@@ -30,8 +31,8 @@ func (RecordBestStepImpl) check(record *models.RecordBestStep) error {
 		return errors.New("用户ID不能为空")
 	}
 
-	if record.Dimension == 0 {
-		return errors.New("阶数不能为空")
+	if record.Width == 0 || record.Height == 0 {
+		return errors.New("尺寸不能为空")
 	}
 
 	if record.RecordId == 0 {
@@ -60,6 +61,8 @@ func (RecordBestStepImpl) Insert(record *models.RecordBestStep) error {
 	// 发送消息至消息队列
 	RecordBestStep.publishMessage(handlers.RankUpdate{
 		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
 	})
 
 	return nil
@@ -103,8 +106,14 @@ func (RecordBestStepImpl) List(recordReq *models.RecordBestStepReq) (models.Reco
 		db.Where("user_id = ?", recordReq.UserId)
 	}
 
-	if recordReq.Dimension != 0 {
-		db.Where("dimension = ?", recordReq.Dimension)
+	_, recordReq.Width, recordReq.Height = utils.NormalizeSize(recordReq.Dimension, recordReq.Width, recordReq.Height)
+
+	if recordReq.Width != 0 {
+		db.Where("width = ?", recordReq.Width)
+	}
+
+	if recordReq.Height != 0 {
+		db.Where("height = ?", recordReq.Height)
 	}
 
 	if recordReq.RecordId != 0 {
@@ -200,8 +209,14 @@ func (RecordBestStepImpl) ListWithUserInfo(recordReq *models.RecordBestStepReq)
 		db.Where("user_id = ?", recordReq.UserId)
 	}
 
-	if recordReq.Dimension != 0 {
-		db.Where("dimension = ?", recordReq.Dimension)
+	_, recordReq.Width, recordReq.Height = utils.NormalizeSize(recordReq.Dimension, recordReq.Width, recordReq.Height)
+
+	if recordReq.Width != 0 {
+		db.Where("width = ?", recordReq.Width)
+	}
+
+	if recordReq.Height != 0 {
+		db.Where("height = ?", recordReq.Height)
 	}
 
 	if recordReq.RecordId != 0 {
@@ -263,7 +278,7 @@ func (RecordBestStepImpl) ListWithUserInfo(recordReq *models.RecordBestStepReq)
 
 // Update 更新记录
 func (RecordBestStepImpl) Update(record *models.RecordBestStep) error {
-	db := database.GetMySQL().Table("record_best_step").Where("user_id = ? AND dimension = ?", record.UserId, record.Dimension)
+	db := database.GetMySQL().Table("record_best_step").Where("user_id = ? AND width = ? AND height = ?", record.UserId, record.Width, record.Height)
 
 	err := db.Updates(record).Error
 
@@ -274,6 +289,8 @@ func (RecordBestStepImpl) Update(record *models.RecordBestStep) error {
 	// 发送消息至消息队列
 	RecordBestStep.publishMessage(handlers.RankUpdate{
 		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
 	})
 
 	return nil
@@ -296,3 +313,20 @@ func (RecordBestStepImpl) publishMessage(rankUpdate handlers.RankUpdate) {
 
 	mq.Publish(messageByte)
 }
+
+// Delete 删除记录
+func (RecordBestStepImpl) Delete(record *models.RecordBestStep) error {
+	err := database.GetMySQL().Table("record_best_step").Where("id = ?", record.Id).Delete(&models.RecordBestStep{}).Error
+	if err != nil {
+		return errors.New("删除失败")
+	}
+
+	// 发送消息至消息队列
+	RecordBestStep.publishMessage(handlers.RankUpdate{
+		Dimension: record.Dimension,
+		Width:     record.Width,
+		Height:    record.Height,
+	})
+
+	return nil
+}
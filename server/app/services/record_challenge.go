@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/utils"
+	"strconv"
+)
+
+type RecordChallengeService interface {
+	GetScramble(challengeReq *models.RecordChallengeReq) (models.RecordChallengeResp, error)
+	Leaderboard(challengeReq *models.RecordChallengeReq) (models.RecordChallengeLeaderboardResp, error)
+	getRecord(recordId, userId int64) (models.Record, error)
+}
+
+type RecordChallengeImpl struct{}
+
+// GetScramble 获取任意公开记录的打乱, 用于挑战该记录
+func (RecordChallengeImpl) GetScramble(challengeReq *models.RecordChallengeReq) (models.RecordChallengeResp, error) {
+	var challengeResp models.RecordChallengeResp
+
+	if challengeReq.IdStr != "" {
+		challengeReq.Id, _ = strconv.ParseInt(challengeReq.IdStr, 10, 64)
+	}
+
+	record, err := RecordChallenge.getRecord(challengeReq.Id, challengeReq.UserId)
+	if err != nil {
+		return challengeResp, err
+	}
+
+	userInfo, err := User.GetUserById(record.UserId)
+	if err != nil {
+		return challengeResp, errors.New("查询用户信息失败")
+	}
+
+	challengeResp = models.RecordChallengeResp{
+		RecordId:        strconv.FormatInt(record.Id, 10),
+		UserInfo:        userInfo,
+		Dimension:       record.Dimension,
+		Width:           record.Width,
+		Height:          record.Height,
+		Duration:        record.Duration,
+		Step:            record.Step,
+		Scramble:        record.Scramble,
+		Idx:             strconv.FormatInt(record.Idx, 10),
+		ScrambleVersion: record.ScrambleVersion,
+	}
+
+	key := models.ScrambleKey{
+		Idx:     record.Idx,
+		Width:   record.Width,
+		Height:  record.Height,
+		Version: record.ScrambleVersion,
+	}
+
+	difficultyMap, err := Scramble.GetDifficultyByKeys([]models.ScrambleKey{key})
+	if err != nil {
+		return challengeResp, err
+	}
+
+	if difficulty, ok := difficultyMap[key]; ok {
+		challengeResp.Difficulty = &difficulty
+	}
+
+	return challengeResp, nil
+}
+
+// Leaderboard 同一打乱的排行榜, 包括原记录以及所有挑战该打乱的记录
+func (RecordChallengeImpl) Leaderboard(challengeReq *models.RecordChallengeReq) (models.RecordChallengeLeaderboardResp, error) {
+	var leaderboardResp models.RecordChallengeLeaderboardResp
+
+	challenge, err := RecordChallenge.GetScramble(challengeReq)
+	if err != nil {
+		return leaderboardResp, err
+	}
+
+	leaderboardResp.Challenge = challenge
+	idx, _ := strconv.ParseInt(challenge.Idx, 10, 64)
+
+	db := database.GetMySQL().Table("record").
+		Where("width = ? AND height = ? AND idx = ? AND scramble = ? AND status = ?",
+			challenge.Width, challenge.Height, idx, challenge.Scramble, 1).
+		Scopes(ScrambleSet.visibleScope(challengeReq.UserId)).
+		Order("duration asc, step asc, id asc")
+
+	// 查询总数
+	err = db.Count(&leaderboardResp.Total).Error
+	if err != nil {
+		return leaderboardResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if challengeReq.Pagination.Page > 0 && challengeReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&challengeReq.Pagination))
+	}
+
+	err = db.Preload("UserInfo").Find(&leaderboardResp.Records).Error
+	if err != nil {
+		return leaderboardResp, errors.New("查询失败")
+	}
+
+	return leaderboardResp, nil
+}
+
+// getRecord 获取用户可以挑战的公开记录
+func (RecordChallengeImpl) getRecord(recordId, userId int64) (models.Record, error) {
+	var record models.Record
+	err := database.GetMySQL().Table("record").Where("id = ? AND status = ?", recordId, 1).First(&record).Error
+	if err != nil {
+		return record, errors.New("记录不存在")
+	}
+
+	if err = ScrambleSet.checkVisible(&record, userId); err != nil {
+		return record, err
+	}
+
+	return record, nil
+}
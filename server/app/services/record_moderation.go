@@ -0,0 +1,518 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"puzzle/app/middlewares/rabbitmq/handlers"
+	"puzzle/app/models"
+	"puzzle/config"
+	"puzzle/database"
+	"puzzle/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 自动标记的默认阈值
+const (
+	defaultMinMoveInterval = 25  // 最小平均移动间隔(毫秒)
+	defaultMinDuration     = 300 // 最小耗时(毫秒)
+)
+
+type RecordModerationService interface {
+	Flag(flagReq *models.RecordModerationFlagReq) error
+	AutoFlag(record *models.Record) error
+	List(moderationReq *models.RecordModerationReq) (models.RecordModerationListResp, error)
+	Handle(handleReq *models.RecordModerationHandleReq) error
+	ListLog(logReq *models.RecordModerationLogReq) (models.RecordModerationLogListResp, error)
+	setRecordStatus(tx *gorm.DB, recordId int64, status int) error
+	insertLog(tx *gorm.DB, moderation *models.RecordModeration, action int, remark string) error
+	removeBests(tx *gorm.DB, userId int64) (userBests, error)
+	publishBestRemoval(bests userBests)
+}
+
+type RecordModerationImpl struct{}
+
+// userBests 用户的全部最佳记录, 封禁用户时在事务中移除, 事务提交后更新对应排名
+type userBests struct {
+	singles    []models.RecordBestSingle
+	averages   []models.RecordBestAverage
+	steps      []models.RecordBestStep
+	blindfolds []models.RecordBestBlindfold
+	relays     []models.RecordBestRelay
+	marathons  []models.RecordBestMarathon
+}
+
+// Flag 标记记录, 同一记录存在待审核的标记时累加标记次数
+func (RecordModerationImpl) Flag(flagReq *models.RecordModerationFlagReq) error {
+	if flagReq.RecordIdStr != "" {
+		flagReq.RecordId, _ = strconv.ParseInt(flagReq.RecordIdStr, 10, 64)
+	}
+
+	if flagReq.RecordId == 0 {
+		return errors.New("记录ID不能为空")
+	}
+
+	if flagReq.Reason == "" {
+		return errors.New("标记原因不能为空")
+	}
+
+	var record models.Record
+	err := database.GetMySQL().Table("record").Where("id = ?", flagReq.RecordId).First(&record).Error
+	if err != nil {
+		return errors.New("记录不存在")
+	}
+
+	// 查询是否存在待审核的标记
+	var moderation models.RecordModeration
+	err = database.GetMySQL().Table("record_moderation").
+		Where("record_id = ? AND status = ?", flagReq.RecordId, 1).
+		Limit(1).
+		Find(&moderation).Error
+	if err != nil {
+		return errors.New("查询审核记录失败")
+	}
+
+	if moderation.Id != 0 {
+		err = database.GetMySQL().Table("record_moderation").
+			Where("id = ?", moderation.Id).
+			Update("flag_count", gorm.Expr("flag_count + 1")).Error
+		if err != nil {
+			return errors.New("更新审核记录失败")
+		}
+
+		return nil
+	}
+
+	snowflake := utils.Snowflake{}
+
+	moderation = models.RecordModeration{
+		Id:         snowflake.NextVal(),
+		RecordId:   record.Id,
+		UserId:     record.UserId,
+		Dimension:  record.Dimension,
+		Width:      record.Width,
+		Height:     record.Height,
+		Source:     flagReq.Source,
+		Reason:     flagReq.Reason,
+		FlagCount:  1,
+		ReporterId: flagReq.ReporterId,
+		Status:     1,
+	}
+
+	err = database.GetMySQL().Create(&moderation).Error
+	if err != nil {
+		return errors.New("新增审核记录失败")
+	}
+
+	return nil
+}
+
+// AutoFlag 根据配置的阈值自动标记可疑记录
+func (RecordModerationImpl) AutoFlag(record *models.Record) error {
+	minMoveInterval := config.Settings.Moderation.MinMoveInterval
+	if minMoveInterval == 0 {
+		minMoveInterval = defaultMinMoveInterval
+	}
+
+	minDuration := config.Settings.Moderation.MinDuration
+	if minDuration == 0 {
+		minDuration = defaultMinDuration
+	}
+
+	reason := ""
+
+	if record.Duration < minDuration {
+		reason = fmt.Sprintf("耗时 %d 毫秒低于阈值 %d 毫秒", record.Duration, minDuration)
+	} else if record.Step > 0 && record.Duration/record.Step < minMoveInterval {
+		reason = fmt.Sprintf("平均每步 %d 毫秒低于阈值 %d 毫秒", record.Duration/record.Step, minMoveInterval)
+	}
+
+	if reason == "" {
+		return nil
+	}
+
+	return RecordModeration.Flag(&models.RecordModerationFlagReq{
+		RecordId: record.Id,
+		Source:   1,
+		Reason:   reason,
+	})
+}
+
+// List 审核队列列表
+func (RecordModerationImpl) List(moderationReq *models.RecordModerationReq) (models.RecordModerationListResp, error) {
+	var moderationListResp models.RecordModerationListResp
+
+	if moderationReq.IdStr != "" {
+		moderationReq.Id, _ = strconv.ParseInt(moderationReq.IdStr, 10, 64)
+	}
+
+	if moderationReq.RecordIdStr != "" {
+		moderationReq.RecordId, _ = strconv.ParseInt(moderationReq.RecordIdStr, 10, 64)
+	}
+
+	if moderationReq.UserIdStr != "" {
+		moderationReq.UserId, _ = strconv.ParseInt(moderationReq.UserIdStr, 10, 64)
+	}
+
+	if moderationReq.OrderBy == "" {
+		moderationReq.OrderBy = "id"
+	}
+
+	db := database.GetMySQL().Table("record_moderation").Order(moderationReq.OrderBy + " " + moderationReq.Sorted)
+
+	if moderationReq.Id != 0 {
+		db.Where("id = ?", moderationReq.Id)
+	}
+
+	if moderationReq.RecordId != 0 {
+		db.Where("record_id = ?", moderationReq.RecordId)
+	}
+
+	if moderationReq.UserId != 0 {
+		db.Where("user_id = ?", moderationReq.UserId)
+	}
+
+	_, moderationReq.Width, moderationReq.Height = utils.NormalizeSize(moderationReq.Dimension, moderationReq.Width, moderationReq.Height)
+
+	if moderationReq.Width != 0 {
+		db.Where("width = ?", moderationReq.Width)
+	}
+
+	if moderationReq.Height != 0 {
+		db.Where("height = ?", moderationReq.Height)
+	}
+
+	if moderationReq.Source != 0 {
+		db.Where("source = ?", moderationReq.Source)
+	}
+
+	if moderationReq.Status != 0 {
+		db.Where("status = ?", moderationReq.Status)
+	}
+
+	if moderationReq.Reason != "" {
+		db.Where("reason Like ?", "%"+moderationReq.Reason+"%")
+	}
+
+	if len(moderationReq.DateRange) == 2 && !moderationReq.DateRange[0].IsZero() && !moderationReq.DateRange[1].IsZero() {
+		db.Where("created_at >= ? AND created_at <= ?", moderationReq.DateRange[0], moderationReq.DateRange[1])
+	}
+
+	// 查询总数
+	err := db.Count(&moderationListResp.Total).Error
+	if err != nil {
+		return moderationListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if moderationReq.Pagination.Page > 0 && moderationReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&moderationReq.Pagination))
+	}
+
+	// 查询列表
+	err = db.Preload("RecordInfo").Preload("UserInfo").Find(&moderationListResp.Records).Error
+	if err != nil {
+		return moderationListResp, errors.New("查询失败")
+	}
+
+	// 查询用户同尺寸的近期历史记录
+	if moderationReq.NeedUserHistory {
+		for i, moderation := range moderationListResp.Records {
+			userId, _ := strconv.ParseInt(moderation.UserId, 10, 64)
+
+			history, err := Record.List(&models.RecordReq{
+				UserId:    userId,
+				Dimension: moderation.Dimension,
+				Width:     moderation.Width,
+				Height:    moderation.Height,
+				Sorted:    "desc",
+				Pagination: utils.Pagination{
+					Page:     1,
+					PageSize: 20,
+				},
+			})
+			if err != nil {
+				return moderationListResp, errors.New("查询用户历史记录失败")
+			}
+
+			moderationListResp.Records[i].UserHistory = history
+		}
+	}
+
+	return moderationListResp, nil
+}
+
+// Handle 处理审核 1:通过 2:驳回(冻结记录并重新计算最佳记录) 3:封禁(驳回并冻结用户)
+func (RecordModerationImpl) Handle(handleReq *models.RecordModerationHandleReq) error {
+	if handleReq.IdStr != "" {
+		handleReq.Id, _ = strconv.ParseInt(handleReq.IdStr, 10, 64)
+	}
+
+	if handleReq.Action < 1 || handleReq.Action > 3 {
+		return errors.New("审核操作不合法")
+	}
+
+	var moderation models.RecordModeration
+	err := database.GetMySQL().Table("record_moderation").Where("id = ?", handleReq.Id).First(&moderation).Error
+	if err != nil {
+		return errors.New("审核记录不存在")
+	}
+
+	if moderation.Status != 1 {
+		return errors.New("该审核已处理")
+	}
+
+	var record models.Record
+	err = database.GetMySQL().Table("record").Where("id = ?", moderation.RecordId).First(&record).Error
+	if err != nil {
+		return errors.New("记录不存在")
+	}
+
+	// 审核状态、记录状态、用户状态与审核日志在同一事务中更新
+	var bests userBests
+	err = database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// 只处理待审核的记录, 避免重复处理
+		result := tx.Table("record_moderation").Where("id = ? AND status = ?", moderation.Id, 1).Updates(&models.RecordModeration{
+			Status:    handleReq.Action + 1,
+			Remark:    handleReq.Remark,
+			HandledAt: &now,
+		})
+		if result.Error != nil {
+			return errors.New("更新审核记录失败")
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("该审核已处理")
+		}
+
+		var err error
+		switch handleReq.Action {
+		case 1: // 通过, 恢复记录
+			err = RecordModeration.setRecordStatus(tx, moderation.RecordId, 1)
+		case 2, 3: // 驳回, 冻结记录
+			err = RecordModeration.setRecordStatus(tx, moderation.RecordId, 2)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 封禁用户, 同时移除用户的全部最佳记录
+		if handleReq.Action == 3 {
+			err = tx.Table("user").Where("id = ?", moderation.UserId).Update("status", 2).Error
+			if err != nil {
+				return errors.New("封禁用户失败")
+			}
+
+			bests, err = RecordModeration.removeBests(tx, moderation.UserId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return RecordModeration.insertLog(tx, &moderation, handleReq.Action, handleReq.Remark)
+	})
+	if err != nil {
+		return err
+	}
+
+	if handleReq.Action == 3 {
+		// 封禁用户的最佳记录已移除, 更新受影响的排名
+		RecordModeration.publishBestRemoval(bests)
+	} else {
+		// 记录状态变化后重新计算最佳记录
+		err = Record.RecomputeBest(moderation.UserId, moderation.Width, moderation.Height)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 通知记录所属用户
+	if handleReq.Action != 1 {
+		content := fmt.Sprintf("您于 %s 提交的 %s 记录经审核已被冻结", record.CreatedAt.Format("2006-01-02 15:04:05"), utils.SizeLabel(moderation.Width, moderation.Height))
+		if handleReq.Remark != "" {
+			content += ", 原因: " + handleReq.Remark
+		}
+
+		err = Record.publishNotification(moderation.UserId, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListLog 审核决定日志列表
+func (RecordModerationImpl) ListLog(logReq *models.RecordModerationLogReq) (models.RecordModerationLogListResp, error) {
+	var logListResp models.RecordModerationLogListResp
+
+	if logReq.ModerationIdStr != "" {
+		logReq.ModerationId, _ = strconv.ParseInt(logReq.ModerationIdStr, 10, 64)
+	}
+
+	if logReq.RecordIdStr != "" {
+		logReq.RecordId, _ = strconv.ParseInt(logReq.RecordIdStr, 10, 64)
+	}
+
+	if logReq.UserIdStr != "" {
+		logReq.UserId, _ = strconv.ParseInt(logReq.UserIdStr, 10, 64)
+	}
+
+	db := database.GetMySQL().Table("record_moderation_log").Order("id desc")
+
+	if logReq.ModerationId != 0 {
+		db.Where("moderation_id = ?", logReq.ModerationId)
+	}
+
+	if logReq.RecordId != 0 {
+		db.Where("record_id = ?", logReq.RecordId)
+	}
+
+	if logReq.UserId != 0 {
+		db.Where("user_id = ?", logReq.UserId)
+	}
+
+	if logReq.Action != 0 {
+		db.Where("action = ?", logReq.Action)
+	}
+
+	if len(logReq.DateRange) == 2 && !logReq.DateRange[0].IsZero() && !logReq.DateRange[1].IsZero() {
+		db.Where("created_at >= ? AND created_at <= ?", logReq.DateRange[0], logReq.DateRange[1])
+	}
+
+	// 查询总数
+	err := db.Count(&logListResp.Total).Error
+	if err != nil {
+		return logListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if logReq.Pagination.Page > 0 && logReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&logReq.Pagination))
+	}
+
+	// 查询列表
+	err = db.Find(&logListResp.Records).Error
+	if err != nil {
+		return logListResp, errors.New("查询失败")
+	}
+
+	return logListResp, nil
+}
+
+// setRecordStatus 更新记录状态
+func (RecordModerationImpl) setRecordStatus(tx *gorm.DB, recordId int64, status int) error {
+	err := tx.Table("record").Where("id = ?", recordId).Update("status", status).Error
+	if err != nil {
+		return errors.New("更新记录状态失败")
+	}
+
+	return nil
+}
+
+// insertLog 保存审核决定
+func (RecordModerationImpl) insertLog(tx *gorm.DB, moderation *models.RecordModeration, action int, remark string) error {
+	snowflake := utils.Snowflake{}
+
+	err := tx.Create(&models.RecordModerationLog{
+		Id:           snowflake.NextVal(),
+		ModerationId: moderation.Id,
+		RecordId:     moderation.RecordId,
+		UserId:       moderation.UserId,
+		Action:       action,
+		Remark:       remark,
+	}).Error
+	if err != nil {
+		return errors.New("保存审核日志失败")
+	}
+
+	return nil
+}
+
+// removeBests 移除用户的全部最佳记录, 返回被移除的记录用于更新排名
+func (RecordModerationImpl) removeBests(tx *gorm.DB, userId int64) (userBests, error) {
+	var bests userBests
+
+	tables := []struct {
+		name  string
+		dest  any
+		model any
+	}{
+		{"record_best_single", &bests.singles, &models.RecordBestSingle{}},
+		{"record_best_average", &bests.averages, &models.RecordBestAverage{}},
+		{"record_best_step", &bests.steps, &models.RecordBestStep{}},
+		{"record_best_blindfold", &bests.blindfolds, &models.RecordBestBlindfold{}},
+		{"record_best_relay", &bests.relays, &models.RecordBestRelay{}},
+		{"record_best_marathon", &bests.marathons, &models.RecordBestMarathon{}},
+	}
+
+	for _, table := range tables {
+		err := tx.Table(table.name).Where("user_id = ?", userId).Find(table.dest).Error
+		if err != nil {
+			return bests, errors.New("查询最佳记录失败")
+		}
+
+		err = tx.Table(table.name).Where("user_id = ?", userId).Delete(table.model).Error
+		if err != nil {
+			return bests, errors.New("移除最佳记录失败")
+		}
+	}
+
+	return bests, nil
+}
+
+// publishBestRemoval 更新被移除的最佳记录所在的排名
+func (RecordModerationImpl) publishBestRemoval(bests userBests) {
+	for _, record := range bests.singles {
+		RecordBestSingle.publishMessage(handlers.RankUpdate{
+			Dimension: record.Dimension,
+			Width:     record.Width,
+			Height:    record.Height,
+		})
+	}
+
+	for _, record := range bests.averages {
+		RecordBestAverage.publishMessage(handlers.RankUpdate{
+			Dimension: record.Dimension,
+			Width:     record.Width,
+			Height:    record.Height,
+			Type:      record.Type,
+		})
+	}
+
+	for _, record := range bests.steps {
+		RecordBestStep.publishMessage(handlers.RankUpdate{
+			Dimension: record.Dimension,
+			Width:     record.Width,
+			Height:    record.Height,
+		})
+	}
+
+	for _, record := range bests.blindfolds {
+		RecordBestBlindfold.publishMessage(handlers.RankUpdate{
+			Dimension: record.Dimension,
+			Width:     record.Width,
+			Height:    record.Height,
+		})
+	}
+
+	for _, record := range bests.relays {
+		RecordBestRelay.publishMessage(handlers.RankUpdate{
+			Event: record.Event,
+		})
+	}
+
+	for _, record := range bests.marathons {
+		RecordBestMarathon.publishMessage(handlers.RankUpdate{
+			Dimension: record.Dimension,
+			Width:     record.Width,
+			Height:    record.Height,
+			Type:      record.Mode,
+			Target:    record.Target,
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"puzzle/app/models"
+	"puzzle/config"
+	"puzzle/database"
+	"puzzle/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportDuplicate   = errors.New("您已举报过该记录, 请等待管理员处理")
+	ErrReportRateLimited = errors.New("举报过于频繁, 请稍后再试")
+)
+
+// 默认每个用户每小时最多举报次数
+const defaultReportLimit = 10
+
+// 举报通知类型ID
+const reportNotificationTypeId = 2
+
+// 举报对象类型名称
+var reportTargetNames = map[int]string{
+	1: "最佳单次",
+	2: "最佳平均",
+	3: "最佳步数",
+}
+
+type RecordReportService interface {
+	Report(reportReq *models.RecordReportReq) error
+	getTarget(targetType int, targetId int64) (int64, string, []int64, error)
+	recordIdsKey(recordIds []int64) string
+	checkRateLimit(userId int64) error
+	notifyAdmins(content string)
+}
+
+type RecordReportImpl struct{}
+
+// Report 举报排行榜记录
+func (RecordReportImpl) Report(reportReq *models.RecordReportReq) error {
+	if reportReq.TargetIdStr != "" {
+		reportReq.TargetId, _ = strconv.ParseInt(reportReq.TargetIdStr, 10, 64)
+	}
+
+	if _, ok := reportTargetNames[reportReq.TargetType]; !ok {
+		return errors.New("举报对象类型不合法")
+	}
+
+	if reportReq.TargetId == 0 {
+		return errors.New("举报对象ID不能为空")
+	}
+
+	reportReq.Reason = strings.TrimSpace(reportReq.Reason)
+	if reportReq.Reason == "" {
+		return errors.New("举报原因不能为空")
+	}
+
+	if len([]rune(reportReq.Reason)) > 200 {
+		return errors.New("举报原因不能超过200字")
+	}
+
+	ownerId, sizeLabel, recordIds, err := RecordReport.getTarget(reportReq.TargetType, reportReq.TargetId)
+	if err != nil {
+		return err
+	}
+
+	if ownerId == reportReq.ReporterId {
+		return errors.New("不能举报自己的记录")
+	}
+
+	// 同一用户对同一记录只能举报一次, 按关联的记录ID去重
+	// 最佳记录被打破时排行榜的行ID不变, 按行ID去重会导致无法举报打破后的新记录
+	recordIdsKey := RecordReport.recordIdsKey(recordIds)
+
+	var count int64
+	err = database.GetMySQL().Table("record_report").
+		Where("reporter_id = ? AND target_type = ? AND record_ids = ?", reportReq.ReporterId, reportReq.TargetType, recordIdsKey).
+		Count(&count).Error
+	if err != nil {
+		return errors.New("查询举报记录失败")
+	}
+
+	if count > 0 {
+		return ErrReportDuplicate
+	}
+
+	err = RecordReport.checkRateLimit(reportReq.ReporterId)
+	if err != nil {
+		return err
+	}
+
+	snowflake := utils.Snowflake{}
+
+	err = database.GetMySQL().Create(&models.RecordReport{
+		Id:         snowflake.NextVal(),
+		ReporterId: reportReq.ReporterId,
+		TargetType: reportReq.TargetType,
+		TargetId:   reportReq.TargetId,
+		RecordIds:  recordIdsKey,
+		Reason:     reportReq.Reason,
+	}).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrReportDuplicate
+	}
+	if err != nil {
+		return errors.New("新增举报记录失败")
+	}
+
+	// 加入审核队列
+	for _, recordId := range recordIds {
+		err = RecordModeration.Flag(&models.RecordModerationFlagReq{
+			RecordId:   recordId,
+			ReporterId: reportReq.ReporterId,
+			Source:     2,
+			Reason:     reportReq.Reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// 通知管理员
+	RecordReport.notifyAdmins(fmt.Sprintf("用户举报了一条 %s%s记录(ID: %d), 原因: %s, 请前往审核队列处理", sizeLabel, reportTargetNames[reportReq.TargetType], reportReq.TargetId, reportReq.Reason))
+
+	return nil
+}
+
+// getTarget 获取举报对象的所属用户、尺寸名称以及关联的记录ID
+func (RecordReportImpl) getTarget(targetType int, targetId int64) (int64, string, []int64, error) {
+	db := database.GetMySQL()
+
+	switch targetType {
+	case 1:
+		var best models.RecordBestSingle
+		if err := db.Table("record_best_single").Where("id = ?", targetId).First(&best).Error; err != nil {
+			return 0, "", nil, errors.New("举报的记录不存在")
+		}
+		return best.UserId, utils.SizeLabel(best.Width, best.Height), []int64{best.RecordId}, nil
+
+	case 2:
+		var best models.RecordBestAverage
+		if err := db.Table("record_best_average").Where("id = ?", targetId).First(&best).Error; err != nil {
+			return 0, "", nil, errors.New("举报的记录不存在")
+		}
+
+		recordIds := make([]int64, 0)
+		for _, idStr := range strings.Split(best.RecordIds, ",") {
+			id, err := strconv.ParseInt(idStr, 10, 64)
+			if err == nil {
+				recordIds = append(recordIds, id)
+			}
+		}
+		return best.UserId, utils.SizeLabel(best.Width, best.Height), recordIds, nil
+
+	default:
+		var best models.RecordBestStep
+		if err := db.Table("record_best_step").Where("id = ?", targetId).First(&best).Error; err != nil {
+			return 0, "", nil, errors.New("举报的记录不存在")
+		}
+		return best.UserId, utils.SizeLabel(best.Width, best.Height), []int64{best.RecordId}, nil
+	}
+}
+
+// recordIdsKey 将关联的记录ID排序后拼接, 作为举报去重的依据
+func (RecordReportImpl) recordIdsKey(recordIds []int64) string {
+	sorted := slices.Clone(recordIds)
+	slices.Sort(sorted)
+
+	idStrs := make([]string, len(sorted))
+	for i, id := range sorted {
+		idStrs[i] = strconv.FormatInt(id, 10)
+	}
+
+	return strings.Join(idStrs, ",")
+}
+
+// checkRateLimit 检查用户举报频率
+func (RecordReportImpl) checkRateLimit(userId int64) error {
+	limit := config.Settings.Moderation.ReportLimit
+	if limit == 0 {
+		limit = defaultReportLimit
+	}
+
+	key := fmt.Sprintf("record_report:limit:%d", userId)
+
+	count, err := incrRateLimit(key, time.Hour)
+	if err != nil {
+		return errors.New("举报频率校验失败")
+	}
+
+	if count > int64(limit) {
+		return ErrReportRateLimited
+	}
+
+	return nil
+}
+
+// notifyAdmins 通知管理员有新的举报
+func (RecordReportImpl) notifyAdmins(content string) {
+	for _, adminId := range config.Settings.Moderation.AdminUserIds {
+		if adminId == 0 {
+			continue
+		}
+
+		err := Notification.Insert(&models.NotificationReq{
+			UserId:  adminId,
+			TypeId:  reportNotificationTypeId,
+			Content: content,
+		})
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 接力的段数范围
+const (
+	minRelayLegs = 2
+	maxRelayLegs = 10
+)
+
+type RelayService interface {
+	Start(startReq *models.RelayStartReq) (models.RelayResp, error)
+	Submit(submitReq *models.RelaySubmitReq) (models.RelayResp, error)
+	List(relayReq *models.RelayReq) (models.RelayListResp, error)
+	event(sizes []models.RelaySize) string
+	updateBest(relay *models.Relay) error
+}
+
+type RelayImpl struct{}
+
+// event 根据各段尺寸生成项目名称, 相同尺寸序列的接力在同一排行榜中比较
+func (RelayImpl) event(sizes []models.RelaySize) string {
+	labels := make([]string, len(sizes))
+	for i, size := range sizes {
+		labels[i] = fmt.Sprintf("%dx%d", size.Width, size.Height)
+	}
+
+	return strings.Join(labels, ",")
+}
+
+// Start 开始接力, 一次下发所有段的打乱, 用户未完成的接力会被放弃
+func (RelayImpl) Start(startReq *models.RelayStartReq) (models.RelayResp, error) {
+	if len(startReq.Sizes) < minRelayLegs || len(startReq.Sizes) > maxRelayLegs {
+		return models.RelayResp{}, fmt.Errorf("接力段数需要在%d到%d之间", minRelayLegs, maxRelayLegs)
+	}
+
+	for i := range startReq.Sizes {
+		size := &startReq.Sizes[i]
+		size.Dimension, size.Width, size.Height = utils.NormalizeSize(size.Dimension, size.Width, size.Height)
+
+		if size.Width < minScrambleSize || size.Width > maxScrambleSize ||
+			size.Height < minScrambleSize || size.Height > maxScrambleSize {
+			return models.RelayResp{}, errors.New("尺寸不合法")
+		}
+	}
+
+	// 放弃未完成的接力
+	err := database.GetMySQL().Table("relay").
+		Where("user_id = ? AND status = ?", startReq.UserId, 1).
+		Update("status", 3).Error
+	if err != nil {
+		return models.RelayResp{}, errors.New("更新接力失败")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	relay := models.Relay{
+		Id:       snowflake.NextVal(),
+		UserId:   startReq.UserId,
+		Event:    Relay.event(startReq.Sizes),
+		LegCount: len(startReq.Sizes),
+		Status:   1,
+	}
+
+	err = database.GetMySQL().Create(&relay).Error
+	if err != nil {
+		return models.RelayResp{}, errors.New("新增接力失败")
+	}
+
+	for i, size := range startReq.Sizes {
+		idx, scramble, difficulty := Scramble.generate(size.Width, size.Height)
+
+		scrambleModel := &models.Scramble{
+			Dimension:  size.Dimension,
+			Width:      size.Width,
+			Height:     size.Height,
+			Idx:        idx,
+			Scramble:   puzzle.FormatScramble(scramble),
+			Version:    puzzle.CurrentVersion,
+			Difficulty: difficulty,
+		}
+
+		err = Scramble.Insert(scrambleModel)
+		if err != nil {
+			return models.RelayResp{}, errors.New("新增打乱失败")
+		}
+
+		err = database.GetMySQL().Create(&models.RelayLeg{
+			Id:              snowflake.NextVal(),
+			RelayId:         relay.Id,
+			Seq:             i + 1,
+			Dimension:       size.Dimension,
+			Width:           size.Width,
+			Height:          size.Height,
+			ScrambleId:      scrambleModel.Id,
+			Idx:             scrambleModel.Idx,
+			Scramble:        scrambleModel.Scramble,
+			ScrambleVersion: scrambleModel.Version,
+		}).Error
+		if err != nil {
+			return models.RelayResp{}, errors.New("新增接力分段失败")
+		}
+	}
+
+	relayList, err := Relay.List(&models.RelayReq{Id: relay.Id})
+	if err != nil || relayList.Total == 0 {
+		return models.RelayResp{}, errors.New("查询接力失败")
+	}
+
+	return relayList.Records[0], nil
+}
+
+// Submit 提交接力, 逐段校验解法, 总耗时为各段耗时之和
+func (RelayImpl) Submit(submitReq *models.RelaySubmitReq) (models.RelayResp, error) {
+	if submitReq.IdStr != "" {
+		submitReq.Id, _ = strconv.ParseInt(submitReq.IdStr, 10, 64)
+	}
+
+	var relay models.Relay
+	err := database.GetMySQL().Table("relay").
+		Where("id = ? AND user_id = ?", submitReq.Id, submitReq.UserId).
+		First(&relay).Error
+	if err != nil {
+		return models.RelayResp{}, errors.New("接力不存在")
+	}
+
+	if relay.Status != 1 {
+		return models.RelayResp{}, errors.New("接力已结束")
+	}
+
+	if len(submitReq.Legs) != relay.LegCount {
+		return models.RelayResp{}, errors.New("需要提交所有分段")
+	}
+
+	var legs []models.RelayLeg
+	err = database.GetMySQL().Table("relay_leg").Where("relay_id = ?", relay.Id).Order("seq asc").Find(&legs).Error
+	if err != nil || len(legs) != relay.LegCount {
+		return models.RelayResp{}, errors.New("查询接力分段失败")
+	}
+
+	legReqMap := make(map[int]models.RelayLegReq, len(submitReq.Legs))
+	for _, legReq := range submitReq.Legs {
+		legReqMap[legReq.Seq] = legReq
+	}
+
+	for i := range legs {
+		leg := &legs[i]
+
+		legReq, ok := legReqMap[leg.Seq]
+		if !ok {
+			return models.RelayResp{}, fmt.Errorf("第%d段成绩不能为空", leg.Seq)
+		}
+
+		if legReq.Duration <= 0 || legReq.Step <= 0 || legReq.Solution == "" {
+			return models.RelayResp{}, fmt.Errorf("第%d段成绩不完整", leg.Seq)
+		}
+
+		encryptionParams := utils.EncryptionParams{
+			Dimension: leg.Dimension,
+			Width:     leg.Width,
+			Height:    leg.Height,
+			RandomIdx: leg.Idx,
+			StepCount: legReq.Step,
+			Scramble:  leg.Scramble,
+			Solution:  legReq.Solution,
+			Version:   leg.ScrambleVersion,
+		}
+
+		if !encryptionParams.VerifyScramble() {
+			return models.RelayResp{}, fmt.Errorf("第%d段校验失败", leg.Seq)
+		}
+
+		leg.Duration = legReq.Duration
+		leg.Step = legReq.Step
+		leg.Solution = legReq.Solution
+
+		relay.Duration += leg.Duration
+		relay.Step += leg.Step
+	}
+
+	relay.Status = 2
+
+	err = database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		for _, leg := range legs {
+			err := tx.Table("relay_leg").Where("id = ?", leg.Id).Updates(map[string]any{
+				"duration": leg.Duration,
+				"step":     leg.Step,
+				"solution": leg.Solution,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// 只更新进行中的接力, 避免重复提交
+		result := tx.Table("relay").Where("id = ? AND status = ?", relay.Id, 1).Updates(map[string]any{
+			"duration": relay.Duration,
+			"step":     relay.Step,
+			"status":   relay.Status,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("接力已结束")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.RelayResp{}, errors.New("提交接力失败")
+	}
+
+	// 更新最佳接力记录
+	err = Relay.updateBest(&relay)
+	if err != nil {
+		return models.RelayResp{}, err
+	}
+
+	relayList, err := Relay.List(&models.RelayReq{Id: relay.Id})
+	if err != nil || relayList.Total == 0 {
+		return models.RelayResp{}, errors.New("查询接力失败")
+	}
+
+	return relayList.Records[0], nil
+}
+
+// List 接力列表
+func (RelayImpl) List(relayReq *models.RelayReq) (models.RelayListResp, error) {
+	var relayListResp models.RelayListResp
+
+	if relayReq.IdStr != "" {
+		relayReq.Id, _ = strconv.ParseInt(relayReq.IdStr, 10, 64)
+	}
+
+	if relayReq.UserIdStr != "" {
+		relayReq.UserId, _ = strconv.ParseInt(relayReq.UserIdStr, 10, 64)
+	}
+
+	db := database.GetMySQL().Table("relay").Order("id " + utils.SortDirection(relayReq.Sorted, "desc"))
+
+	if relayReq.Id != 0 {
+		db.Where("id = ?", relayReq.Id)
+	}
+
+	if relayReq.UserId != 0 {
+		db.Where("user_id = ?", relayReq.UserId)
+	}
+
+	if relayReq.Event != "" {
+		db.Where("event = ?", relayReq.Event)
+	}
+
+	if relayReq.Status != 0 {
+		db.Where("status = ?", relayReq.Status)
+	}
+
+	// 查询总数
+	err := db.Count(&relayListResp.Total).Error
+	if err != nil {
+		return relayListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if relayReq.Pagination.Page > 0 && relayReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&relayReq.Pagination))
+	}
+
+	err = db.Preload("UserInfo").
+		Preload("Legs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq asc")
+		}).
+		Find(&relayListResp.Records).Error
+	if err != nil {
+		return relayListResp, errors.New("查询失败")
+	}
+
+	// 进行中的接力不返回解法
+	for i := range relayListResp.Records {
+		if relayListResp.Records[i].Status == 1 {
+			for j := range relayListResp.Records[i].Legs {
+				relayListResp.Records[i].Legs[j].Solution = ""
+			}
+		}
+	}
+
+	return relayListResp, nil
+}
+
+// updateBest 更新最佳接力记录
+func (RelayImpl) updateBest(relay *models.Relay) error {
+	recordBestRelay, err := RecordBestRelay.List(&models.RecordBestRelayReq{
+		UserId: relay.UserId,
+		Event:  relay.Event,
+		Pagination: utils.Pagination{
+			Page:     1,
+			PageSize: 1,
+		},
+	})
+	if err != nil {
+		return errors.New("获取最佳接力记录失败")
+	}
+
+	// 若无最佳接力记录, 则直接插入
+	if recordBestRelay.Total == 0 {
+		snowflake := utils.Snowflake{}
+
+		err = RecordBestRelay.Insert(&models.RecordBestRelay{
+			Id:             snowflake.NextVal(),
+			UserId:         relay.UserId,
+			Event:          relay.Event,
+			RelayId:        relay.Id,
+			RecordDuration: relay.Duration,
+			RecordStep:     relay.Step,
+		})
+		if err != nil {
+			return errors.New("新增最佳接力记录失败")
+		}
+
+		return nil
+	}
+
+	// 没有打破记录
+	if relay.Duration >= recordBestRelay.Records[0].RecordDuration {
+		return nil
+	}
+
+	id, _ := strconv.ParseInt(recordBestRelay.Records[0].Id, 10, 64)
+
+	err = RecordBestRelay.Update(&models.RecordBestRelay{
+		Id:               id,
+		UserId:           relay.UserId,
+		Event:            relay.Event,
+		RelayId:          relay.Id,
+		RecordDuration:   relay.Duration,
+		RecordStep:       relay.Step,
+		RecordBreakCount: recordBestRelay.Records[0].RecordBreakCount + 1,
+	})
+	if err != nil {
+		return errors.New("更新最佳接力记录失败")
+	}
+
+	return nil
+}
@@ -2,29 +2,46 @@ package services
 
 import (
 	"errors"
-	"fmt"
 	"puzzle/app/models"
+	"puzzle/config"
 	"puzzle/database"
+	"puzzle/puzzle"
 	"puzzle/utils"
 	"strconv"
-	"strings"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// 服务端下发打乱的边长范围
+const (
+	minScrambleSize = 3
+	maxScrambleSize = puzzle.MaxSize
+)
+
+const (
+	defaultScrambleMaxAttempts    = 100     // 默认重新生成打乱的最大次数
+	defaultScrambleMaxSearchNodes = 1000000 // 默认生成一次打乱时计算最优步数最多搜索的节点总数
 )
 
 type ScrambleSerivce interface {
 	check(scramble *models.Scramble) error
 	Insert(scramble *models.Scramble) error
+	insert(tx *gorm.DB, scramble *models.Scramble) error
 	List(scrambleReq *models.ScrambleReq) (models.ScrambleListResp, error)
 	GetNewScamble(getNewScrambleReq *models.GetNewScambleReq) (models.ScrambleResp, error)
 	GetUserScramble(getNewScrambleReq *models.GetNewScambleReq) (models.ScrambleResp, error)
+	GetDifficultyByKeys(keys []models.ScrambleKey) (map[models.ScrambleKey]models.ScrambleDifficulty, error)
+	generate(width, height int) (int64, []int, models.ScrambleDifficulty)
+	isTooEasy(difficulty models.ScrambleDifficulty) bool
 }
 
 type ScrambleImpl struct{}
 
 // check 检查数据
 func (ScrambleImpl) check(scramble *models.Scramble) error {
-	if scramble.Dimension == 0 {
-		return errors.New("阶数不能为空")
+	if scramble.Width == 0 || scramble.Height == 0 {
+		return errors.New("尺寸不能为空")
 	}
 
 	if scramble.Scramble == "" {
@@ -40,15 +57,24 @@ func (ScrambleImpl) check(scramble *models.Scramble) error {
 
 // Insert 插入打乱公式
 func (ScrambleImpl) Insert(scramble *models.Scramble) error {
+	return Scramble.insert(database.GetMySQL(), scramble)
+}
+
+// insert 在指定的事务中插入打乱公式
+func (ScrambleImpl) insert(tx *gorm.DB, scramble *models.Scramble) error {
 	if err := Scramble.check(scramble); err != nil {
 		return err
 	}
 
+	if scramble.Version == 0 {
+		scramble.Version = puzzle.VersionLegacy
+	}
+
 	snowflake := utils.Snowflake{}
 	scramble.Id = snowflake.NextVal()
 	scramble.Status = 1
 
-	return database.GetMySQL().Create(scramble).Error
+	return tx.Create(scramble).Error
 }
 
 // List 获取打乱公式列表
@@ -60,8 +86,14 @@ func (ScrambleImpl) List(scrambleReq *models.ScrambleReq) (models.ScrambleListRe
 		db.Where("id = ?", scrambleReq.Id)
 	}
 
-	if scrambleReq.Dimension != 0 {
-		db.Where("dimension = ?", scrambleReq.Dimension)
+	_, scrambleReq.Width, scrambleReq.Height = utils.NormalizeSize(scrambleReq.Dimension, scrambleReq.Width, scrambleReq.Height)
+
+	if scrambleReq.Width != 0 {
+		db.Where("width = ?", scrambleReq.Width)
+	}
+
+	if scrambleReq.Height != 0 {
+		db.Where("height = ?", scrambleReq.Height)
 	}
 
 	if scrambleReq.Idx != 0 {
@@ -72,6 +104,10 @@ func (ScrambleImpl) List(scrambleReq *models.ScrambleReq) (models.ScrambleListRe
 		db.Where("scramble = ?", scrambleReq.Scramble)
 	}
 
+	if scrambleReq.Version != 0 {
+		db.Where("version = ?", scrambleReq.Version)
+	}
+
 	if scrambleReq.Status != 0 {
 		db.Where("status = ?", scrambleReq.Status)
 	}
@@ -108,10 +144,24 @@ func (ScrambleImpl) List(scrambleReq *models.ScrambleReq) (models.ScrambleListRe
 func (ScrambleImpl) GetNewScamble(getNewScrambleReq *models.GetNewScambleReq) (models.ScrambleResp, error) {
 	var scrambleResp models.ScrambleResp
 
+	getNewScrambleReq.Dimension, getNewScrambleReq.Width, getNewScrambleReq.Height = utils.NormalizeSize(getNewScrambleReq.Dimension, getNewScrambleReq.Width, getNewScrambleReq.Height)
+
+	if getNewScrambleReq.Width < minScrambleSize || getNewScrambleReq.Width > maxScrambleSize ||
+		getNewScrambleReq.Height < minScrambleSize || getNewScrambleReq.Height > maxScrambleSize {
+		return scrambleResp, errors.New("尺寸不合法")
+	}
+
+	if getNewScrambleReq.Event < 0 || getNewScrambleReq.Event > 2 {
+		return scrambleResp, errors.New("项目不合法")
+	}
+
 	// 获取用户当前的完成状态
 	scrambledUserStatusReq := models.ScrambledUserStatusReq{
 		UserId:    getNewScrambleReq.UserId,
 		Dimension: getNewScrambleReq.Dimension,
+		Width:     getNewScrambleReq.Width,
+		Height:    getNewScrambleReq.Height,
+		Event:     getNewScrambleReq.Event,
 		Pagination: utils.Pagination{
 			PageSize: 1,
 			Page:     1,
@@ -125,18 +175,21 @@ func (ScrambleImpl) GetNewScamble(getNewScrambleReq *models.GetNewScambleReq) (m
 
 	// 如果没有找到用户的完成状态，或是用户的完成状态为已完成，则生成新的打乱公式
 	if scrambledUserStatusResp.Total == 0 || scrambledUserStatusResp.Records[0].Status == 2 {
-		idx := time.Now().UnixMilli()
-		scramble := utils.Shuffle(getNewScrambleReq.Dimension, int(idx))
-		scrambleStr := strings.Trim(strings.Replace(fmt.Sprint(scramble), " ", ",", -1), "[]")
+		idx, scramble, difficulty := Scramble.generate(getNewScrambleReq.Width, getNewScrambleReq.Height)
+		scrambleStr := puzzle.FormatScramble(scramble)
 
 		snowflake := utils.Snowflake{}
 
 		scrambleModel := &models.Scramble{
-			Id:        snowflake.NextVal(),
-			Dimension: getNewScrambleReq.Dimension,
-			Idx:       idx,
-			Scramble:  scrambleStr,
-			Status:    1,
+			Id:         snowflake.NextVal(),
+			Dimension:  getNewScrambleReq.Dimension,
+			Width:      getNewScrambleReq.Width,
+			Height:     getNewScrambleReq.Height,
+			Idx:        idx,
+			Scramble:   scrambleStr,
+			Version:    puzzle.CurrentVersion,
+			Difficulty: difficulty,
+			Status:     1,
 		}
 
 		err = Scramble.Insert(scrambleModel)
@@ -150,7 +203,10 @@ func (ScrambleImpl) GetNewScamble(getNewScrambleReq *models.GetNewScambleReq) (m
 			scrambledUserStatus := &models.ScrambledUserStatus{
 				UserId:     getNewScrambleReq.UserId,
 				Dimension:  getNewScrambleReq.Dimension,
+				Width:      getNewScrambleReq.Width,
+				Height:     getNewScrambleReq.Height,
 				ScrambleId: scrambleModel.Id,
+				Event:      getNewScrambleReq.Event,
 				Status:     1,
 			}
 
@@ -176,13 +232,17 @@ func (ScrambleImpl) GetNewScamble(getNewScrambleReq *models.GetNewScambleReq) (m
 
 		// 返回打乱公式
 		scrambleResp = models.ScrambleResp{
-			Id:        scrambleModel.Id,
-			Dimension: scrambleModel.Dimension,
-			Idx:       scrambleModel.Idx,
-			Scramble:  scrambleModel.Scramble,
-			Status:    scrambleModel.Status,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			Id:         scrambleModel.Id,
+			Dimension:  scrambleModel.Dimension,
+			Width:      scrambleModel.Width,
+			Height:     scrambleModel.Height,
+			Idx:        scrambleModel.Idx,
+			Scramble:   scrambleModel.Scramble,
+			Version:    scrambleModel.Version,
+			Difficulty: scrambleModel.Difficulty,
+			Status:     scrambleModel.Status,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
 		}
 		return scrambleResp, nil
 	}
@@ -194,10 +254,15 @@ func (ScrambleImpl) GetNewScamble(getNewScrambleReq *models.GetNewScambleReq) (m
 func (ScrambleImpl) GetUserScramble(getNewScrambleReq *models.GetNewScambleReq) (models.ScrambleResp, error) {
 	var scrambleResp models.ScrambleResp
 
+	getNewScrambleReq.Dimension, getNewScrambleReq.Width, getNewScrambleReq.Height = utils.NormalizeSize(getNewScrambleReq.Dimension, getNewScrambleReq.Width, getNewScrambleReq.Height)
+
 	// 获取用户当前的打乱信息
 	scrambledUserStatusReq := &models.ScrambledUserStatusReq{
 		UserId:    getNewScrambleReq.UserId,
 		Dimension: getNewScrambleReq.Dimension,
+		Width:     getNewScrambleReq.Width,
+		Height:    getNewScrambleReq.Height,
+		Event:     getNewScrambleReq.Event,
 		Pagination: utils.Pagination{
 			PageSize: 1,
 			Page:     1,
@@ -230,3 +295,95 @@ func (ScrambleImpl) GetUserScramble(getNewScrambleReq *models.GetNewScambleReq)
 
 	return scrambleListResp.Records[0], nil
 }
+
+// GetDifficultyByKeys 根据打乱的随机数、尺寸与生成器版本批量查询打乱难度
+func (ScrambleImpl) GetDifficultyByKeys(keys []models.ScrambleKey) (map[models.ScrambleKey]models.ScrambleDifficulty, error) {
+	idxs := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		idxs = append(idxs, key.Idx)
+	}
+
+	var scrambles []models.Scramble
+	err := database.GetMySQL().Table("scramble").Where("idx in ?", idxs).Find(&scrambles).Error
+	if err != nil {
+		return nil, errors.New("查询打乱难度失败")
+	}
+
+	difficultyMap := make(map[models.ScrambleKey]models.ScrambleDifficulty, len(scrambles))
+	for _, scramble := range scrambles {
+		key := models.ScrambleKey{
+			Idx:     scramble.Idx,
+			Width:   scramble.Width,
+			Height:  scramble.Height,
+			Version: scramble.Version,
+		}
+		difficultyMap[key] = scramble.Difficulty
+	}
+
+	return difficultyMap, nil
+}
+
+// generate 生成打乱并计算难度, 按配置跳过过于简单的打乱, 超过最大次数后使用最后一次生成的打乱
+// 所有尝试共享最优步数的搜索节点总量, 用完后只按曼哈顿距离筛选, 避免在请求中进行过多的搜索
+func (ScrambleImpl) generate(width, height int) (int64, []int, models.ScrambleDifficulty) {
+	maxAttempts := config.Settings.Scramble.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = defaultScrambleMaxAttempts
+	}
+
+	remainingNodes := config.Settings.Scramble.MaxSearchNodes
+	if remainingNodes == 0 {
+		remainingNodes = defaultScrambleMaxSearchNodes
+	}
+
+	generator, _ := puzzle.GetGenerator(puzzle.CurrentVersion)
+
+	var (
+		idx        int64
+		scramble   []int
+		difficulty models.ScrambleDifficulty
+	)
+
+	for i := 0; i < maxAttempts; i++ {
+		idx = puzzle.GenerateSeed()
+		scramble = generator.Generate(width, height, idx)
+
+		board, _ := puzzle.NewBoard(width, height, scramble)
+
+		// 曼哈顿距离已低于下限时无需搜索最优步数
+		maxNodes := min(remainingNodes, puzzle.DefaultMaxSearchNodes)
+		if minDistance := config.Settings.Scramble.MinManhattanDistance; minDistance > 0 && board.ManhattanDistance() < minDistance {
+			maxNodes = 0
+		}
+
+		d, nodes := board.DifficultyWithin(maxNodes)
+		remainingNodes -= nodes
+
+		difficulty = models.ScrambleDifficulty{
+			ManhattanDistance: d.ManhattanDistance,
+			LinearConflicts:   d.LinearConflicts,
+			OptimalLength:     d.OptimalLength,
+		}
+
+		if !Scramble.isTooEasy(difficulty) {
+			break
+		}
+	}
+
+	return idx, scramble, difficulty
+}
+
+// isTooEasy 判断打乱是否低于配置的排行榜难度下限
+func (ScrambleImpl) isTooEasy(difficulty models.ScrambleDifficulty) bool {
+	settings := config.Settings.Scramble
+
+	if settings.MinManhattanDistance > 0 && difficulty.ManhattanDistance < settings.MinManhattanDistance {
+		return true
+	}
+
+	if settings.MinOptimalLength > 0 && difficulty.OptimalLength >= 0 && difficulty.OptimalLength < settings.MinOptimalLength {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,506 @@
+package services
+
+import (
+	"errors"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 打乱组的最大打乱数量
+const maxScrambleSetCount = 50
+
+type ScrambleSetService interface {
+	Insert(setReq *models.ScrambleSetReq) (models.ScrambleSetResp, error)
+	List(setReq *models.ScrambleSetReq) (models.ScrambleSetListResp, error)
+	StartSession(sessionReq *models.ScrambleSessionReq) (models.ScrambleSessionResp, error)
+	GetSessionScramble(sessionReq *models.ScrambleSessionReq) (models.ScrambleSessionResp, error)
+	Result(setReq *models.ScrambleSetReq) (models.ScrambleSetResultResp, error)
+	getSet(setId int64) (models.ScrambleSet, error)
+	getItem(setId int64, seq int) (models.ScrambleSetItem, error)
+	finished(setId, userId int64) (bool, error)
+	checkVisible(record *models.Record, userId int64) error
+	visibleScope(userId int64) func(db *gorm.DB) *gorm.DB
+}
+
+type ScrambleSetImpl struct{}
+
+// Insert 创建打乱组, 一次性生成组内所有打乱
+func (ScrambleSetImpl) Insert(setReq *models.ScrambleSetReq) (models.ScrambleSetResp, error) {
+	var setResp models.ScrambleSetResp
+
+	setReq.Name = strings.TrimSpace(setReq.Name)
+	if setReq.Name == "" {
+		return setResp, errors.New("名称不能为空")
+	}
+
+	if len([]rune(setReq.Name)) > 50 {
+		return setResp, errors.New("名称不能超过50字")
+	}
+
+	setReq.Dimension, setReq.Width, setReq.Height = utils.NormalizeSize(setReq.Dimension, setReq.Width, setReq.Height)
+
+	if setReq.Width < minScrambleSize || setReq.Width > maxScrambleSize ||
+		setReq.Height < minScrambleSize || setReq.Height > maxScrambleSize {
+		return setResp, errors.New("尺寸不合法")
+	}
+
+	if setReq.Count < 1 || setReq.Count > maxScrambleSetCount {
+		return setResp, errors.New("打乱数量不合法")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	set := models.ScrambleSet{
+		Id:        snowflake.NextVal(),
+		Name:      setReq.Name,
+		CreatorId: setReq.CreatorId,
+		Dimension: setReq.Dimension,
+		Width:     setReq.Width,
+		Height:    setReq.Height,
+		Count:     setReq.Count,
+		Status:    1,
+	}
+
+	// 生成打乱耗时较长, 在事务外生成, 打乱组、打乱与组内打乱在同一事务中写入
+	scrambleModels := make([]*models.Scramble, 0, setReq.Count)
+	for seq := 1; seq <= setReq.Count; seq++ {
+		idx, scramble, difficulty := Scramble.generate(set.Width, set.Height)
+
+		scrambleModels = append(scrambleModels, &models.Scramble{
+			Dimension:  set.Dimension,
+			Width:      set.Width,
+			Height:     set.Height,
+			Idx:        idx,
+			Scramble:   puzzle.FormatScramble(scramble),
+			Version:    puzzle.CurrentVersion,
+			Difficulty: difficulty,
+		})
+	}
+
+	err := database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&set).Error; err != nil {
+			return errors.New("新增打乱组失败")
+		}
+
+		for i, scrambleModel := range scrambleModels {
+			if err := Scramble.insert(tx, scrambleModel); err != nil {
+				return errors.New("新增打乱失败")
+			}
+
+			err := tx.Create(&models.ScrambleSetItem{
+				Id:         snowflake.NextVal(),
+				SetId:      set.Id,
+				Seq:        i + 1,
+				ScrambleId: scrambleModel.Id,
+			}).Error
+			if err != nil {
+				return errors.New("新增打乱组打乱失败")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return setResp, err
+	}
+
+	setResp = models.ScrambleSetResp{
+		Id:        strconv.FormatInt(set.Id, 10),
+		Name:      set.Name,
+		CreatorId: strconv.FormatInt(set.CreatorId, 10),
+		Dimension: set.Dimension,
+		Width:     set.Width,
+		Height:    set.Height,
+		Count:     set.Count,
+		Status:    set.Status,
+	}
+
+	return setResp, nil
+}
+
+// List 打乱组列表
+func (ScrambleSetImpl) List(setReq *models.ScrambleSetReq) (models.ScrambleSetListResp, error) {
+	var setListResp models.ScrambleSetListResp
+
+	if setReq.IdStr != "" {
+		setReq.Id, _ = strconv.ParseInt(setReq.IdStr, 10, 64)
+	}
+
+	db := database.GetMySQL().Table("scramble_set").Where("status = ?", 1).Order("created_at " + utils.SortDirection(setReq.Sorted, "desc"))
+
+	if setReq.Id != 0 {
+		db.Where("id = ?", setReq.Id)
+	}
+
+	if setReq.CreatorId != 0 {
+		db.Where("creator_id = ?", setReq.CreatorId)
+	}
+
+	if setReq.Name != "" {
+		db.Where("name Like ?", "%"+setReq.Name+"%")
+	}
+
+	_, setReq.Width, setReq.Height = utils.NormalizeSize(setReq.Dimension, setReq.Width, setReq.Height)
+
+	if setReq.Width != 0 {
+		db.Where("width = ?", setReq.Width)
+	}
+
+	if setReq.Height != 0 {
+		db.Where("height = ?", setReq.Height)
+	}
+
+	// 查询总数
+	err := db.Count(&setListResp.Total).Error
+	if err != nil {
+		return setListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if setReq.Pagination.Page > 0 && setReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&setReq.Pagination))
+	}
+
+	// 查询列表
+	err = db.Preload("CreatorInfo").Find(&setListResp.Records).Error
+	if err != nil {
+		return setListResp, errors.New("查询失败")
+	}
+
+	return setListResp, nil
+}
+
+// StartSession 开始挑战打乱组, 每个用户对同一打乱组只有一次挑战, 重复开始时返回已有的挑战
+func (ScrambleSetImpl) StartSession(sessionReq *models.ScrambleSessionReq) (models.ScrambleSessionResp, error) {
+	if sessionReq.SetIdStr != "" {
+		sessionReq.SetId, _ = strconv.ParseInt(sessionReq.SetIdStr, 10, 64)
+	}
+
+	set, err := ScrambleSet.getSet(sessionReq.SetId)
+	if err != nil {
+		return models.ScrambleSessionResp{}, err
+	}
+
+	var session models.ScrambleSession
+	err = database.GetMySQL().Table("scramble_session").
+		Where("set_id = ? AND user_id = ?", set.Id, sessionReq.UserId).
+		Limit(1).
+		Find(&session).Error
+	if err != nil {
+		return models.ScrambleSessionResp{}, errors.New("查询打乱组挑战失败")
+	}
+
+	if session.Id == 0 {
+		item, err := ScrambleSet.getItem(set.Id, 1)
+		if err != nil {
+			return models.ScrambleSessionResp{}, err
+		}
+
+		snowflake := utils.Snowflake{}
+
+		session = models.ScrambleSession{
+			Id:      snowflake.NextVal(),
+			SetId:   set.Id,
+			UserId:  sessionReq.UserId,
+			Current: 1,
+			Status:  1,
+		}
+
+		err = database.GetMySQL().Create(&session).Error
+		if err != nil {
+			return models.ScrambleSessionResp{}, errors.New("新增打乱组挑战失败")
+		}
+
+		err = ScrambledUserStatus.Insert(&models.ScrambledUserStatus{
+			UserId:     sessionReq.UserId,
+			Dimension:  set.Dimension,
+			Width:      set.Width,
+			Height:     set.Height,
+			ScrambleId: item.ScrambleId,
+			SessionId:  session.Id,
+		})
+		if err != nil {
+			return models.ScrambleSessionResp{}, err
+		}
+	}
+
+	return ScrambleSet.GetSessionScramble(&models.ScrambleSessionReq{
+		Id:     session.Id,
+		UserId: sessionReq.UserId,
+	})
+}
+
+// GetSessionScramble 获取挑战的当前打乱, 当前打乱已完成时切换到下一个打乱
+func (ScrambleSetImpl) GetSessionScramble(sessionReq *models.ScrambleSessionReq) (models.ScrambleSessionResp, error) {
+	var sessionResp models.ScrambleSessionResp
+
+	if sessionReq.IdStr != "" {
+		sessionReq.Id, _ = strconv.ParseInt(sessionReq.IdStr, 10, 64)
+	}
+
+	var session models.ScrambleSession
+	err := database.GetMySQL().Table("scramble_session").
+		Where("id = ? AND user_id = ?", sessionReq.Id, sessionReq.UserId).
+		First(&session).Error
+	if err != nil {
+		return sessionResp, errors.New("打乱组挑战不存在")
+	}
+
+	set, err := ScrambleSet.getSet(session.SetId)
+	if err != nil {
+		return sessionResp, err
+	}
+
+	sessionResp = models.ScrambleSessionResp{
+		Id:      strconv.FormatInt(session.Id, 10),
+		SetId:   strconv.FormatInt(session.SetId, 10),
+		Current: session.Current,
+		Count:   set.Count,
+		Status:  session.Status,
+	}
+
+	if session.Status == 2 {
+		return sessionResp, nil
+	}
+
+	statusList, err := ScrambledUserStatus.List(&models.ScrambledUserStatusReq{
+		UserId:    session.UserId,
+		Width:     set.Width,
+		Height:    set.Height,
+		SessionId: session.Id,
+		Pagination: utils.Pagination{
+			PageSize: 1,
+			Page:     1,
+		},
+	})
+	if err != nil || statusList.Total == 0 {
+		return sessionResp, errors.New("查询用户打乱公式状态失败")
+	}
+
+	status := statusList.Records[0]
+
+	// 当前打乱已完成, 切换到下一个打乱
+	if status.Status == 2 {
+		id, _ := strconv.ParseInt(status.Id, 10, 64)
+
+		if session.Current >= set.Count {
+			err = database.GetMySQL().Table("scramble_session").Where("id = ?", session.Id).Update("status", 2).Error
+			if err != nil {
+				return sessionResp, errors.New("更新打乱组挑战失败")
+			}
+
+			sessionResp.Status = 2
+			return sessionResp, nil
+		}
+
+		item, err := ScrambleSet.getItem(set.Id, session.Current+1)
+		if err != nil {
+			return sessionResp, err
+		}
+
+		err = ScrambledUserStatus.Update(&models.ScrambledUserStatus{
+			Id:         id,
+			ScrambleId: item.ScrambleId,
+			Status:     1,
+		})
+		if err != nil {
+			return sessionResp, err
+		}
+
+		err = database.GetMySQL().Table("scramble_session").Where("id = ?", session.Id).Update("current", session.Current+1).Error
+		if err != nil {
+			return sessionResp, errors.New("更新打乱组挑战失败")
+		}
+
+		status.ScrambleId = item.ScrambleId
+		sessionResp.Current = session.Current + 1
+	}
+
+	scrambleList, err := Scramble.List(&models.ScrambleReq{Id: status.ScrambleId})
+	if err != nil || scrambleList.Total == 0 {
+		return sessionResp, errors.New("未找到打乱公式")
+	}
+
+	sessionResp.Scramble = &scrambleList.Records[0]
+
+	return sessionResp, nil
+}
+
+// Result 打乱组成绩对比, 按完成数量降序, 总耗时升序排列, 查看者完成挑战前只返回自己的成绩
+func (ScrambleSetImpl) Result(setReq *models.ScrambleSetReq) (models.ScrambleSetResultResp, error) {
+	var resultResp models.ScrambleSetResultResp
+
+	if setReq.IdStr != "" {
+		setReq.Id, _ = strconv.ParseInt(setReq.IdStr, 10, 64)
+	}
+
+	setList, err := ScrambleSet.List(&models.ScrambleSetReq{Id: setReq.Id})
+	if err != nil {
+		return resultResp, err
+	}
+
+	if setList.Total == 0 {
+		return resultResp, errors.New("打乱组不存在")
+	}
+
+	resultResp.SetInfo = setList.Records[0]
+	resultResp.Results = make([]models.ScrambleSetResult, 0)
+
+	// 打乱随机数与序号的对应关系
+	var scrambles []struct {
+		Idx int64
+		Seq int
+	}
+	err = database.GetMySQL().Table("scramble_set_item").
+		Select("scramble.idx, scramble_set_item.seq").
+		Joins("JOIN scramble ON scramble.id = scramble_set_item.scramble_id").
+		Where("scramble_set_item.set_id = ?", setReq.Id).
+		Find(&scrambles).Error
+	if err != nil {
+		return resultResp, errors.New("查询打乱组打乱失败")
+	}
+
+	seqMap := make(map[string]int, len(scrambles))
+	for _, scramble := range scrambles {
+		seqMap[strconv.FormatInt(scramble.Idx, 10)] = scramble.Seq
+	}
+
+	finished, err := ScrambleSet.finished(setReq.Id, setReq.UserId)
+	if err != nil {
+		return resultResp, err
+	}
+
+	db := database.GetMySQL().Table("record").
+		Where("type = ? AND status = ?", 4, 1).
+		Where("session_id IN (?)", database.GetMySQL().Table("scramble_session").Select("id").Where("set_id = ?", setReq.Id))
+
+	if !finished {
+		db.Where("user_id = ?", setReq.UserId)
+	}
+
+	var records []models.RecordResp
+	err = db.Order("id asc").
+		Preload("UserInfo").
+		Find(&records).Error
+	if err != nil {
+		return resultResp, errors.New("查询打乱组成绩失败")
+	}
+
+	resultMap := make(map[string]*models.ScrambleSetResult)
+	userIds := make([]string, 0)
+
+	for _, record := range records {
+		result, ok := resultMap[record.UserId]
+		if !ok {
+			result = &models.ScrambleSetResult{
+				UserId:   record.UserId,
+				UserInfo: record.UserInfo,
+				Items:    make([]models.ScrambleSetResultItem, 0),
+			}
+			resultMap[record.UserId] = result
+			userIds = append(userIds, record.UserId)
+		}
+
+		result.SolvedCount++
+		result.TotalDuration += record.Duration
+		result.TotalStep += record.Step
+		result.Items = append(result.Items, models.ScrambleSetResultItem{
+			Seq:      seqMap[record.Idx],
+			RecordId: record.Id,
+			Duration: record.Duration,
+			Step:     record.Step,
+		})
+	}
+
+	for _, userId := range userIds {
+		result := resultMap[userId]
+		result.AverageDuration = result.TotalDuration / result.SolvedCount
+		resultResp.Results = append(resultResp.Results, *result)
+	}
+
+	sort.SliceStable(resultResp.Results, func(i, j int) bool {
+		if resultResp.Results[i].SolvedCount != resultResp.Results[j].SolvedCount {
+			return resultResp.Results[i].SolvedCount > resultResp.Results[j].SolvedCount
+		}
+		return resultResp.Results[i].TotalDuration < resultResp.Results[j].TotalDuration
+	})
+
+	return resultResp, nil
+}
+
+// getSet 获取启用的打乱组
+func (ScrambleSetImpl) getSet(setId int64) (models.ScrambleSet, error) {
+	var set models.ScrambleSet
+	err := database.GetMySQL().Table("scramble_set").Where("id = ? AND status = ?", setId, 1).First(&set).Error
+	if err != nil {
+		return set, errors.New("打乱组不存在")
+	}
+
+	return set, nil
+}
+
+// finished 用户是否已完成打乱组挑战
+func (ScrambleSetImpl) finished(setId, userId int64) (bool, error) {
+	var count int64
+	err := database.GetMySQL().Table("scramble_session").
+		Where("set_id = ? AND user_id = ? AND status = ?", setId, userId, 2).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.New("查询打乱组挑战失败")
+	}
+
+	return count > 0, nil
+}
+
+// checkVisible 检查用户能否查看记录, 打乱组记录只有本人或已完成该打乱组挑战的用户可以查看
+func (ScrambleSetImpl) checkVisible(record *models.Record, userId int64) error {
+	if record.Type != 4 || record.UserId == userId {
+		return nil
+	}
+
+	var session models.ScrambleSession
+	err := database.GetMySQL().Table("scramble_session").Where("id = ?", record.SessionId).First(&session).Error
+	if err != nil {
+		return errors.New("打乱组挑战不存在")
+	}
+
+	finished, err := ScrambleSet.finished(session.SetId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !finished {
+		return errors.New("完成打乱组挑战后才能查看其他人的成绩")
+	}
+
+	return nil
+}
+
+// visibleScope 过滤用户不能查看的打乱组记录
+func (ScrambleSetImpl) visibleScope(userId int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		finishedSessions := database.GetMySQL().Table("scramble_session").Select("id").
+			Where("set_id IN (?)", database.GetMySQL().Table("scramble_session").Select("set_id").
+				Where("user_id = ? AND status = ?", userId, 2))
+
+		return db.Where("record.type <> ? OR record.user_id = ? OR record.session_id IN (?)", 4, userId, finishedSessions)
+	}
+}
+
+// getItem 获取打乱组中指定序号的打乱
+func (ScrambleSetImpl) getItem(setId int64, seq int) (models.ScrambleSetItem, error) {
+	var item models.ScrambleSetItem
+	err := database.GetMySQL().Table("scramble_set_item").Where("set_id = ? AND seq = ?", setId, seq).First(&item).Error
+	if err != nil {
+		return item, errors.New("打乱组打乱不存在")
+	}
+
+	return item, nil
+}
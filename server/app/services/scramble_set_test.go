@@ -0,0 +1,26 @@
+package services
+
+import (
+	"puzzle/app/models"
+	"testing"
+)
+
+func TestScrambleSetCheckVisible(t *testing.T) {
+	tests := []struct {
+		name   string
+		record models.Record
+		userId int64
+	}{
+		{"非打乱组记录", models.Record{Type: 2, UserId: 1, SessionId: 0}, 2},
+		{"挑战记录", models.Record{Type: 5, UserId: 1, SessionId: 0}, 2},
+		{"本人的打乱组记录", models.Record{Type: 4, UserId: 1, SessionId: 10}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ScrambleSet.checkVisible(&tt.record, tt.userId); err != nil {
+				t.Errorf("checkVisible() error = %v, want nil", err)
+			}
+		})
+	}
+}
@@ -21,8 +21,8 @@ func (ScrambledUserStatusImpl) check(scrambledUserStatus *models.ScrambledUserSt
 		return errors.New("用户ID不能为空")
 	}
 
-	if scrambledUserStatus.Dimension == 0 {
-		return errors.New("阶数不能为空")
+	if scrambledUserStatus.Width == 0 || scrambledUserStatus.Height == 0 {
+		return errors.New("尺寸不能为空")
 	}
 
 	if scrambledUserStatus.ScrambleId == 0 {
@@ -42,6 +42,10 @@ func (ScrambledUserStatusImpl) Insert(scrambledUserStatus *models.ScrambledUserS
 	scrambledUserStatus.Id = snowflake.NextVal()
 	scrambledUserStatus.Status = 1
 
+	if scrambledUserStatus.Event == 0 {
+		scrambledUserStatus.Event = 1
+	}
+
 	return database.GetMySQL().Create(scrambledUserStatus).Error
 }
 
@@ -53,9 +57,24 @@ func (ScrambledUserStatusImpl) List(scrambledUserStatusReq *models.ScrambledUser
 		db.Where("user_id = ?", scrambledUserStatusReq.UserId)
 	}
 
-	if scrambledUserStatusReq.Dimension != 0 {
-		db.Where("dimension = ?", scrambledUserStatusReq.Dimension)
+	_, scrambledUserStatusReq.Width, scrambledUserStatusReq.Height = utils.NormalizeSize(scrambledUserStatusReq.Dimension, scrambledUserStatusReq.Width, scrambledUserStatusReq.Height)
+
+	if scrambledUserStatusReq.Width != 0 {
+		db.Where("width = ?", scrambledUserStatusReq.Width)
+	}
+
+	if scrambledUserStatusReq.Height != 0 {
+		db.Where("height = ?", scrambledUserStatusReq.Height)
+	}
+
+	// 排行榜打乱与打乱组打乱相互独立
+	db.Where("session_id = ?", scrambledUserStatusReq.SessionId)
+
+	// 普通打乱与盲拧打乱相互独立, 未指定时为普通打乱
+	if scrambledUserStatusReq.Event == 0 {
+		scrambledUserStatusReq.Event = 1
 	}
+	db.Where("event = ?", scrambledUserStatusReq.Event)
 
 	if scrambledUserStatusReq.ScrambleId != 0 {
 		db.Where("scramble_id = ?", scrambledUserStatusReq.ScrambleId)
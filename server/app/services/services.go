@@ -11,4 +11,21 @@ var (
 	Notification        = new(NotificationImpl)
 	Cos                 = new(CosImpl)
 	AdminAuthorization  = new(AdminAuthorizationImpl)
+	Idempotency         = new(IdempotencyImpl)
+	RecordModeration    = new(RecordModerationImpl)
+	RecordReport        = new(RecordReportImpl)
+	Solution            = new(SolutionImpl)
+	ScrambleSet         = new(ScrambleSetImpl)
+	RecordChallenge     = new(RecordChallengeImpl)
+	Relay               = new(RelayImpl)
+	RecordBestRelay     = new(RecordBestRelayImpl)
+	Marathon            = new(MarathonImpl)
+	RecordBestMarathon  = new(RecordBestMarathonImpl)
+	RecordBestBlindfold = new(RecordBestBlindfoldImpl)
+	Ghost               = new(GhostImpl)
+	Live                = new(LiveImpl)
+	RaceRoom            = new(RaceRoomImpl)
+	Chat                = new(ChatImpl)
+	Tournament          = new(TournamentImpl)
+	FriendChallenge     = new(FriendChallengeImpl)
 )
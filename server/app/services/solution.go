@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"strconv"
+)
+
+type SolutionService interface {
+	Analyze(analysisReq *models.SolutionAnalysisReq) (models.SolutionAnalysisResp, error)
+}
+
+type SolutionImpl struct{}
+
+// Analyze 分析记录的解法, 删除往返抵消与循环等冗余移动, 返回原始步数、删除冗余后的步数与各类冗余的步数
+// 原始步数 = 不移动的点击 + 抵消 + 合并 + 删除冗余后的步数, 冻结与删除的记录不能分析
+func (SolutionImpl) Analyze(analysisReq *models.SolutionAnalysisReq) (models.SolutionAnalysisResp, error) {
+	var analysisResp models.SolutionAnalysisResp
+
+	if analysisReq.RecordIdStr != "" {
+		analysisReq.RecordId, _ = strconv.ParseInt(analysisReq.RecordIdStr, 10, 64)
+	}
+
+	if analysisReq.RecordId == 0 {
+		return analysisResp, errors.New("记录ID不能为空")
+	}
+
+	var record models.Record
+	err := database.GetMySQL().Table("record").Where("id = ?", analysisReq.RecordId).First(&record).Error
+	if err != nil {
+		return analysisResp, errors.New("记录不存在")
+	}
+
+	if record.Status != 1 {
+		return analysisResp, errors.New("记录已冻结或删除")
+	}
+
+	board, err := puzzle.ParseScramble(record.Width, record.Height, record.Scramble)
+	if err != nil {
+		return analysisResp, errors.New("打乱格式错误")
+	}
+
+	tiles, err := board.DecodeSolution(record.Solution)
+	if err != nil {
+		return analysisResp, errors.New("解法格式错误")
+	}
+
+	// 旧版打乱的历史解法中可能包含不移动的点击, 视为冗余移动
+	moves := tiles
+	if record.ScrambleVersion == puzzle.VersionLegacy {
+		moves = board.LegacyMoves(tiles)
+	}
+
+	rawMoves, err := board.TilesToDirections(moves)
+	if err != nil {
+		return analysisResp, errors.New("解法格式错误")
+	}
+
+	optimization, err := board.OptimizeDetail(moves)
+	if err != nil {
+		return analysisResp, errors.New("解法格式错误")
+	}
+
+	cleaned := optimization.Tiles
+
+	cleanedMoves, err := board.TilesToDirections(cleaned)
+	if err != nil {
+		return analysisResp, errors.New("解法格式错误")
+	}
+
+	analysisResp = models.SolutionAnalysisResp{
+		RecordId:             strconv.FormatInt(record.Id, 10),
+		RawStep:              len(tiles),
+		CleanedStep:          len(cleaned),
+		InvalidStep:          len(tiles) - len(moves),
+		CancelledStep:        optimization.Cancelled,
+		MergedStep:           optimization.Merged,
+		Solution:             puzzle.FormatScramble(cleaned),
+		DirectionSolution:    puzzle.FormatDirections(cleanedMoves),
+		RawDirectionSolution: puzzle.FormatDirections(rawMoves),
+	}
+
+	return analysisResp, nil
+}
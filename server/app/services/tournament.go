@@ -0,0 +1,1256 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"puzzle/app/models"
+	"puzzle/database"
+	"puzzle/puzzle"
+	"puzzle/utils"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const tournamentNotificationTypeId = 3 // 赛事通知类型ID
+
+const maxTournamentPlayers = 128 // 赛事最多选手数量
+
+var tournamentBestOfs = []int{1, 3, 5, 7} // 每场比赛可选的局数
+
+type TournamentService interface {
+	Create(createReq *models.TournamentCreateReq) (models.TournamentResp, error)
+	Cancel(tournamentReq *models.TournamentReq) error
+	Register(tournamentReq *models.TournamentReq) error
+	Unregister(tournamentReq *models.TournamentReq) error
+	Start(tournamentReq *models.TournamentReq) error
+	List(tournamentReq *models.TournamentReq) (models.TournamentListResp, error)
+	Get(tournamentReq *models.TournamentReq) (models.TournamentResp, error)
+	GetMatch(matchReq *models.TournamentMatchReq) (models.TournamentMatchResp, error)
+	Submit(submitReq *models.TournamentSubmitReq) (models.TournamentMatchResp, error)
+	Judge(matchReq *models.TournamentMatchReq) error
+	getTournament(id int64) (models.Tournament, error)
+	lockTournament(tx *gorm.DB, id int64) (models.Tournament, error)
+	seed(tx *gorm.DB, tournament *models.Tournament) ([]models.TournamentPlayer, error)
+	buildElimination(tx *gorm.DB, tournament *models.Tournament, players []models.TournamentPlayer, notices *[]tournamentNotice) error
+	buildSwissRound(tx *gorm.DB, tournament *models.Tournament, round int, notices *[]tournamentNotice) error
+	fill(tx *gorm.DB, tournament *models.Tournament, matchId int64, slot int, userId int64, notices *[]tournamentNotice) error
+	resolve(tx *gorm.DB, tournament *models.Tournament, match *models.TournamentMatch, notices *[]tournamentNotice) error
+	complete(tx *gorm.DB, tournament *models.Tournament, match *models.TournamentMatch, winnerSlot int, fromStatus int, notices *[]tournamentNotice) error
+	resetFinal(tx *gorm.DB, tournament *models.Tournament, match *models.TournamentMatch, notices *[]tournamentNotice) error
+	checkSwissRound(tx *gorm.DB, tournament *models.Tournament, notices *[]tournamentNotice) error
+	finish(tx *gorm.DB, tournament *models.Tournament, winnerId int64, notices *[]tournamentNotice) error
+	issueGame(tx *gorm.DB, tournament *models.Tournament, matchId int64, seq int) error
+	hide(matchResp *models.TournamentMatchResp, userId int64)
+	notify(userIds []int64, content string)
+}
+
+type TournamentImpl struct{}
+
+// tournamentNotice 事务提交后发送的赛事通知
+type tournamentNotice struct {
+	userIds []int64
+	content string
+}
+
+// Create 创建赛事, 管理员使用
+func (TournamentImpl) Create(createReq *models.TournamentCreateReq) (models.TournamentResp, error) {
+	createReq.Name = strings.TrimSpace(createReq.Name)
+	if createReq.Name == "" {
+		return models.TournamentResp{}, errors.New("赛事名称不能为空")
+	}
+
+	if len([]rune(createReq.Name)) > 50 {
+		return models.TournamentResp{}, errors.New("赛事名称不能超过50字")
+	}
+
+	if createReq.Format < 1 || createReq.Format > 3 {
+		return models.TournamentResp{}, errors.New("赛制不合法")
+	}
+
+	if createReq.SeedMethod < 1 || createReq.SeedMethod > 3 {
+		return models.TournamentResp{}, errors.New("种子排序方式不合法")
+	}
+
+	createReq.Dimension, createReq.Width, createReq.Height = utils.NormalizeSize(createReq.Dimension, createReq.Width, createReq.Height)
+
+	if createReq.Width < minScrambleSize || createReq.Width > maxScrambleSize ||
+		createReq.Height < minScrambleSize || createReq.Height > maxScrambleSize {
+		return models.TournamentResp{}, errors.New("尺寸不合法")
+	}
+
+	if createReq.MaxPlayers < 2 || createReq.MaxPlayers > maxTournamentPlayers {
+		return models.TournamentResp{}, fmt.Errorf("选手数量需要在2到%d之间", maxTournamentPlayers)
+	}
+
+	if !slices.Contains(tournamentBestOfs, createReq.BestOf) {
+		return models.TournamentResp{}, errors.New("每场比赛的局数不合法")
+	}
+
+	if createReq.Format != 3 {
+		createReq.SwissRounds = 0
+	}
+
+	if createReq.SwissRounds < 0 || createReq.SwissRounds >= createReq.MaxPlayers {
+		return models.TournamentResp{}, errors.New("瑞士制轮数不合法")
+	}
+
+	snowflake := utils.Snowflake{}
+
+	tournament := models.Tournament{
+		Id:          snowflake.NextVal(),
+		Name:        createReq.Name,
+		Format:      createReq.Format,
+		SeedMethod:  createReq.SeedMethod,
+		Dimension:   createReq.Dimension,
+		Width:       createReq.Width,
+		Height:      createReq.Height,
+		MaxPlayers:  createReq.MaxPlayers,
+		BestOf:      createReq.BestOf,
+		SwissRounds: createReq.SwissRounds,
+		Status:      1,
+	}
+
+	if err := database.GetMySQL().Create(&tournament).Error; err != nil {
+		return models.TournamentResp{}, errors.New("创建赛事失败")
+	}
+
+	return Tournament.Get(&models.TournamentReq{Id: tournament.Id})
+}
+
+// Cancel 取消报名中或进行中的赛事, 管理员使用
+func (TournamentImpl) Cancel(tournamentReq *models.TournamentReq) error {
+	if tournamentReq.IdStr != "" {
+		tournamentReq.Id, _ = strconv.ParseInt(tournamentReq.IdStr, 10, 64)
+	}
+
+	result := database.GetMySQL().Table("tournament").
+		Where("id = ? AND status IN ?", tournamentReq.Id, []int{1, 2}).
+		Update("status", 4)
+	if result.Error != nil {
+		return errors.New("取消赛事失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("赛事不存在或已结束")
+	}
+
+	return nil
+}
+
+// Register 报名赛事
+func (TournamentImpl) Register(tournamentReq *models.TournamentReq) error {
+	if tournamentReq.IdStr != "" {
+		tournamentReq.Id, _ = strconv.ParseInt(tournamentReq.IdStr, 10, 64)
+	}
+
+	// 锁定赛事行后统计人数并报名, 避免并发报名超过人数上限
+	return database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		var tournament models.Tournament
+		err := tx.Table("tournament").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", tournamentReq.Id).
+			First(&tournament).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("赛事不存在")
+		}
+		if err != nil {
+			return errors.New("查询赛事失败")
+		}
+
+		if tournament.Status != 1 {
+			return errors.New("赛事不在报名中")
+		}
+
+		var count int64
+		err = tx.Table("tournament_player").Where("tournament_id = ?", tournament.Id).Count(&count).Error
+		if err != nil {
+			return errors.New("查询报名人数失败")
+		}
+
+		if count >= int64(tournament.MaxPlayers) {
+			return errors.New("报名人数已满")
+		}
+
+		snowflake := utils.Snowflake{}
+
+		// 赛事与用户有唯一索引, 重复报名时插入失败
+		err = tx.Create(&models.TournamentPlayer{
+			Id:           snowflake.NextVal(),
+			TournamentId: tournament.Id,
+			UserId:       tournamentReq.UserId,
+			Status:       1,
+		}).Error
+		if err != nil {
+			return errors.New("报名失败, 请勿重复报名")
+		}
+
+		return nil
+	})
+}
+
+// Unregister 取消报名, 仅报名中的赛事可以取消
+func (TournamentImpl) Unregister(tournamentReq *models.TournamentReq) error {
+	if tournamentReq.IdStr != "" {
+		tournamentReq.Id, _ = strconv.ParseInt(tournamentReq.IdStr, 10, 64)
+	}
+
+	tournament, err := Tournament.getTournament(tournamentReq.Id)
+	if err != nil {
+		return err
+	}
+
+	if tournament.Status != 1 {
+		return errors.New("赛事不在报名中")
+	}
+
+	result := database.GetMySQL().
+		Where("tournament_id = ? AND user_id = ?", tournament.Id, tournamentReq.UserId).
+		Delete(&models.TournamentPlayer{})
+	if result.Error != nil {
+		return errors.New("取消报名失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("未报名该赛事")
+	}
+
+	return nil
+}
+
+// Start 开赛, 管理员使用, 按种子排序生成对阵并通知第一轮的选手
+// 开赛与生成对阵在同一事务中, 生成对阵失败时赛事仍在报名中, 管理员可以重新开赛
+func (TournamentImpl) Start(tournamentReq *models.TournamentReq) error {
+	if tournamentReq.IdStr != "" {
+		tournamentReq.Id, _ = strconv.ParseInt(tournamentReq.IdStr, 10, 64)
+	}
+
+	notices := make([]tournamentNotice, 0)
+
+	err := database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		// 锁定赛事行, 避免重复开赛
+		tournament, err := Tournament.lockTournament(tx, tournamentReq.Id)
+		if err != nil {
+			return err
+		}
+
+		if tournament.Status != 1 {
+			return errors.New("赛事不在报名中")
+		}
+
+		players, err := Tournament.seed(tx, &tournament)
+		if err != nil {
+			return err
+		}
+
+		if len(players) < 2 {
+			return errors.New("报名人数不足")
+		}
+
+		updates := map[string]any{"status": 2}
+		if tournament.Format == 3 {
+			// 瑞士制默认轮数为能决出唯一全胜选手的轮数
+			if tournament.SwissRounds == 0 || tournament.SwissRounds >= len(players) {
+				tournament.SwissRounds = min(bits.Len(uint(len(players)-1)), len(players)-1)
+			}
+
+			tournament.CurrentRound = 1
+			updates["swiss_rounds"] = tournament.SwissRounds
+			updates["current_round"] = tournament.CurrentRound
+		}
+
+		if err = tx.Table("tournament").Where("id = ?", tournament.Id).Updates(updates).Error; err != nil {
+			return errors.New("开赛失败")
+		}
+
+		tournament.Status = 2
+
+		if tournament.Format == 3 {
+			return Tournament.buildSwissRound(tx, &tournament, 1, &notices)
+		}
+
+		return Tournament.buildElimination(tx, &tournament, players, &notices)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, notice := range notices {
+		Tournament.notify(notice.userIds, notice.content)
+	}
+
+	return nil
+}
+
+// List 赛事列表
+func (TournamentImpl) List(tournamentReq *models.TournamentReq) (models.TournamentListResp, error) {
+	var tournamentListResp models.TournamentListResp
+
+	db := database.GetMySQL().Table("tournament").Order("id " + utils.SortDirection(tournamentReq.Sorted, "desc"))
+
+	if tournamentReq.Format != 0 {
+		db.Where("format = ?", tournamentReq.Format)
+	}
+
+	if tournamentReq.Status != 0 {
+		db.Where("status = ?", tournamentReq.Status)
+	}
+
+	// 查询总数
+	err := db.Count(&tournamentListResp.Total).Error
+	if err != nil {
+		return tournamentListResp, errors.New("查询失败")
+	}
+
+	// 分页
+	if tournamentReq.Pagination.Page > 0 && tournamentReq.Pagination.PageSize > 0 {
+		db.Scopes(utils.Paginate(&tournamentReq.Pagination))
+	}
+
+	err = db.Find(&tournamentListResp.Records).Error
+	if err != nil {
+		return tournamentListResp, errors.New("查询失败")
+	}
+
+	return tournamentListResp, nil
+}
+
+// Get 赛事详情, 包括选手与所有比赛
+func (TournamentImpl) Get(tournamentReq *models.TournamentReq) (models.TournamentResp, error) {
+	var tournamentResp models.TournamentResp
+
+	if tournamentReq.IdStr != "" {
+		tournamentReq.Id, _ = strconv.ParseInt(tournamentReq.IdStr, 10, 64)
+	}
+
+	err := database.GetMySQL().Table("tournament").
+		Where("id = ?", tournamentReq.Id).
+		Preload("Players", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seed asc, id asc")
+		}).
+		Preload("Players.UserInfo").
+		Preload("Matches", func(db *gorm.DB) *gorm.DB {
+			return db.Order("bracket asc, round asc, position asc")
+		}).
+		Preload("Matches.Games", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq asc")
+		}).
+		First(&tournamentResp).Error
+	if err != nil {
+		return tournamentResp, errors.New("赛事不存在")
+	}
+
+	for i := range tournamentResp.Matches {
+		Tournament.hide(&tournamentResp.Matches[i], tournamentReq.UserId)
+	}
+
+	return tournamentResp, nil
+}
+
+// GetMatch 比赛详情, 包括各局的打乱与成绩
+func (TournamentImpl) GetMatch(matchReq *models.TournamentMatchReq) (models.TournamentMatchResp, error) {
+	var matchResp models.TournamentMatchResp
+
+	if matchReq.IdStr != "" {
+		matchReq.Id, _ = strconv.ParseInt(matchReq.IdStr, 10, 64)
+	}
+
+	err := database.GetMySQL().Table("tournament_match").
+		Where("id = ?", matchReq.Id).
+		Preload("Games", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq asc")
+		}).
+		First(&matchResp).Error
+	if err != nil {
+		return matchResp, errors.New("比赛不存在")
+	}
+
+	Tournament.hide(&matchResp, matchReq.UserId)
+
+	return matchResp, nil
+}
+
+// Submit 提交当前一局的成绩, 双方都提交后判定胜负, 先赢过半数局的选手赢得比赛
+// 双方都放弃或成绩完全相同时重赛一局
+// 赛事的比赛不要求双方同时在线, 成绩需要长期保存, 因此不使用保存在 redis 中且会过期的直播对战,
+// 由服务端校验解法能还原该局的打乱且步数一致, 耗时不超过该局下发至今的时间
+// 提交成绩与结束比赛、推进对阵、下发下一局在同一事务中, 并锁定赛事行, 同一赛事的结算依次进行
+func (TournamentImpl) Submit(submitReq *models.TournamentSubmitReq) (models.TournamentMatchResp, error) {
+	if submitReq.MatchIdStr != "" {
+		submitReq.MatchId, _ = strconv.ParseInt(submitReq.MatchIdStr, 10, 64)
+	}
+
+	var match models.TournamentMatch
+	err := database.GetMySQL().Table("tournament_match").Where("id = ?", submitReq.MatchId).First(&match).Error
+	if err != nil {
+		return models.TournamentMatchResp{}, errors.New("比赛不存在")
+	}
+
+	notices := make([]tournamentNotice, 0)
+
+	err = database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		tournament, err := Tournament.lockTournament(tx, match.TournamentId)
+		if err != nil {
+			return err
+		}
+
+		if tournament.Status != 2 {
+			return errors.New("赛事未在进行中")
+		}
+
+		// 锁定赛事后重新读取比赛, 获取最新的状态与胜局数
+		err = tx.Table("tournament_match").Where("id = ?", match.Id).First(&match).Error
+		if err != nil {
+			return errors.New("比赛不存在")
+		}
+
+		if match.Status != 2 {
+			return errors.New("比赛未在进行中")
+		}
+
+		var slot int
+		switch submitReq.UserId {
+		case match.Player1Id:
+			slot = 1
+		case match.Player2Id:
+			slot = 2
+		default:
+			return errors.New("不是该比赛的选手")
+		}
+
+		var game models.TournamentGame
+		err = tx.Table("tournament_game").
+			Where("match_id = ? AND seq = ? AND status = ?", match.Id, submitReq.Seq, 1).
+			First(&game).Error
+		if err != nil {
+			return errors.New("该局不存在或已结束")
+		}
+
+		switch submitReq.Status {
+		case 2:
+			if submitReq.Duration <= 0 || submitReq.Step <= 0 || submitReq.Solution == "" {
+				return errors.New("成绩不完整")
+			}
+
+			encryptionParams := utils.EncryptionParams{
+				Dimension: tournament.Dimension,
+				Width:     tournament.Width,
+				Height:    tournament.Height,
+				RandomIdx: game.Idx,
+				StepCount: submitReq.Step,
+				Scramble:  game.Scramble,
+				Solution:  submitReq.Solution,
+				Version:   game.ScrambleVersion,
+			}
+
+			if !encryptionParams.VerifyScramble() {
+				return ErrRecordVerify
+			}
+
+			// 选手在该局下发后才能看到打乱, 耗时不能超过下发至今的时间
+			if time.Duration(submitReq.Duration)*time.Millisecond > time.Since(game.CreatedAt) {
+				return ErrRecordVerify
+			}
+		case 3:
+			submitReq.Duration, submitReq.Step, submitReq.Solution = 0, 0, ""
+		default:
+			return errors.New("状态不合法")
+		}
+
+		// 只更新未提交的选手成绩, 避免重复提交
+		prefix := fmt.Sprintf("player%d_", slot)
+		result := tx.Table("tournament_game").
+			Where("id = ? AND "+prefix+"status = ?", game.Id, 1).
+			Updates(map[string]any{
+				prefix + "duration": submitReq.Duration,
+				prefix + "step":     submitReq.Step,
+				prefix + "solution": submitReq.Solution,
+				prefix + "status":   submitReq.Status,
+			})
+		if result.Error != nil {
+			return errors.New("提交失败")
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("该局已提交过成绩, 请勿重复提交")
+		}
+
+		err = tx.Table("tournament_game").Where("id = ?", game.Id).First(&game).Error
+		if err != nil {
+			return errors.New("查询该局失败")
+		}
+
+		if game.Player1Status == 1 || game.Player2Status == 1 {
+			return nil
+		}
+
+		// 完成的选手胜过放弃的选手, 双方都完成时耗时少者获胜, 耗时相同时步数少者获胜
+		winnerSlot := 0
+		switch {
+		case game.Player1Status == 2 && game.Player2Status != 2:
+			winnerSlot = 1
+		case game.Player2Status == 2 && game.Player1Status != 2:
+			winnerSlot = 2
+		case game.Player1Status == 2 && game.Player2Status == 2:
+			switch {
+			case game.Player1Duration < game.Player2Duration:
+				winnerSlot = 1
+			case game.Player2Duration < game.Player1Duration:
+				winnerSlot = 2
+			case game.Player1Step < game.Player2Step:
+				winnerSlot = 1
+			case game.Player2Step < game.Player1Step:
+				winnerSlot = 2
+			}
+		}
+
+		winnerId := int64(0)
+		if winnerSlot != 0 {
+			winnerId = []int64{match.Player1Id, match.Player2Id}[winnerSlot-1]
+		}
+
+		err = tx.Table("tournament_game").
+			Where("id = ?", game.Id).
+			Updates(map[string]any{"winner_id": winnerId, "status": 2}).Error
+		if err != nil {
+			return errors.New("结算失败")
+		}
+
+		if winnerSlot != 0 {
+			column := fmt.Sprintf("player%d_wins", winnerSlot)
+			err = tx.Table("tournament_match").Where("id = ?", match.Id).
+				Update(column, gorm.Expr(column+" + 1")).Error
+			if err != nil {
+				return errors.New("结算失败")
+			}
+
+			if winnerSlot == 1 {
+				match.Player1Wins++
+			} else {
+				match.Player2Wins++
+			}
+		}
+
+		switch {
+		case match.Player1Wins > tournament.BestOf/2:
+			return Tournament.complete(tx, &tournament, &match, 1, 2, &notices)
+		case match.Player2Wins > tournament.BestOf/2:
+			return Tournament.complete(tx, &tournament, &match, 2, 2, &notices)
+		default:
+			return Tournament.issueGame(tx, &tournament, match.Id, game.Seq+1)
+		}
+	})
+	if err != nil {
+		return models.TournamentMatchResp{}, err
+	}
+
+	for _, notice := range notices {
+		Tournament.notify(notice.userIds, notice.content)
+	}
+
+	return Tournament.GetMatch(&models.TournamentMatchReq{Id: match.Id, UserId: submitReq.UserId})
+}
+
+// Judge 管理员判定进行中比赛的胜者, 用于选手缺席等情况
+func (TournamentImpl) Judge(matchReq *models.TournamentMatchReq) error {
+	if matchReq.IdStr != "" {
+		matchReq.Id, _ = strconv.ParseInt(matchReq.IdStr, 10, 64)
+	}
+
+	if matchReq.WinnerIdStr != "" {
+		matchReq.WinnerId, _ = strconv.ParseInt(matchReq.WinnerIdStr, 10, 64)
+	}
+
+	var match models.TournamentMatch
+	err := database.GetMySQL().Table("tournament_match").Where("id = ?", matchReq.Id).First(&match).Error
+	if err != nil {
+		return errors.New("比赛不存在")
+	}
+
+	notices := make([]tournamentNotice, 0)
+
+	err = database.GetMySQL().Transaction(func(tx *gorm.DB) error {
+		tournament, err := Tournament.lockTournament(tx, match.TournamentId)
+		if err != nil {
+			return err
+		}
+
+		if tournament.Status != 2 {
+			return errors.New("赛事未在进行中")
+		}
+
+		err = tx.Table("tournament_match").Where("id = ?", match.Id).First(&match).Error
+		if err != nil {
+			return errors.New("比赛不存在")
+		}
+
+		if match.Status != 2 {
+			return errors.New("比赛未在进行中")
+		}
+
+		switch matchReq.WinnerId {
+		case match.Player1Id:
+			return Tournament.complete(tx, &tournament, &match, 1, 2, &notices)
+		case match.Player2Id:
+			return Tournament.complete(tx, &tournament, &match, 2, 2, &notices)
+		default:
+			return errors.New("胜者不是该比赛的选手")
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, notice := range notices {
+		Tournament.notify(notice.userIds, notice.content)
+	}
+
+	return nil
+}
+
+// getTournament 获取赛事
+func (TournamentImpl) getTournament(id int64) (models.Tournament, error) {
+	var tournament models.Tournament
+	err := database.GetMySQL().Table("tournament").Where("id = ?", id).First(&tournament).Error
+	if err != nil {
+		return tournament, errors.New("赛事不存在")
+	}
+
+	return tournament, nil
+}
+
+// lockTournament 在事务中锁定并获取赛事, 同一赛事的开赛与结算依次进行
+func (TournamentImpl) lockTournament(tx *gorm.DB, id int64) (models.Tournament, error) {
+	var tournament models.Tournament
+	err := tx.Table("tournament").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&tournament).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tournament, errors.New("赛事不存在")
+	}
+	if err != nil {
+		return tournament, errors.New("查询赛事失败")
+	}
+
+	return tournament, nil
+}
+
+// seed 按种子排序方式为选手编排种子序号, 没有成绩的选手排在最后并按报名顺序排列
+func (TournamentImpl) seed(tx *gorm.DB, tournament *models.Tournament) ([]models.TournamentPlayer, error) {
+	var players []models.TournamentPlayer
+	err := tx.Table("tournament_player").
+		Where("tournament_id = ?", tournament.Id).
+		Order("id asc").
+		Find(&players).Error
+	if err != nil {
+		return nil, errors.New("查询选手失败")
+	}
+
+	if len(players) == 0 {
+		return players, nil
+	}
+
+	userIds := make([]int64, len(players))
+	for i, player := range players {
+		userIds[i] = player.UserId
+	}
+
+	type bestDuration struct {
+		UserId   int64
+		Duration int
+	}
+
+	var bests []bestDuration
+	switch tournament.SeedMethod {
+	case 1:
+		err = database.GetMySQL().Table("record_best_average").
+			Select("user_id, record_average_duration AS duration").
+			Where("user_id IN ? AND width = ? AND height = ? AND type = ?", userIds, tournament.Width, tournament.Height, 5).
+			Find(&bests).Error
+	case 2:
+		err = database.GetMySQL().Table("record_best_single").
+			Select("user_id, record_duration AS duration").
+			Where("user_id IN ? AND width = ? AND height = ?", userIds, tournament.Width, tournament.Height).
+			Find(&bests).Error
+	}
+	if err != nil {
+		return nil, errors.New("查询选手成绩失败")
+	}
+
+	durations := make(map[int64]int, len(bests))
+	for _, best := range bests {
+		durations[best.UserId] = best.Duration
+	}
+
+	sort.SliceStable(players, func(i, j int) bool {
+		a, aOk := durations[players[i].UserId]
+		b, bOk := durations[players[j].UserId]
+		if aOk != bOk {
+			return aOk
+		}
+		return aOk && a < b
+	})
+
+	for i := range players {
+		players[i].Seed = i + 1
+		err = tx.Table("tournament_player").Where("id = ?", players[i].Id).Update("seed", players[i].Seed).Error
+		if err != nil {
+			return nil, errors.New("更新种子失败")
+		}
+	}
+
+	return players, nil
+}
+
+// buildElimination 生成淘汰赛的完整对阵表, 选手数量不足2的幂时由高种子轮空
+// 双败淘汰的败者组每两轮为一个阶段, 奇数轮为败者组内部对决, 偶数轮迎战胜者组落败的选手, 败者组冠军与胜者组冠军进行总决赛
+// 败者组冠军赢得总决赛时再加赛一场, 见 resetFinal
+func (TournamentImpl) buildElimination(tx *gorm.DB, tournament *models.Tournament, players []models.TournamentPlayer, notices *[]tournamentNotice) error {
+	size := 1
+	for size < len(players) {
+		size *= 2
+	}
+	rounds := bits.Len(uint(size)) - 1
+
+	snowflake := utils.Snowflake{}
+	newMatch := func(bracket, round, position int) *models.TournamentMatch {
+		return &models.TournamentMatch{
+			Id:            snowflake.NextVal(),
+			TournamentId:  tournament.Id,
+			Bracket:       bracket,
+			Round:         round,
+			Position:      position,
+			Player1Status: 1,
+			Player2Status: 1,
+			Status:        1,
+		}
+	}
+
+	link := func(from *models.TournamentMatch, to *models.TournamentMatch, slot int, loser bool) {
+		if loser {
+			from.LoserMatchId, from.LoserSlot = to.Id, slot
+		} else {
+			from.NextMatchId, from.NextSlot = to.Id, slot
+		}
+	}
+
+	// 胜者组
+	winners := make([][]*models.TournamentMatch, rounds+1)
+	for r := 1; r <= rounds; r++ {
+		for m := 0; m < size>>r; m++ {
+			winners[r] = append(winners[r], newMatch(1, r, m))
+		}
+	}
+
+	for r := 1; r < rounds; r++ {
+		for m, match := range winners[r] {
+			link(match, winners[r+1][m/2], m%2+1, false)
+		}
+	}
+
+	all := make([]*models.TournamentMatch, 0)
+	for r := 1; r <= rounds; r++ {
+		all = append(all, winners[r]...)
+	}
+
+	if tournament.Format == 2 {
+		final := newMatch(3, 1, 0)
+		link(winners[rounds][0], final, 1, false)
+
+		// 败者组
+		losers := make([][]*models.TournamentMatch, 2*(rounds-1)+1)
+		for r := 1; r <= 2*(rounds-1); r++ {
+			for m := 0; m < size>>((r+1)/2+1); m++ {
+				losers[r] = append(losers[r], newMatch(2, r, m))
+			}
+		}
+
+		for r := 1; r <= 2*(rounds-1); r++ {
+			for m, match := range losers[r] {
+				switch {
+				case r == 2*(rounds-1):
+					link(match, final, 2, false)
+				case r%2 == 1:
+					link(match, losers[r+1][m], 1, false)
+				default:
+					link(match, losers[r+1][m/2], m%2+1, false)
+				}
+			}
+		}
+
+		// 胜者组落败的选手进入败者组, 只有两名选手时直接进入总决赛
+		for r := 1; r <= rounds; r++ {
+			for m, match := range winners[r] {
+				switch {
+				case rounds == 1:
+					link(match, final, 2, true)
+				case r == 1:
+					link(match, losers[1][m/2], m%2+1, true)
+				default:
+					link(match, losers[2*(r-1)][m], 2, true)
+				}
+			}
+		}
+
+		for r := 1; r <= 2*(rounds-1); r++ {
+			all = append(all, losers[r]...)
+		}
+		all = append(all, final)
+	}
+
+	// 第一轮按种子对阵, 种子序号超过选手数量的位置轮空
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+
+	for m, match := range winners[1] {
+		for slot, seed := range order[2*m : 2*m+2] {
+			id, status := int64(0), 3
+			if seed <= len(players) {
+				id, status = players[seed-1].UserId, 2
+			}
+
+			if slot == 0 {
+				match.Player1Id, match.Player1Status = id, status
+			} else {
+				match.Player2Id, match.Player2Status = id, status
+			}
+		}
+	}
+
+	for _, match := range all {
+		if err := tx.Create(match).Error; err != nil {
+			return errors.New("生成对阵失败")
+		}
+	}
+
+	for _, match := range winners[1] {
+		if err := Tournament.resolve(tx, tournament, match, notices); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildSwissRound 生成瑞士制的一轮对阵
+// 第一轮按种子上半区对下半区, 之后按积分与种子排序, 依次与排名最近且未交手的选手对阵
+// 人数为奇数时由排名最低且未轮空过的选手轮空, 轮空记为一场胜利
+func (TournamentImpl) buildSwissRound(tx *gorm.DB, tournament *models.Tournament, round int, notices *[]tournamentNotice) error {
+	var players []models.TournamentPlayer
+	err := tx.Table("tournament_player").
+		Where("tournament_id = ?", tournament.Id).
+		Order("points desc, seed asc").
+		Find(&players).Error
+	if err != nil {
+		return errors.New("查询选手失败")
+	}
+
+	var previous []models.TournamentMatch
+	err = tx.Table("tournament_match").Where("tournament_id = ?", tournament.Id).Find(&previous).Error
+	if err != nil {
+		return errors.New("查询比赛失败")
+	}
+
+	played := make(map[[2]int64]bool)
+	hadBye := make(map[int64]bool)
+	for _, match := range previous {
+		if match.Player2Status == 3 {
+			hadBye[match.Player1Id] = true
+			continue
+		}
+		played[[2]int64{match.Player1Id, match.Player2Id}] = true
+		played[[2]int64{match.Player2Id, match.Player1Id}] = true
+	}
+
+	snowflake := utils.Snowflake{}
+	matches := make([]*models.TournamentMatch, 0)
+	newMatch := func(player1Id, player2Id int64) {
+		match := &models.TournamentMatch{
+			Id:            snowflake.NextVal(),
+			TournamentId:  tournament.Id,
+			Bracket:       4,
+			Round:         round,
+			Position:      len(matches),
+			Player1Id:     player1Id,
+			Player2Id:     player2Id,
+			Player1Status: 2,
+			Player2Status: 2,
+			Status:        1,
+		}
+		if player2Id == 0 {
+			match.Player2Status = 3
+		}
+		matches = append(matches, match)
+	}
+
+	userIds := make([]int64, len(players))
+	for i, player := range players {
+		userIds[i] = player.UserId
+	}
+
+	var byeId int64
+	if len(userIds)%2 == 1 {
+		index := len(userIds) - 1
+		for i := len(userIds) - 1; i >= 0; i-- {
+			if !hadBye[userIds[i]] {
+				index = i
+				break
+			}
+		}
+		byeId = userIds[index]
+		userIds = slices.Delete(userIds, index, index+1)
+	}
+
+	if round == 1 {
+		half := len(userIds) / 2
+		for i := 0; i < half; i++ {
+			newMatch(userIds[i], userIds[i+half])
+		}
+	} else {
+		paired := make([]bool, len(userIds))
+		for i := range userIds {
+			if paired[i] {
+				continue
+			}
+
+			opponent := -1
+			for j := i + 1; j < len(userIds); j++ {
+				if paired[j] {
+					continue
+				}
+				if opponent < 0 {
+					opponent = j
+				}
+				if !played[[2]int64{userIds[i], userIds[j]}] {
+					opponent = j
+					break
+				}
+			}
+
+			paired[i], paired[opponent] = true, true
+			newMatch(userIds[i], userIds[opponent])
+		}
+	}
+
+	if byeId != 0 {
+		newMatch(byeId, 0)
+	}
+
+	for _, match := range matches {
+		if err = tx.Create(match).Error; err != nil {
+			return errors.New("生成对阵失败")
+		}
+	}
+
+	for _, match := range matches {
+		if err = Tournament.resolve(tx, tournament, match, notices); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fill 将上一场比赛的胜者或败者填入比赛, userId 为0时表示轮空, 双方都确定后开始比赛
+func (TournamentImpl) fill(tx *gorm.DB, tournament *models.Tournament, matchId int64, slot int, userId int64, notices *[]tournamentNotice) error {
+	status := 2
+	if userId == 0 {
+		status = 3
+	}
+
+	prefix := fmt.Sprintf("player%d_", slot)
+	err := tx.Table("tournament_match").
+		Where("id = ?", matchId).
+		Updates(map[string]any{prefix + "id": userId, prefix + "status": status}).Error
+	if err != nil {
+		return errors.New("更新对阵失败")
+	}
+
+	var match models.TournamentMatch
+	err = tx.Table("tournament_match").Where("id = ?", matchId).First(&match).Error
+	if err != nil {
+		return errors.New("查询比赛失败")
+	}
+
+	return Tournament.resolve(tx, tournament, &match, notices)
+}
+
+// resolve 双方都已确定的比赛开始进行, 一方轮空时另一方直接获胜, 双方都轮空时轮空继续向后传递
+func (TournamentImpl) resolve(tx *gorm.DB, tournament *models.Tournament, match *models.TournamentMatch, notices *[]tournamentNotice) error {
+	if match.Status != 1 || match.Player1Status == 1 || match.Player2Status == 1 {
+		return nil
+	}
+
+	switch {
+	case match.Player1Status == 2 && match.Player2Status == 2:
+		// 只开始等待选手的比赛, 避免双方同时确定时重复开始
+		result := tx.Table("tournament_match").Where("id = ? AND status = ?", match.Id, 1).Update("status", 2)
+		if result.Error != nil {
+			return errors.New("开始比赛失败")
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		match.Status = 2
+		if err := Tournament.issueGame(tx, tournament, match.Id, 1); err != nil {
+			return err
+		}
+
+		*notices = append(*notices, tournamentNotice{
+			userIds: []int64{match.Player1Id, match.Player2Id},
+			content: fmt.Sprintf("赛事「%s」的比赛已就绪, 请前往赛事页面开始比赛", tournament.Name),
+		})
+
+		return nil
+	case match.Player1Status == 2:
+		return Tournament.complete(tx, tournament, match, 1, 1, notices)
+	case match.Player2Status == 2:
+		return Tournament.complete(tx, tournament, match, 2, 1, notices)
+	default:
+		return Tournament.complete(tx, tournament, match, 0, 1, notices)
+	}
+}
+
+// complete 结束比赛, winnerSlot 为0时表示双方都轮空
+// 胜者进入下一场比赛或成为冠军, 败者进入败者组或被淘汰, 瑞士制记录胜负后检查本轮是否结束
+func (TournamentImpl) complete(tx *gorm.DB, tournament *models.Tournament, match *models.TournamentMatch, winnerSlot int, fromStatus int, notices *[]tournamentNotice) error {
+	var winnerId, loserId int64
+	switch winnerSlot {
+	case 1:
+		winnerId, loserId = match.Player1Id, match.Player2Id
+	case 2:
+		winnerId, loserId = match.Player2Id, match.Player1Id
+	}
+
+	// 只结束指定状态的比赛, 避免重复结算
+	result := tx.Table("tournament_match").
+		Where("id = ? AND status = ?", match.Id, fromStatus).
+		Updates(map[string]any{"winner_id": winnerId, "status": 3})
+	if result.Error != nil {
+		return errors.New("结束比赛失败")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	match.WinnerId, match.Status = winnerId, 3
+
+	// 双败淘汰的总决赛中败者组冠军获胜时, 胜者组冠军只是第一次落败, 需要加赛一场
+	finalReset := tournament.Format == 2 && match.Bracket == 3 && match.Round == 1 && winnerSlot == 2
+
+	if winnerId != 0 {
+		err := tx.Table("tournament_player").
+			Where("tournament_id = ? AND user_id = ?", tournament.Id, winnerId).
+			Update("points", gorm.Expr("points + 1")).Error
+		if err != nil {
+			return errors.New("更新选手失败")
+		}
+	}
+
+	if loserId != 0 {
+		updates := map[string]any{"losses": gorm.Expr("losses + 1")}
+		if tournament.Format == 1 || (tournament.Format == 2 && match.Bracket != 1 && !finalReset) {
+			updates["status"] = 2
+		}
+
+		err := tx.Table("tournament_player").
+			Where("tournament_id = ? AND user_id = ?", tournament.Id, loserId).
+			Updates(updates).Error
+		if err != nil {
+			return errors.New("更新选手失败")
+		}
+	}
+
+	if tournament.Format == 3 {
+		return Tournament.checkSwissRound(tx, tournament, notices)
+	}
+
+	if finalReset {
+		return Tournament.resetFinal(tx, tournament, match, notices)
+	}
+
+	if match.NextMatchId == 0 {
+		return Tournament.finish(tx, tournament, winnerId, notices)
+	}
+
+	if err := Tournament.fill(tx, tournament, match.NextMatchId, match.NextSlot, winnerId, notices); err != nil {
+		return err
+	}
+
+	if match.LoserMatchId != 0 {
+		if err := Tournament.fill(tx, tournament, match.LoserMatchId, match.LoserSlot, loserId, notices); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resetFinal 生成总决赛的加赛, 双方保持总决赛中的位置, 加赛的胜者成为冠军
+func (TournamentImpl) resetFinal(tx *gorm.DB, tournament *models.Tournament, match *models.TournamentMatch, notices *[]tournamentNotice) error {
+	snowflake := utils.Snowflake{}
+
+	reset := &models.TournamentMatch{
+		Id:            snowflake.NextVal(),
+		TournamentId:  tournament.Id,
+		Bracket:       3,
+		Round:         match.Round + 1,
+		Position:      0,
+		Player1Id:     match.Player1Id,
+		Player2Id:     match.Player2Id,
+		Player1Status: 2,
+		Player2Status: 2,
+		Status:        1,
+	}
+
+	if err := tx.Create(reset).Error; err != nil {
+		return errors.New("生成总决赛加赛失败")
+	}
+
+	return Tournament.resolve(tx, tournament, reset, notices)
+}
+
+// checkSwissRound 瑞士制本轮所有比赛结束后生成下一轮, 最后一轮结束后积分最高的选手成为冠军
+func (TournamentImpl) checkSwissRound(tx *gorm.DB, tournament *models.Tournament, notices *[]tournamentNotice) error {
+	var count int64
+	err := tx.Table("tournament_match").
+		Where("tournament_id = ? AND round = ? AND status != ?", tournament.Id, tournament.CurrentRound, 3).
+		Count(&count).Error
+	if err != nil {
+		return errors.New("查询比赛失败")
+	}
+
+	if count > 0 || tournament.Status != 2 {
+		return nil
+	}
+
+	if tournament.CurrentRound >= tournament.SwissRounds {
+		var winner models.TournamentPlayer
+		err = tx.Table("tournament_player").
+			Where("tournament_id = ?", tournament.Id).
+			Order("points desc, losses asc, seed asc").
+			First(&winner).Error
+		if err != nil {
+			return errors.New("查询选手失败")
+		}
+
+		return Tournament.finish(tx, tournament, winner.UserId, notices)
+	}
+
+	err = tx.Table("tournament").
+		Where("id = ?", tournament.Id).
+		Update("current_round", tournament.CurrentRound+1).Error
+	if err != nil {
+		return errors.New("更新赛事失败")
+	}
+
+	tournament.CurrentRound++
+
+	return Tournament.buildSwissRound(tx, tournament, tournament.CurrentRound, notices)
+}
+
+// finish 结束赛事并通知所有选手
+func (TournamentImpl) finish(tx *gorm.DB, tournament *models.Tournament, winnerId int64, notices *[]tournamentNotice) error {
+	err := tx.Table("tournament").
+		Where("id = ?", tournament.Id).
+		Updates(map[string]any{"winner_id": winnerId, "status": 3}).Error
+	if err != nil {
+		return errors.New("结束赛事失败")
+	}
+
+	tournament.WinnerId, tournament.Status = winnerId, 3
+
+	var userIds []int64
+	err = tx.Table("tournament_player").Where("tournament_id = ?", tournament.Id).Pluck("user_id", &userIds).Error
+	if err != nil {
+		return errors.New("查询选手失败")
+	}
+
+	winner, err := User.GetUserById(winnerId)
+	if err != nil {
+		return err
+	}
+
+	*notices = append(*notices, tournamentNotice{
+		userIds: userIds,
+		content: fmt.Sprintf("赛事「%s」已结束, 冠军: %s", tournament.Name, winner.Nickname),
+	})
+
+	return nil
+}
+
+// issueGame 下发比赛中的一局, 两名选手使用同一个打乱
+func (TournamentImpl) issueGame(tx *gorm.DB, tournament *models.Tournament, matchId int64, seq int) error {
+	idx, scramble, _ := Scramble.generate(tournament.Width, tournament.Height)
+
+	snowflake := utils.Snowflake{}
+
+	err := tx.Create(&models.TournamentGame{
+		Id:              snowflake.NextVal(),
+		MatchId:         matchId,
+		Seq:             seq,
+		Idx:             idx,
+		Scramble:        puzzle.FormatScramble(scramble),
+		ScrambleVersion: puzzle.CurrentVersion,
+		Player1Status:   1,
+		Player2Status:   1,
+		Status:          1,
+	}).Error
+	if err != nil {
+		return errors.New("下发打乱失败")
+	}
+
+	return nil
+}
+
+// notify 通过通知服务通知选手
+func (TournamentImpl) notify(userIds []int64, content string) {
+	for _, userId := range userIds {
+		err := Notification.Insert(&models.NotificationReq{
+			UserId:  userId,
+			TypeId:  tournamentNotificationTypeId,
+			Content: content,
+		})
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+}
+
+// hide 隐藏进行中的对局里对手的成绩, 避免选手提交前参考对手
+func (TournamentImpl) hide(matchResp *models.TournamentMatchResp, userId int64) {
+	userIdStr := strconv.FormatInt(userId, 10)
+
+	for i := range matchResp.Games {
+		game := &matchResp.Games[i]
+		if game.Status != 1 {
+			continue
+		}
+
+		if matchResp.Player1Id != userIdStr {
+			game.Player1Duration = 0
+			game.Player1Step = 0
+		}
+
+		if matchResp.Player2Id != userIdStr {
+			game.Player2Duration = 0
+			game.Player2Step = 0
+		}
+	}
+}
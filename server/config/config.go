@@ -12,6 +12,10 @@ type Config struct {
 	Redis       Redis       `mapstructure:"redis"`
 	RabbitMQ    RabbitMQ    `mapstructure:"rabbitmq"`
 	Cos         Cos         `mapstructure:"cos"`
+	Moderation  Moderation  `mapstructure:"moderation"`
+	Scramble    Scramble    `mapstructure:"scramble"`
+	Chat        Chat        `mapstructure:"chat"`
+	Websocket   Websocket   `mapstructure:"websocket"`
 }
 
 type Application struct {
@@ -48,6 +52,31 @@ type Cos struct {
 	Url       string `mapstructure:"url"`
 }
 
+type Moderation struct {
+	MinMoveInterval int     `mapstructure:"min_move_interval"` // 最小平均移动间隔(毫秒), 低于该值的记录会被自动标记
+	MinDuration     int     `mapstructure:"min_duration"`      // 最小耗时(毫秒), 低于该值的记录会被自动标记
+	ReportLimit     int     `mapstructure:"report_limit"`      // 每个用户每小时最多举报次数
+	AdminUserIds    []int64 `mapstructure:"admin_user_ids"`    // 接收举报通知的管理员用户ID
+}
+
+type Scramble struct {
+	MinManhattanDistance int `mapstructure:"min_manhattan_distance"` // 排行榜打乱的最小曼哈顿距离, 0:不限制
+	MinOptimalLength     int `mapstructure:"min_optimal_length"`     // 排行榜打乱的最少单格移动步数, 无法计算时不限制, 0:不限制
+	MaxAttempts          int `mapstructure:"max_attempts"`           // 重新生成打乱的最大次数
+	MaxSearchNodes       int `mapstructure:"max_search_nodes"`       // 生成一次打乱时计算最优步数最多搜索的节点总数
+}
+
+type Chat struct {
+	RateLimit      int      `mapstructure:"rate_limit"`      // 每个客户端在计数周期内最多发送的消息数量
+	RateWindow     int      `mapstructure:"rate_window"`     // 发送频率计数周期(秒)
+	SensitiveWords []string `mapstructure:"sensitive_words"` // 敏感词, 消息中的敏感词会被替换为 *
+}
+
+type Websocket struct {
+	AuthGracePeriod       int `mapstructure:"auth_grace_period"`        // 握手时未携带令牌的连接需要在该时长(秒)内完成认证, 否则会被关闭
+	MaxConnectionsPerUser int `mapstructure:"max_connections_per_user"` // 每个用户最多同时保持的连接数量
+}
+
 var Settings Config
 
 func InitConfig() {
@@ -2,6 +2,8 @@ CREATE DATABASE IF NOT EXISTS puzzle DEFAULT CHARSET utf8mb4 COLLATE utf8mb4_gen
 
 USE puzzle;
 
+-- 基线版本创建的旧数据库按文件名顺序执行 migrations 目录下的脚本升级到当前结构, 每个脚本只执行一次
+
 DROP TABLE IF EXISTS `user`;
 CREATE TABLE IF NOT EXISTS `user` (
   `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
@@ -50,14 +52,23 @@ DROP TABLE IF EXISTS `record`;
 CREATE TABLE IF NOT EXISTS `record` (
   `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
   `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
-  `dimension` TINYINT(1) NOT NULL COMMENT '阶数 3 | 4 | 5 | 6',
-  `type` TINYINT(1) NOT NULL COMMENT '类型 1:练习 2:排行榜 3:对战',
-  `duration` INT NOT NULL COMMENT '耗时',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `type` TINYINT(1) NOT NULL COMMENT '类型 1:练习 2:排行榜 3:对战 4:打乱组 5:挑战 6:盲拧 7:好友挑战',
+  `duration` INT NOT NULL COMMENT '耗时 盲拧为记忆与执行耗时之和',
+  `memo_duration` INT NOT NULL DEFAULT 0 COMMENT '记忆耗时 仅盲拧',
+  `exec_duration` INT NOT NULL DEFAULT 0 COMMENT '执行耗时 仅盲拧',
   `step` INT NOT NULL COMMENT '步数',
   `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:启用 2:冻结 3:删除',
-  `scramble` VARCHAR(255) NOT NULL COMMENT '打乱公式',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
   `solution` TEXT NOT NULL COMMENT '还原公式',
+  `move_times` TEXT NOT NULL COMMENT '每一步距开始的耗时(逗号分隔), 用于回放',
   `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `session_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '打乱组挑战ID 0:非打乱组',
+  `challenge_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '挑战的原记录ID 0:非挑战记录',
+  `duplicate_of` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '重复提交的最早记录ID 0:非重复 仅升级前的旧数据',
   `created_at` DATETIME NOT NULL COMMENT '创建时间',
   `updated_at` DATETIME NOT NULL COMMENT '更新时间',
   PRIMARY KEY (`id`)
@@ -65,15 +76,21 @@ CREATE TABLE IF NOT EXISTS `record` (
 
 -- 为`record`表添加索引，以提高查询效率
 ALTER TABLE `record` ADD INDEX `idx_record_user_id` (`user_id`);
-ALTER TABLE `record` ADD INDEX `idx_record_dimension` (`dimension`);
+ALTER TABLE `record` ADD INDEX `idx_record_width_height` (`width`, `height`);
 ALTER TABLE `record` ADD INDEX `idx_record_type` (`type`);
 ALTER TABLE `record` ADD INDEX `idx_record_status` (`status`);
+ALTER TABLE `record` ADD INDEX `idx_record_idx` (`idx`);
+ALTER TABLE `record` ADD INDEX `idx_record_challenge_id` (`challenge_id`);
+-- 同一用户同一打乱同一类型只允许提交一次记录
+ALTER TABLE `record` ADD UNIQUE INDEX `idx_record_user_id_idx_type` (`user_id`, `idx`, `type`, `duplicate_of`);
 
 DROP TABLE IF EXISTS `record_best_single`;
 CREATE TABLE IF NOT EXISTS `record_best_single` (
   `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
   `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
-  `dimension` TINYINT(1) NOT NULL COMMENT '阶数 3 | 4 | 5 | 6',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
   `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
   `record_duration` INT NOT NULL COMMENT '记录耗时',
   `record_step` INT NOT NULL COMMENT '记录步数',
@@ -87,16 +104,42 @@ CREATE TABLE IF NOT EXISTS `record_best_single` (
 
 -- 为`record_best_single`表添加索引，以提高按事件类型进行的查询效率
 ALTER TABLE `record_best_single` ADD INDEX `idx_record_best_single_user_id` (`user_id`);
-ALTER TABLE `record_best_single` ADD INDEX `idx_record_best_single_dimension` (`dimension`);
+ALTER TABLE `record_best_single` ADD INDEX `idx_record_best_single_width_height` (`width`, `height`);
 ALTER TABLE `record_best_single` ADD INDEX `idx_record_best_single_record_duration` (`record_duration`);
 ALTER TABLE `record_best_single` ADD INDEX `idx_record_best_single_ranked` (`ranked`);
 
 
+DROP TABLE IF EXISTS `record_best_blindfold`;
+CREATE TABLE IF NOT EXISTS `record_best_blindfold` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
+  `record_duration` INT NOT NULL COMMENT '记录总耗时',
+  `record_memo_duration` INT NOT NULL COMMENT '记录记忆耗时',
+  `record_exec_duration` INT NOT NULL COMMENT '记录执行耗时',
+  `record_step` INT NOT NULL COMMENT '记录步数',
+  `record_break_count` INT NOT NULL DEFAULT 1 COMMENT '打破最佳盲拧记录的次数',
+  `ranked` INT UNSIGNED COMMENT '排名',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '最佳盲拧记录表';
+
+-- 为`record_best_blindfold`表添加索引，以提高查询效率
+ALTER TABLE `record_best_blindfold` ADD INDEX `idx_record_best_blindfold_user_id` (`user_id`);
+ALTER TABLE `record_best_blindfold` ADD INDEX `idx_record_best_blindfold_width_height` (`width`, `height`);
+ALTER TABLE `record_best_blindfold` ADD INDEX `idx_record_best_blindfold_record_duration` (`record_duration`);
+
 DROP TABLE IF EXISTS `record_best_average`;
 CREATE TABLE IF NOT EXISTS `record_best_average` (
   `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
   `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
-  `dimension` TINYINT(1) NOT NULL COMMENT '阶数 3 | 4 | 5 | 6',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
   `type` TINYINT(5) NOT NULL COMMENT '类型 5:5次平均 12:12次平均',
   `record_ids` TEXT NOT NULL COMMENT '记录ID',
   `record_average_duration` INT NOT NULL COMMENT '记录平均用时',
@@ -109,7 +152,7 @@ CREATE TABLE IF NOT EXISTS `record_best_average` (
 
 -- 为`record_best_average`表添加索引，以提高按事件类型进行的查询效率
 ALTER TABLE `record_best_average` ADD INDEX `idx_record_best_average_user_id` (`user_id`);
-ALTER TABLE `record_best_average` ADD INDEX `idx_record_best_average_dimension` (`dimension`);
+ALTER TABLE `record_best_average` ADD INDEX `idx_record_best_average_width_height` (`width`, `height`);
 ALTER TABLE `record_best_average` ADD INDEX `idx_record_best_average_type` (`type`);
 ALTER TABLE `record_best_average` ADD INDEX `idx_record_best_average_record_average_duration` (`record_average_duration`);
 ALTER TABLE `record_best_average` ADD INDEX `idx_record_best_average_ranked` (`ranked`);
@@ -119,7 +162,9 @@ DROP TABLE IF EXISTS `record_best_step`;
 CREATE TABLE IF NOT EXISTS `record_best_step` (
   `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
   `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
-  `dimension` TINYINT(1) NOT NULL COMMENT '阶数 3 | 4 | 5 | 6',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
   `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
   `record_step` INT NOT NULL COMMENT '记录步数',
   `record_break_count` INT NOT NULL DEFAULT 1 COMMENT '打破最佳步数记录的次数',
@@ -131,7 +176,7 @@ CREATE TABLE IF NOT EXISTS `record_best_step` (
 
 -- 为`record_best_step`表添加索引，以提高按事件类型进行的查询效率
 ALTER TABLE `record_best_step` ADD INDEX `idx_record_best_step_user_id` (`user_id`);
-ALTER TABLE `record_best_step` ADD INDEX `idx_record_best_step_dimension` (`dimension`);
+ALTER TABLE `record_best_step` ADD INDEX `idx_record_best_step_width_height` (`width`, `height`);
 ALTER TABLE `record_best_step` ADD INDEX `idx_record_best_step_record_step` (`record_step`);
 ALTER TABLE `record_best_step` ADD INDEX `idx_record_best_step_ranked` (`ranked`);
 
@@ -139,17 +184,23 @@ ALTER TABLE `record_best_step` ADD INDEX `idx_record_best_step_ranked` (`ranked`
 DROP TABLE IF EXISTS `scramble`;
 CREATE TABLE IF NOT EXISTS `scramble` (
   `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
-  `dimension` TINYINT(1) NOT NULL COMMENT '阶数 3 | 4 | 5 | 6',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
   `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
-  `scramble` VARCHAR(255) NOT NULL COMMENT '打乱公式',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `manhattan_distance` INT NOT NULL DEFAULT 0 COMMENT '曼哈顿距离之和',
+  `linear_conflicts` INT NOT NULL DEFAULT 0 COMMENT '线性冲突数',
+  `optimal_length` INT NOT NULL DEFAULT -1 COMMENT '最少单格移动步数 -1:无法计算',
   `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:启用 2:冻结 3:删除',
   `created_at` DATETIME NOT NULL COMMENT '创建时间',
   `updated_at` DATETIME NOT NULL COMMENT '更新时间',
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '打乱表';
 
--- 为`scramble`表添加索引，以提高按阶数进行的查询效率
-ALTER TABLE `scramble` ADD INDEX `idx_scramble_dimension` (`dimension`);
+-- 为`scramble`表添加索引，以提高按尺寸进行的查询效率
+ALTER TABLE `scramble` ADD INDEX `idx_scramble_width_height` (`width`, `height`);
 ALTER TABLE `scramble` ADD INDEX `idx_scramble_idx` (`idx`);
 ALTER TABLE `scramble` ADD INDEX `idx_scramble_idx_status` (`status`);
 
@@ -157,8 +208,12 @@ DROP TABLE IF EXISTS `scrambled_user_status`;
 CREATE TABLE IF NOT EXISTS `scrambled_user_status`(
   `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
   `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
-  `dimension` TINYINT(1) NOT NULL COMMENT '阶数 3 | 4 | 5 | 6',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
   `scramble_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱ID',
+  `session_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '打乱组挑战ID 0:非打乱组',
+  `event` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '项目 1:普通 2:盲拧',
   `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:未完成 2:已完成',
   `created_at` DATETIME NOT NULL COMMENT '创建时间',
   `updated_at` DATETIME NOT NULL COMMENT '更新时间',
@@ -167,9 +222,405 @@ CREATE TABLE IF NOT EXISTS `scrambled_user_status`(
 
 -- 为`scrambled_user_status`表添加索引，以提高按用户ID进行的查询效率
 ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_user_id` (`user_id`);
-ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_dimension` (`dimension`);
+ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_width_height` (`width`, `height`);
 ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_scramble_id` (`scramble_id`);
 ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_user_status` (`status`);
+ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_session_id` (`session_id`);
+
+DROP TABLE IF EXISTS `scramble_set`;
+CREATE TABLE IF NOT EXISTS `scramble_set` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `name` VARCHAR(50) NOT NULL COMMENT '名称',
+  `creator_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '创建者ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `count` INT NOT NULL COMMENT '打乱数量',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:启用 2:冻结 3:删除',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '打乱组表';
+
+-- 为`scramble_set`表添加索引，以提高查询效率
+ALTER TABLE `scramble_set` ADD INDEX `idx_scramble_set_creator_id` (`creator_id`);
+ALTER TABLE `scramble_set` ADD INDEX `idx_scramble_set_width_height` (`width`, `height`);
+ALTER TABLE `scramble_set` ADD INDEX `idx_scramble_set_status` (`status`);
+
+DROP TABLE IF EXISTS `scramble_set_item`;
+CREATE TABLE IF NOT EXISTS `scramble_set_item` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `set_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱组ID',
+  `seq` INT NOT NULL COMMENT '序号 从1开始',
+  `scramble_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱ID',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '打乱组打乱表';
+
+-- 同一打乱组中序号唯一
+ALTER TABLE `scramble_set_item` ADD UNIQUE INDEX `idx_scramble_set_item_set_id_seq` (`set_id`, `seq`);
+
+DROP TABLE IF EXISTS `scramble_session`;
+CREATE TABLE IF NOT EXISTS `scramble_session` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `set_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱组ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `current` INT NOT NULL DEFAULT 1 COMMENT '当前打乱的序号',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已完成',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '打乱组挑战表';
+
+-- 每个用户对同一打乱组只有一次挑战
+ALTER TABLE `scramble_session` ADD UNIQUE INDEX `idx_scramble_session_user_id_set_id` (`user_id`, `set_id`);
+ALTER TABLE `scramble_session` ADD INDEX `idx_scramble_session_set_id` (`set_id`);
+
+DROP TABLE IF EXISTS `relay`;
+CREATE TABLE IF NOT EXISTS `relay` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `event` VARCHAR(100) NOT NULL COMMENT '项目 各段尺寸按顺序拼接',
+  `leg_count` INT NOT NULL COMMENT '段数',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '总耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '总步数',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已完成 3:已放弃',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '接力表';
+
+-- 为`relay`表添加索引，以提高查询效率
+ALTER TABLE `relay` ADD INDEX `idx_relay_user_id` (`user_id`);
+ALTER TABLE `relay` ADD INDEX `idx_relay_event` (`event`);
+ALTER TABLE `relay` ADD INDEX `idx_relay_status` (`status`);
+
+DROP TABLE IF EXISTS `relay_leg`;
+CREATE TABLE IF NOT EXISTS `relay_leg` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `relay_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '接力ID',
+  `seq` INT NOT NULL COMMENT '序号 从1开始',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `scramble_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱ID',
+  `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `solution` TEXT NOT NULL COMMENT '解法',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '分段耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '步数',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '接力分段表';
+
+-- 同一接力中序号唯一
+ALTER TABLE `relay_leg` ADD UNIQUE INDEX `idx_relay_leg_relay_id_seq` (`relay_id`, `seq`);
+
+DROP TABLE IF EXISTS `record_best_relay`;
+CREATE TABLE IF NOT EXISTS `record_best_relay` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `event` VARCHAR(100) NOT NULL COMMENT '项目 各段尺寸按顺序拼接',
+  `relay_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '接力ID',
+  `record_duration` INT NOT NULL COMMENT '总耗时',
+  `record_step` INT NOT NULL COMMENT '总步数',
+  `record_break_count` INT NOT NULL DEFAULT 1 COMMENT '打破最佳接力记录的次数',
+  `ranked` INT UNSIGNED COMMENT '排名',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '最佳接力记录表';
+
+-- 每个用户每个项目只有一条最佳接力记录
+ALTER TABLE `record_best_relay` ADD UNIQUE INDEX `idx_record_best_relay_user_id_event` (`user_id`, `event`);
+ALTER TABLE `record_best_relay` ADD INDEX `idx_record_best_relay_record_duration` (`record_duration`);
+
+DROP TABLE IF EXISTS `marathon`;
+CREATE TABLE IF NOT EXISTS `marathon` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `mode` TINYINT(1) NOT NULL COMMENT '模式 1:马拉松 2:限时',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `target` INT NOT NULL COMMENT '目标 马拉松:打乱数量 限时:时限(秒)',
+  `current` INT NOT NULL DEFAULT 1 COMMENT '当前打乱的序号',
+  `solved_count` INT NOT NULL DEFAULT 0 COMMENT '完成数量',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '总耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '总步数',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已完成 3:已放弃',
+  `expired_at` DATETIME DEFAULT NULL COMMENT '截止时间 仅限时模式',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '耐力模式表';
+
+-- 为`marathon`表添加索引，以提高查询效率
+ALTER TABLE `marathon` ADD INDEX `idx_marathon_user_id` (`user_id`);
+ALTER TABLE `marathon` ADD INDEX `idx_marathon_mode_width_height_target` (`mode`, `width`, `height`, `target`);
+ALTER TABLE `marathon` ADD INDEX `idx_marathon_status` (`status`);
+
+DROP TABLE IF EXISTS `marathon_solve`;
+CREATE TABLE IF NOT EXISTS `marathon_solve` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `marathon_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '耐力模式ID',
+  `seq` INT NOT NULL COMMENT '序号 从1开始',
+  `scramble_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱ID',
+  `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `solution` TEXT NOT NULL COMMENT '解法',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '步数',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:未完成 2:已完成',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '耐力模式还原表';
+
+-- 同一耐力模式中序号唯一
+ALTER TABLE `marathon_solve` ADD UNIQUE INDEX `idx_marathon_solve_marathon_id_seq` (`marathon_id`, `seq`);
+
+DROP TABLE IF EXISTS `record_best_marathon`;
+CREATE TABLE IF NOT EXISTS `record_best_marathon` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `mode` TINYINT(1) NOT NULL COMMENT '模式 1:马拉松 2:限时',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `target` INT NOT NULL COMMENT '目标 马拉松:打乱数量 限时:时限(秒)',
+  `marathon_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '耐力模式ID',
+  `solved_count` INT NOT NULL COMMENT '完成数量',
+  `record_duration` INT NOT NULL COMMENT '总耗时',
+  `record_step` INT NOT NULL COMMENT '总步数',
+  `record_break_count` INT NOT NULL DEFAULT 1 COMMENT '打破最佳耐力模式记录的次数',
+  `ranked` INT UNSIGNED COMMENT '排名',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '最佳耐力模式记录表';
+
+-- 每个用户每个项目只有一条最佳耐力模式记录
+ALTER TABLE `record_best_marathon` ADD UNIQUE INDEX `idx_record_best_marathon_user_event` (`user_id`, `mode`, `width`, `height`, `target`);
+
+DROP TABLE IF EXISTS `record_moderation`;
+CREATE TABLE IF NOT EXISTS `record_moderation` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录所属用户ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `source` TINYINT(1) NOT NULL COMMENT '来源 1:自动标记 2:用户举报 3:管理员标记',
+  `reason` VARCHAR(255) NOT NULL COMMENT '标记原因',
+  `flag_count` INT NOT NULL DEFAULT 1 COMMENT '被标记次数',
+  `reporter_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '首次举报的用户ID 0:非用户举报',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:待审核 2:已通过 3:已驳回 4:已封禁',
+  `remark` VARCHAR(255) COMMENT '审核备注',
+  `handled_at` DATETIME COMMENT '审核时间',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '记录审核表';
+
+-- 为`record_moderation`表添加索引，以提高审核队列的查询效率
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_record_id` (`record_id`);
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_user_id` (`user_id`);
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_width_height` (`width`, `height`);
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_status` (`status`);
+
+DROP TABLE IF EXISTS `record_moderation_log`;
+CREATE TABLE IF NOT EXISTS `record_moderation_log` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `moderation_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '审核ID',
+  `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录所属用户ID',
+  `action` TINYINT(1) NOT NULL COMMENT '操作 1:通过 2:驳回 3:封禁',
+  `remark` VARCHAR(255) COMMENT '审核备注',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '记录审核日志表';
+
+-- 为`record_moderation_log`表添加索引，以提高按审核ID进行的查询效率
+ALTER TABLE `record_moderation_log` ADD INDEX `idx_record_moderation_log_moderation_id` (`moderation_id`);
+ALTER TABLE `record_moderation_log` ADD INDEX `idx_record_moderation_log_record_id` (`record_id`);
+
+DROP TABLE IF EXISTS `record_report`;
+CREATE TABLE IF NOT EXISTS `record_report` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `reporter_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '举报用户ID',
+  `target_type` TINYINT(1) NOT NULL COMMENT '举报对象类型 1:最佳单次 2:最佳平均 3:最佳步数',
+  `target_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '举报对象ID',
+  `record_ids` VARCHAR(255) NOT NULL COMMENT '举报对象关联的记录ID(升序, 逗号分隔)',
+  `reason` VARCHAR(255) NOT NULL COMMENT '举报原因',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '用户举报表';
+
+-- 同一用户对同一记录只能举报一次, 最佳记录被打破后排行榜的行ID不变, 因此按关联的记录ID去重
+ALTER TABLE `record_report` ADD UNIQUE INDEX `idx_record_report_reporter_records` (`reporter_id`, `target_type`, `record_ids`);
+
+DROP TABLE IF EXISTS `tournament`;
+CREATE TABLE IF NOT EXISTS `tournament` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `name` VARCHAR(200) NOT NULL COMMENT '名称',
+  `format` TINYINT(1) NOT NULL COMMENT '赛制 1:单败淘汰 2:双败淘汰 3:瑞士制',
+  `seed_method` TINYINT(1) NOT NULL COMMENT '种子排序 1:最佳平均 2:最佳单次 3:报名顺序',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `max_players` INT NOT NULL COMMENT '最多选手数量',
+  `best_of` TINYINT(1) NOT NULL COMMENT '每场比赛的局数',
+  `swiss_rounds` INT NOT NULL DEFAULT 0 COMMENT '瑞士制轮数 0:按人数自动计算',
+  `current_round` INT NOT NULL DEFAULT 0 COMMENT '瑞士制当前轮次',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '冠军用户ID',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:报名中 2:进行中 3:已结束 4:已取消',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事表';
+
+-- 为`tournament`表添加索引，以提高按状态查询的效率
+ALTER TABLE `tournament` ADD INDEX `idx_tournament_status` (`status`);
+
+DROP TABLE IF EXISTS `tournament_player`;
+CREATE TABLE IF NOT EXISTS `tournament_player` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `tournament_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '赛事ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `seed` INT NOT NULL DEFAULT 0 COMMENT '种子序号 从1开始, 开赛前为0',
+  `points` INT NOT NULL DEFAULT 0 COMMENT '胜场(瑞士制积分)',
+  `losses` INT NOT NULL DEFAULT 0 COMMENT '负场',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:参赛中 2:已淘汰',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事选手表';
+
+-- 为`tournament_player`表添加唯一索引，同一用户只能报名一次
+ALTER TABLE `tournament_player` ADD UNIQUE INDEX `idx_tournament_player_tournament_user` (`tournament_id`, `user_id`);
+
+DROP TABLE IF EXISTS `tournament_match`;
+CREATE TABLE IF NOT EXISTS `tournament_match` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `tournament_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '赛事ID',
+  `bracket` TINYINT(1) NOT NULL COMMENT '分组 1:胜者组 2:败者组 3:总决赛 4:瑞士制',
+  `round` INT NOT NULL COMMENT '分组内的轮次 从1开始 总决赛第2轮为加赛',
+  `position` INT NOT NULL COMMENT '轮次内的序号 从0开始',
+  `player1_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '选手1用户ID',
+  `player2_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '选手2用户ID',
+  `player1_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手1状态 1:待定 2:已确定 3:轮空',
+  `player2_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手2状态 1:待定 2:已确定 3:轮空',
+  `player1_wins` INT NOT NULL DEFAULT 0 COMMENT '选手1胜局数',
+  `player2_wins` INT NOT NULL DEFAULT 0 COMMENT '选手2胜局数',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者用户ID 0:无',
+  `next_match_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者进入的比赛ID 0:无',
+  `next_slot` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '胜者在下一场比赛中的位置 1:选手1 2:选手2',
+  `loser_match_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '败者进入的比赛ID 0:无',
+  `loser_slot` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '败者在下一场比赛中的位置 1:选手1 2:选手2',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:等待选手 2:进行中 3:已结束',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事比赛表';
+
+-- 为`tournament_match`表添加索引，以提高按赛事查询对阵的效率
+ALTER TABLE `tournament_match` ADD INDEX `idx_tournament_match_tournament_id` (`tournament_id`, `round`);
+
+DROP TABLE IF EXISTS `tournament_game`;
+CREATE TABLE IF NOT EXISTS `tournament_game` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `match_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '比赛ID',
+  `seq` INT NOT NULL COMMENT '局数 从1开始',
+  `idx` BIGINT(20) NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `player1_duration` INT NOT NULL DEFAULT 0 COMMENT '选手1耗时',
+  `player1_step` INT NOT NULL DEFAULT 0 COMMENT '选手1步数',
+  `player1_solution` TEXT NOT NULL COMMENT '选手1解法',
+  `player1_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手1状态 1:未提交 2:完成 3:放弃',
+  `player2_duration` INT NOT NULL DEFAULT 0 COMMENT '选手2耗时',
+  `player2_step` INT NOT NULL DEFAULT 0 COMMENT '选手2步数',
+  `player2_solution` TEXT NOT NULL COMMENT '选手2解法',
+  `player2_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手2状态 1:未提交 2:完成 3:放弃',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者用户ID 0:无',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已结束',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事比赛对局表';
+
+-- 为`tournament_game`表添加唯一索引，同一场比赛的局数不重复
+ALTER TABLE `tournament_game` ADD UNIQUE INDEX `idx_tournament_game_match_seq` (`match_id`, `seq`);
+
+DROP TABLE IF EXISTS `friend_challenge`;
+CREATE TABLE IF NOT EXISTS `friend_challenge` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `challenger_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '发起者用户ID',
+  `opponent_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '被挑战者用户ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `message` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '留言',
+  `deadline` DATETIME NOT NULL COMMENT '截止时间',
+  `challenger_duration` INT NOT NULL DEFAULT 0 COMMENT '发起者耗时',
+  `challenger_step` INT NOT NULL DEFAULT 0 COMMENT '发起者步数',
+  `challenger_solution` TEXT NOT NULL COMMENT '发起者解法',
+  `challenger_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '发起者状态 1:未提交 2:完成 3:放弃',
+  `opponent_duration` INT NOT NULL DEFAULT 0 COMMENT '被挑战者耗时',
+  `opponent_step` INT NOT NULL DEFAULT 0 COMMENT '被挑战者步数',
+  `opponent_solution` TEXT NOT NULL COMMENT '被挑战者解法',
+  `opponent_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '被挑战者状态 1:未提交 2:完成 3:放弃',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者用户ID 0:无',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已结束 3:已拒绝 4:已取消',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '好友挑战表';
+
+-- 为`friend_challenge`表添加索引，以提高按用户与截止时间查询的效率
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_challenger_id` (`challenger_id`);
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_opponent_id` (`opponent_id`);
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_status_deadline` (`status`, `deadline`);
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_idx` (`idx`);
+
+DROP TABLE IF EXISTS `chat_message`;
+CREATE TABLE IF NOT EXISTS `chat_message` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `channel` VARCHAR(32) NOT NULL COMMENT '频道 lobby:大厅 race:<房间ID>:竞速房间 live:<对局ID>:直播与对战',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '发送者用户ID',
+  `content` VARCHAR(800) NOT NULL COMMENT '消息内容',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:正常 2:已删除',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '聊天消息表';
+
+-- 为`chat_message`表添加索引，以提高按频道查询历史消息的效率
+ALTER TABLE `chat_message` ADD INDEX `idx_chat_message_channel` (`channel`, `status`);
+ALTER TABLE `chat_message` ADD INDEX `idx_chat_message_user_id` (`user_id`);
+
+DROP TABLE IF EXISTS `chat_restriction`;
+CREATE TABLE IF NOT EXISTS `chat_restriction` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `type` TINYINT(1) NOT NULL COMMENT '类型 1:禁言 2:封禁',
+  `reason` VARCHAR(800) NOT NULL DEFAULT '' COMMENT '原因',
+  `expired_at` DATETIME COMMENT '到期时间 为空时永久',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:生效中 2:已解除',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '聊天限制表';
+
+-- 为`chat_restriction`表添加索引，以提高按用户查询生效中限制的效率
+ALTER TABLE `chat_restriction` ADD INDEX `idx_chat_restriction_user_id` (`user_id`, `status`);
 
 DROP TABLE IF EXISTS `notification`;
 CREATE TABLE IF NOT EXISTS `notification` (
@@ -207,4 +658,7 @@ ALTER TABLE `notification_type` ADD INDEX `idx_notification_type_status` (`statu
 
 BEGIN;
 INSERT INTO `notification_type` (`id`, `name`, `icon`, `status`, `created_at`, `updated_at`) VALUES (1, '系统通知', '', 1, '2024-02-04 23:11:32', '2024-02-04 23:11:32');
+INSERT INTO `notification_type` (`id`, `name`, `icon`, `status`, `created_at`, `updated_at`) VALUES (2, '举报通知', '', 1, '2024-02-04 23:11:32', '2024-02-04 23:11:32');
+INSERT INTO `notification_type` (`id`, `name`, `icon`, `status`, `created_at`, `updated_at`) VALUES (3, '赛事通知', '', 1, '2024-02-04 23:11:32', '2024-02-04 23:11:32');
+INSERT INTO `notification_type` (`id`, `name`, `icon`, `status`, `created_at`, `updated_at`) VALUES (4, '挑战通知', '', 1, '2024-02-04 23:11:32', '2024-02-04 23:11:32');
 COMMIT;
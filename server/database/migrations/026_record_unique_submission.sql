@@ -0,0 +1,18 @@
+-- 同一用户同一打乱同一类型只允许提交一次记录
+
+USE puzzle;
+
+-- 升级前已存在的重复提交不删除, 只标记为重复: duplicate_of 记为最早一条记录的ID
+-- 重复的记录仍可能被最佳记录引用, 保留后最佳记录无需重新计算
+ALTER TABLE `record` ADD COLUMN `duplicate_of` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '重复提交的最早记录ID 0:非重复 仅升级前的旧数据' AFTER `idx`;
+UPDATE `record` r1
+  JOIN (
+    SELECT `user_id`, `idx`, `type`, MIN(`id`) AS `first_id`
+    FROM `record`
+    GROUP BY `user_id`, `idx`, `type`
+    HAVING COUNT(*) > 1
+  ) r2 ON r1.`user_id` = r2.`user_id` AND r1.`idx` = r2.`idx` AND r1.`type` = r2.`type`
+SET r1.`duplicate_of` = r2.`first_id`
+WHERE r1.`id` > r2.`first_id`;
+-- 新提交的记录 duplicate_of 均为 0, 与最早的一条冲突
+ALTER TABLE `record` ADD UNIQUE INDEX `idx_record_user_id_idx_type` (`user_id`, `idx`, `type`, `duplicate_of`);
@@ -0,0 +1,43 @@
+-- 可疑记录的审核队列与审核日志
+
+USE puzzle;
+
+CREATE TABLE IF NOT EXISTS `record_moderation` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录所属用户ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `source` TINYINT(1) NOT NULL COMMENT '来源 1:自动标记 2:用户举报 3:管理员标记',
+  `reason` VARCHAR(255) NOT NULL COMMENT '标记原因',
+  `flag_count` INT NOT NULL DEFAULT 1 COMMENT '被标记次数',
+  `reporter_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '首次举报的用户ID 0:非用户举报',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:待审核 2:已通过 3:已驳回 4:已封禁',
+  `remark` VARCHAR(255) COMMENT '审核备注',
+  `handled_at` DATETIME COMMENT '审核时间',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '记录审核表';
+
+-- 为`record_moderation`表添加索引，以提高审核队列的查询效率
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_record_id` (`record_id`);
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_user_id` (`user_id`);
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_width_height` (`width`, `height`);
+ALTER TABLE `record_moderation` ADD INDEX `idx_record_moderation_status` (`status`);
+
+CREATE TABLE IF NOT EXISTS `record_moderation_log` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `moderation_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '审核ID',
+  `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录所属用户ID',
+  `action` TINYINT(1) NOT NULL COMMENT '操作 1:通过 2:驳回 3:封禁',
+  `remark` VARCHAR(255) COMMENT '审核备注',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '记录审核日志表';
+
+-- 为`record_moderation_log`表添加索引，以提高按审核ID进行的查询效率
+ALTER TABLE `record_moderation_log` ADD INDEX `idx_record_moderation_log_moderation_id` (`moderation_id`);
+ALTER TABLE `record_moderation_log` ADD INDEX `idx_record_moderation_log_record_id` (`record_id`);
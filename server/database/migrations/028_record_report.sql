@@ -0,0 +1,22 @@
+-- 用户举报排行榜中的可疑记录
+
+USE puzzle;
+
+CREATE TABLE IF NOT EXISTS `record_report` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `reporter_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '举报用户ID',
+  `target_type` TINYINT(1) NOT NULL COMMENT '举报对象类型 1:最佳单次 2:最佳平均 3:最佳步数',
+  `target_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '举报对象ID',
+  `record_ids` VARCHAR(255) NOT NULL COMMENT '举报对象关联的记录ID(升序, 逗号分隔)',
+  `reason` VARCHAR(255) NOT NULL COMMENT '举报原因',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '用户举报表';
+
+-- 同一用户对同一记录只能举报一次, 最佳记录被打破后排行榜的行ID不变, 因此按关联的记录ID去重
+ALTER TABLE `record_report` ADD UNIQUE INDEX `idx_record_report_reporter_records` (`reporter_id`, `target_type`, `record_ids`);
+
+-- 举报处理结果通知
+BEGIN;
+INSERT IGNORE INTO `notification_type` (`id`, `name`, `icon`, `status`, `created_at`, `updated_at`) VALUES (2, '举报通知', '', 1, '2024-02-04 23:11:32', '2024-02-04 23:11:32');
+COMMIT;
@@ -0,0 +1,7 @@
+-- 记录与打乱保存打乱生成器版本, 旧数据均为旧版
+
+USE puzzle;
+
+ALTER TABLE `record` ADD COLUMN `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀' AFTER `idx`;
+
+ALTER TABLE `scramble` ADD COLUMN `version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀' AFTER `scramble`;
@@ -0,0 +1,72 @@
+-- 长方形谜题: 记录、最佳记录与打乱添加宽度与高度, 并以阶数回填旧数据
+
+USE puzzle;
+
+-- ----------------------------
+-- `record`
+-- ----------------------------
+ALTER TABLE `record` ADD COLUMN `width` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '宽度(列数)' AFTER `dimension`;
+ALTER TABLE `record` ADD COLUMN `height` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '高度(行数)' AFTER `width`;
+UPDATE `record` SET `width` = `dimension`, `height` = `dimension` WHERE `width` = 0;
+ALTER TABLE `record` ALTER COLUMN `width` DROP DEFAULT;
+ALTER TABLE `record` ALTER COLUMN `height` DROP DEFAULT;
+ALTER TABLE `record` MODIFY COLUMN `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形';
+-- 大尺寸的打乱公式超过 255 个字符
+ALTER TABLE `record` MODIFY COLUMN `scramble` TEXT NOT NULL COMMENT '打乱公式';
+ALTER TABLE `record` DROP INDEX `idx_record_dimension`;
+ALTER TABLE `record` ADD INDEX `idx_record_width_height` (`width`, `height`);
+
+-- ----------------------------
+-- `record_best_single` `record_best_average` `record_best_step`
+-- ----------------------------
+ALTER TABLE `record_best_single` ADD COLUMN `width` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '宽度(列数)' AFTER `dimension`;
+ALTER TABLE `record_best_single` ADD COLUMN `height` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '高度(行数)' AFTER `width`;
+UPDATE `record_best_single` SET `width` = `dimension`, `height` = `dimension` WHERE `width` = 0;
+ALTER TABLE `record_best_single` ALTER COLUMN `width` DROP DEFAULT;
+ALTER TABLE `record_best_single` ALTER COLUMN `height` DROP DEFAULT;
+ALTER TABLE `record_best_single` MODIFY COLUMN `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形';
+ALTER TABLE `record_best_single` DROP INDEX `idx_record_best_single_dimension`;
+ALTER TABLE `record_best_single` ADD INDEX `idx_record_best_single_width_height` (`width`, `height`);
+
+ALTER TABLE `record_best_average` ADD COLUMN `width` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '宽度(列数)' AFTER `dimension`;
+ALTER TABLE `record_best_average` ADD COLUMN `height` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '高度(行数)' AFTER `width`;
+UPDATE `record_best_average` SET `width` = `dimension`, `height` = `dimension` WHERE `width` = 0;
+ALTER TABLE `record_best_average` ALTER COLUMN `width` DROP DEFAULT;
+ALTER TABLE `record_best_average` ALTER COLUMN `height` DROP DEFAULT;
+ALTER TABLE `record_best_average` MODIFY COLUMN `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形';
+ALTER TABLE `record_best_average` DROP INDEX `idx_record_best_average_dimension`;
+ALTER TABLE `record_best_average` ADD INDEX `idx_record_best_average_width_height` (`width`, `height`);
+
+ALTER TABLE `record_best_step` ADD COLUMN `width` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '宽度(列数)' AFTER `dimension`;
+ALTER TABLE `record_best_step` ADD COLUMN `height` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '高度(行数)' AFTER `width`;
+UPDATE `record_best_step` SET `width` = `dimension`, `height` = `dimension` WHERE `width` = 0;
+ALTER TABLE `record_best_step` ALTER COLUMN `width` DROP DEFAULT;
+ALTER TABLE `record_best_step` ALTER COLUMN `height` DROP DEFAULT;
+ALTER TABLE `record_best_step` MODIFY COLUMN `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形';
+ALTER TABLE `record_best_step` DROP INDEX `idx_record_best_step_dimension`;
+ALTER TABLE `record_best_step` ADD INDEX `idx_record_best_step_width_height` (`width`, `height`);
+
+-- ----------------------------
+-- `scramble`
+-- ----------------------------
+ALTER TABLE `scramble` ADD COLUMN `width` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '宽度(列数)' AFTER `dimension`;
+ALTER TABLE `scramble` ADD COLUMN `height` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '高度(行数)' AFTER `width`;
+UPDATE `scramble` SET `width` = `dimension`, `height` = `dimension` WHERE `width` = 0;
+ALTER TABLE `scramble` ALTER COLUMN `width` DROP DEFAULT;
+ALTER TABLE `scramble` ALTER COLUMN `height` DROP DEFAULT;
+ALTER TABLE `scramble` MODIFY COLUMN `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形';
+ALTER TABLE `scramble` MODIFY COLUMN `scramble` TEXT NOT NULL COMMENT '打乱公式';
+ALTER TABLE `scramble` DROP INDEX `idx_scramble_dimension`;
+ALTER TABLE `scramble` ADD INDEX `idx_scramble_width_height` (`width`, `height`);
+
+-- ----------------------------
+-- `scrambled_user_status`
+-- ----------------------------
+ALTER TABLE `scrambled_user_status` ADD COLUMN `width` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '宽度(列数)' AFTER `dimension`;
+ALTER TABLE `scrambled_user_status` ADD COLUMN `height` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '高度(行数)' AFTER `width`;
+UPDATE `scrambled_user_status` SET `width` = `dimension`, `height` = `dimension` WHERE `width` = 0;
+ALTER TABLE `scrambled_user_status` ALTER COLUMN `width` DROP DEFAULT;
+ALTER TABLE `scrambled_user_status` ALTER COLUMN `height` DROP DEFAULT;
+ALTER TABLE `scrambled_user_status` MODIFY COLUMN `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形';
+ALTER TABLE `scrambled_user_status` DROP INDEX `idx_scrambled_user_status_dimension`;
+ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_width_height` (`width`, `height`);
@@ -0,0 +1,7 @@
+-- 打乱难度, 旧打乱不回填, 最优步数记为 -1(无法计算)
+
+USE puzzle;
+
+ALTER TABLE `scramble` ADD COLUMN `manhattan_distance` INT NOT NULL DEFAULT 0 COMMENT '曼哈顿距离之和' AFTER `version`;
+ALTER TABLE `scramble` ADD COLUMN `linear_conflicts` INT NOT NULL DEFAULT 0 COMMENT '线性冲突数' AFTER `manhattan_distance`;
+ALTER TABLE `scramble` ADD COLUMN `optimal_length` INT NOT NULL DEFAULT -1 COMMENT '最少单格移动步数 -1:无法计算' AFTER `linear_conflicts`;
@@ -0,0 +1,60 @@
+-- 打乱组与用户的打乱组挑战
+
+USE puzzle;
+
+-- ----------------------------
+-- `record` `scrambled_user_status`
+-- ----------------------------
+ALTER TABLE `record` MODIFY COLUMN `type` TINYINT(1) NOT NULL COMMENT '类型 1:练习 2:排行榜 3:对战 4:打乱组';
+ALTER TABLE `record` ADD COLUMN `session_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '打乱组挑战ID 0:非打乱组' AFTER `scramble_version`;
+ALTER TABLE `scrambled_user_status` ADD COLUMN `session_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '打乱组挑战ID 0:非打乱组' AFTER `scramble_id`;
+ALTER TABLE `scrambled_user_status` ADD INDEX `idx_scrambled_user_status_session_id` (`session_id`);
+
+-- ----------------------------
+-- 新增的表, 与 init.sql 保持一致
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS `scramble_set` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `name` VARCHAR(50) NOT NULL COMMENT '名称',
+  `creator_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '创建者ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `count` INT NOT NULL COMMENT '打乱数量',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:启用 2:冻结 3:删除',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '打乱组表';
+
+-- 为`scramble_set`表添加索引，以提高查询效率
+ALTER TABLE `scramble_set` ADD INDEX `idx_scramble_set_creator_id` (`creator_id`);
+ALTER TABLE `scramble_set` ADD INDEX `idx_scramble_set_width_height` (`width`, `height`);
+ALTER TABLE `scramble_set` ADD INDEX `idx_scramble_set_status` (`status`);
+
+CREATE TABLE IF NOT EXISTS `scramble_set_item` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `set_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱组ID',
+  `seq` INT NOT NULL COMMENT '序号 从1开始',
+  `scramble_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱ID',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '打乱组打乱表';
+
+-- 同一打乱组中序号唯一
+ALTER TABLE `scramble_set_item` ADD UNIQUE INDEX `idx_scramble_set_item_set_id_seq` (`set_id`, `seq`);
+
+CREATE TABLE IF NOT EXISTS `scramble_session` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `set_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱组ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `current` INT NOT NULL DEFAULT 1 COMMENT '当前打乱的序号',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已完成',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '打乱组挑战表';
+
+-- 每个用户对同一打乱组只有一次挑战
+ALTER TABLE `scramble_session` ADD UNIQUE INDEX `idx_scramble_session_user_id_set_id` (`user_id`, `set_id`);
+ALTER TABLE `scramble_session` ADD INDEX `idx_scramble_session_set_id` (`set_id`);
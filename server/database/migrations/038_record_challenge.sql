@@ -0,0 +1,9 @@
+-- 挑战任意公开记录, 挑战记录关联原记录
+
+USE puzzle;
+
+ALTER TABLE `record` MODIFY COLUMN `type` TINYINT(1) NOT NULL COMMENT '类型 1:练习 2:排行榜 3:对战 4:打乱组 5:挑战';
+-- 挑战的原记录
+ALTER TABLE `record` ADD COLUMN `challenge_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '挑战的原记录ID 0:非挑战记录' AFTER `session_id`;
+ALTER TABLE `record` ADD INDEX `idx_record_idx` (`idx`);
+ALTER TABLE `record` ADD INDEX `idx_record_challenge_id` (`challenge_id`);
@@ -0,0 +1,61 @@
+-- 多尺寸接力与最佳接力记录
+
+USE puzzle;
+
+CREATE TABLE IF NOT EXISTS `relay` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `event` VARCHAR(100) NOT NULL COMMENT '项目 各段尺寸按顺序拼接',
+  `leg_count` INT NOT NULL COMMENT '段数',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '总耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '总步数',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已完成 3:已放弃',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '接力表';
+
+-- 为`relay`表添加索引，以提高查询效率
+ALTER TABLE `relay` ADD INDEX `idx_relay_user_id` (`user_id`);
+ALTER TABLE `relay` ADD INDEX `idx_relay_event` (`event`);
+ALTER TABLE `relay` ADD INDEX `idx_relay_status` (`status`);
+
+CREATE TABLE IF NOT EXISTS `relay_leg` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `relay_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '接力ID',
+  `seq` INT NOT NULL COMMENT '序号 从1开始',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `scramble_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱ID',
+  `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `solution` TEXT NOT NULL COMMENT '解法',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '分段耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '步数',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '接力分段表';
+
+-- 同一接力中序号唯一
+ALTER TABLE `relay_leg` ADD UNIQUE INDEX `idx_relay_leg_relay_id_seq` (`relay_id`, `seq`);
+
+CREATE TABLE IF NOT EXISTS `record_best_relay` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `event` VARCHAR(100) NOT NULL COMMENT '项目 各段尺寸按顺序拼接',
+  `relay_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '接力ID',
+  `record_duration` INT NOT NULL COMMENT '总耗时',
+  `record_step` INT NOT NULL COMMENT '总步数',
+  `record_break_count` INT NOT NULL DEFAULT 1 COMMENT '打破最佳接力记录的次数',
+  `ranked` INT UNSIGNED COMMENT '排名',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '最佳接力记录表';
+
+-- 每个用户每个项目只有一条最佳接力记录
+ALTER TABLE `record_best_relay` ADD UNIQUE INDEX `idx_record_best_relay_user_id_event` (`user_id`, `event`);
+ALTER TABLE `record_best_relay` ADD INDEX `idx_record_best_relay_record_duration` (`record_duration`);
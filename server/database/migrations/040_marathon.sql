@@ -0,0 +1,69 @@
+-- 马拉松与限时模式及最佳耐力模式记录
+
+USE puzzle;
+
+CREATE TABLE IF NOT EXISTS `marathon` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `mode` TINYINT(1) NOT NULL COMMENT '模式 1:马拉松 2:限时',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `target` INT NOT NULL COMMENT '目标 马拉松:打乱数量 限时:时限(秒)',
+  `current` INT NOT NULL DEFAULT 1 COMMENT '当前打乱的序号',
+  `solved_count` INT NOT NULL DEFAULT 0 COMMENT '完成数量',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '总耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '总步数',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已完成 3:已放弃',
+  `expired_at` DATETIME DEFAULT NULL COMMENT '截止时间 仅限时模式',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '耐力模式表';
+
+-- 为`marathon`表添加索引，以提高查询效率
+ALTER TABLE `marathon` ADD INDEX `idx_marathon_user_id` (`user_id`);
+ALTER TABLE `marathon` ADD INDEX `idx_marathon_mode_width_height_target` (`mode`, `width`, `height`, `target`);
+ALTER TABLE `marathon` ADD INDEX `idx_marathon_status` (`status`);
+
+CREATE TABLE IF NOT EXISTS `marathon_solve` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `marathon_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '耐力模式ID',
+  `seq` INT NOT NULL COMMENT '序号 从1开始',
+  `scramble_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱ID',
+  `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `solution` TEXT NOT NULL COMMENT '解法',
+  `duration` INT NOT NULL DEFAULT 0 COMMENT '耗时',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '步数',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:未完成 2:已完成',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '耐力模式还原表';
+
+-- 同一耐力模式中序号唯一
+ALTER TABLE `marathon_solve` ADD UNIQUE INDEX `idx_marathon_solve_marathon_id_seq` (`marathon_id`, `seq`);
+
+CREATE TABLE IF NOT EXISTS `record_best_marathon` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `mode` TINYINT(1) NOT NULL COMMENT '模式 1:马拉松 2:限时',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `target` INT NOT NULL COMMENT '目标 马拉松:打乱数量 限时:时限(秒)',
+  `marathon_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '耐力模式ID',
+  `solved_count` INT NOT NULL COMMENT '完成数量',
+  `record_duration` INT NOT NULL COMMENT '总耗时',
+  `record_step` INT NOT NULL COMMENT '总步数',
+  `record_break_count` INT NOT NULL DEFAULT 1 COMMENT '打破最佳耐力模式记录的次数',
+  `ranked` INT UNSIGNED COMMENT '排名',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '最佳耐力模式记录表';
+
+-- 每个用户每个项目只有一条最佳耐力模式记录
+ALTER TABLE `record_best_marathon` ADD UNIQUE INDEX `idx_record_best_marathon_user_event` (`user_id`, `mode`, `width`, `height`, `target`);
@@ -0,0 +1,38 @@
+-- 盲拧记录分别保存记忆与执行耗时, 并单独计算最佳盲拧记录
+
+USE puzzle;
+
+-- ----------------------------
+-- `record` `scrambled_user_status`
+-- ----------------------------
+ALTER TABLE `record` MODIFY COLUMN `type` TINYINT(1) NOT NULL COMMENT '类型 1:练习 2:排行榜 3:对战 4:打乱组 5:挑战 6:盲拧';
+ALTER TABLE `record` MODIFY COLUMN `duration` INT NOT NULL COMMENT '耗时 盲拧为记忆与执行耗时之和';
+ALTER TABLE `record` ADD COLUMN `memo_duration` INT NOT NULL DEFAULT 0 COMMENT '记忆耗时 仅盲拧' AFTER `duration`;
+ALTER TABLE `record` ADD COLUMN `exec_duration` INT NOT NULL DEFAULT 0 COMMENT '执行耗时 仅盲拧' AFTER `memo_duration`;
+ALTER TABLE `scrambled_user_status` ADD COLUMN `event` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '项目 1:普通 2:盲拧' AFTER `session_id`;
+
+-- ----------------------------
+-- 新增的表, 与 init.sql 保持一致
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS `record_best_blindfold` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `record_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '记录ID',
+  `record_duration` INT NOT NULL COMMENT '记录总耗时',
+  `record_memo_duration` INT NOT NULL COMMENT '记录记忆耗时',
+  `record_exec_duration` INT NOT NULL COMMENT '记录执行耗时',
+  `record_step` INT NOT NULL COMMENT '记录步数',
+  `record_break_count` INT NOT NULL DEFAULT 1 COMMENT '打破最佳盲拧记录的次数',
+  `ranked` INT UNSIGNED COMMENT '排名',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '最佳盲拧记录表';
+
+-- 为`record_best_blindfold`表添加索引，以提高查询效率
+ALTER TABLE `record_best_blindfold` ADD INDEX `idx_record_best_blindfold_user_id` (`user_id`);
+ALTER TABLE `record_best_blindfold` ADD INDEX `idx_record_best_blindfold_width_height` (`width`, `height`);
+ALTER TABLE `record_best_blindfold` ADD INDEX `idx_record_best_blindfold_record_duration` (`record_duration`);
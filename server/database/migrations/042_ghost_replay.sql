@@ -0,0 +1,5 @@
+-- 记录保存每一步的耗时, 用于回放
+
+USE puzzle;
+
+ALTER TABLE `record` ADD COLUMN `move_times` TEXT NOT NULL COMMENT '每一步距开始的耗时(逗号分隔), 用于回放' AFTER `solution`;
@@ -0,0 +1,33 @@
+-- 大厅与房间聊天, 以及管理员的禁言与封禁
+
+USE puzzle;
+
+CREATE TABLE IF NOT EXISTS `chat_message` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `channel` VARCHAR(32) NOT NULL COMMENT '频道 lobby:大厅 race:<房间ID>:竞速房间 live:<对局ID>:直播与对战',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '发送者用户ID',
+  `content` VARCHAR(800) NOT NULL COMMENT '消息内容',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:正常 2:已删除',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '聊天消息表';
+
+-- 为`chat_message`表添加索引，以提高按频道查询历史消息的效率
+ALTER TABLE `chat_message` ADD INDEX `idx_chat_message_channel` (`channel`, `status`);
+ALTER TABLE `chat_message` ADD INDEX `idx_chat_message_user_id` (`user_id`);
+
+CREATE TABLE IF NOT EXISTS `chat_restriction` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `type` TINYINT(1) NOT NULL COMMENT '类型 1:禁言 2:封禁',
+  `reason` VARCHAR(800) NOT NULL DEFAULT '' COMMENT '原因',
+  `expired_at` DATETIME COMMENT '到期时间 为空时永久',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:生效中 2:已解除',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '聊天限制表';
+
+-- 为`chat_restriction`表添加索引，以提高按用户查询生效中限制的效率
+ALTER TABLE `chat_restriction` ADD INDEX `idx_chat_restriction_user_id` (`user_id`, `status`);
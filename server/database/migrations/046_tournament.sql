@@ -0,0 +1,97 @@
+-- 单败淘汰、双败淘汰与瑞士制赛事
+
+USE puzzle;
+
+CREATE TABLE IF NOT EXISTS `tournament` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `name` VARCHAR(200) NOT NULL COMMENT '名称',
+  `format` TINYINT(1) NOT NULL COMMENT '赛制 1:单败淘汰 2:双败淘汰 3:瑞士制',
+  `seed_method` TINYINT(1) NOT NULL COMMENT '种子排序 1:最佳平均 2:最佳单次 3:报名顺序',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `max_players` INT NOT NULL COMMENT '最多选手数量',
+  `best_of` TINYINT(1) NOT NULL COMMENT '每场比赛的局数',
+  `swiss_rounds` INT NOT NULL DEFAULT 0 COMMENT '瑞士制轮数 0:按人数自动计算',
+  `current_round` INT NOT NULL DEFAULT 0 COMMENT '瑞士制当前轮次',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '冠军用户ID',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:报名中 2:进行中 3:已结束 4:已取消',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事表';
+
+-- 为`tournament`表添加索引，以提高按状态查询的效率
+ALTER TABLE `tournament` ADD INDEX `idx_tournament_status` (`status`);
+
+CREATE TABLE IF NOT EXISTS `tournament_player` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `tournament_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '赛事ID',
+  `user_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '用户ID',
+  `seed` INT NOT NULL DEFAULT 0 COMMENT '种子序号 从1开始, 开赛前为0',
+  `points` INT NOT NULL DEFAULT 0 COMMENT '胜场(瑞士制积分)',
+  `losses` INT NOT NULL DEFAULT 0 COMMENT '负场',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:参赛中 2:已淘汰',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事选手表';
+
+-- 为`tournament_player`表添加唯一索引，同一用户只能报名一次
+ALTER TABLE `tournament_player` ADD UNIQUE INDEX `idx_tournament_player_tournament_user` (`tournament_id`, `user_id`);
+
+CREATE TABLE IF NOT EXISTS `tournament_match` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `tournament_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '赛事ID',
+  `bracket` TINYINT(1) NOT NULL COMMENT '分组 1:胜者组 2:败者组 3:总决赛 4:瑞士制',
+  `round` INT NOT NULL COMMENT '分组内的轮次 从1开始 总决赛第2轮为加赛',
+  `position` INT NOT NULL COMMENT '轮次内的序号 从0开始',
+  `player1_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '选手1用户ID',
+  `player2_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '选手2用户ID',
+  `player1_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手1状态 1:待定 2:已确定 3:轮空',
+  `player2_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手2状态 1:待定 2:已确定 3:轮空',
+  `player1_wins` INT NOT NULL DEFAULT 0 COMMENT '选手1胜局数',
+  `player2_wins` INT NOT NULL DEFAULT 0 COMMENT '选手2胜局数',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者用户ID 0:无',
+  `next_match_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者进入的比赛ID 0:无',
+  `next_slot` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '胜者在下一场比赛中的位置 1:选手1 2:选手2',
+  `loser_match_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '败者进入的比赛ID 0:无',
+  `loser_slot` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '败者在下一场比赛中的位置 1:选手1 2:选手2',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:等待选手 2:进行中 3:已结束',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事比赛表';
+
+-- 为`tournament_match`表添加索引，以提高按赛事查询对阵的效率
+ALTER TABLE `tournament_match` ADD INDEX `idx_tournament_match_tournament_id` (`tournament_id`, `round`);
+
+CREATE TABLE IF NOT EXISTS `tournament_game` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `match_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '比赛ID',
+  `seq` INT NOT NULL COMMENT '局数 从1开始',
+  `idx` BIGINT(20) NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `player1_duration` INT NOT NULL DEFAULT 0 COMMENT '选手1耗时',
+  `player1_step` INT NOT NULL DEFAULT 0 COMMENT '选手1步数',
+  `player1_solution` TEXT NOT NULL COMMENT '选手1解法',
+  `player1_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手1状态 1:未提交 2:完成 3:放弃',
+  `player2_duration` INT NOT NULL DEFAULT 0 COMMENT '选手2耗时',
+  `player2_step` INT NOT NULL DEFAULT 0 COMMENT '选手2步数',
+  `player2_solution` TEXT NOT NULL COMMENT '选手2解法',
+  `player2_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '选手2状态 1:未提交 2:完成 3:放弃',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者用户ID 0:无',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已结束',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '赛事比赛对局表';
+
+-- 为`tournament_game`表添加唯一索引，同一场比赛的局数不重复
+ALTER TABLE `tournament_game` ADD UNIQUE INDEX `idx_tournament_game_match_seq` (`match_id`, `seq`);
+
+-- 赛事通知
+BEGIN;
+INSERT IGNORE INTO `notification_type` (`id`, `name`, `icon`, `status`, `created_at`, `updated_at`) VALUES (3, '赛事通知', '', 1, '2024-02-04 23:11:32', '2024-02-04 23:11:32');
+COMMIT;
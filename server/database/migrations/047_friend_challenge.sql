@@ -0,0 +1,43 @@
+-- 异步好友挑战, 双方使用同一打乱并在截止时间前提交
+
+USE puzzle;
+
+ALTER TABLE `record` MODIFY COLUMN `type` TINYINT(1) NOT NULL COMMENT '类型 1:练习 2:排行榜 3:对战 4:打乱组 5:挑战 6:盲拧 7:好友挑战';
+
+CREATE TABLE IF NOT EXISTS `friend_challenge` (
+  `id` BIGINT(20) UNSIGNED NOT NULL COMMENT '主键ID',
+  `challenger_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '发起者用户ID',
+  `opponent_id` BIGINT(20) UNSIGNED NOT NULL COMMENT '被挑战者用户ID',
+  `dimension` TINYINT(1) NOT NULL COMMENT '阶数(方形边长) 0:非方形',
+  `width` TINYINT(1) NOT NULL COMMENT '宽度(列数)',
+  `height` TINYINT(1) NOT NULL COMMENT '高度(行数)',
+  `idx` BIGINT(20) UNSIGNED NOT NULL COMMENT '打乱随机数',
+  `scramble` TEXT NOT NULL COMMENT '打乱公式',
+  `scramble_version` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '打乱生成器版本 1:旧版 2:均匀',
+  `message` VARCHAR(200) NOT NULL DEFAULT '' COMMENT '留言',
+  `deadline` DATETIME NOT NULL COMMENT '截止时间',
+  `challenger_duration` INT NOT NULL DEFAULT 0 COMMENT '发起者耗时',
+  `challenger_step` INT NOT NULL DEFAULT 0 COMMENT '发起者步数',
+  `challenger_solution` TEXT NOT NULL COMMENT '发起者解法',
+  `challenger_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '发起者状态 1:未提交 2:完成 3:放弃',
+  `opponent_duration` INT NOT NULL DEFAULT 0 COMMENT '被挑战者耗时',
+  `opponent_step` INT NOT NULL DEFAULT 0 COMMENT '被挑战者步数',
+  `opponent_solution` TEXT NOT NULL COMMENT '被挑战者解法',
+  `opponent_status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '被挑战者状态 1:未提交 2:完成 3:放弃',
+  `winner_id` BIGINT(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT '胜者用户ID 0:无',
+  `status` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态 1:进行中 2:已结束 3:已拒绝 4:已取消',
+  `created_at` DATETIME NOT NULL COMMENT '创建时间',
+  `updated_at` DATETIME NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT '好友挑战表';
+
+-- 为`friend_challenge`表添加索引，以提高按用户与截止时间查询的效率
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_challenger_id` (`challenger_id`);
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_opponent_id` (`opponent_id`);
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_status_deadline` (`status`, `deadline`);
+ALTER TABLE `friend_challenge` ADD INDEX `idx_friend_challenge_idx` (`idx`);
+
+-- 挑战通知
+BEGIN;
+INSERT IGNORE INTO `notification_type` (`id`, `name`, `icon`, `status`, `created_at`, `updated_at`) VALUES (4, '挑战通知', '', 1, '2024-02-04 23:11:32', '2024-02-04 23:11:32');
+COMMIT;
@@ -44,6 +44,7 @@ func InitMySQL() {
 			SingularTable: true, // 使用单数表名，启用该选项后，`User` 的表名应该是 `user`
 		},
 		// SkipDefaultTransaction: true, // 禁用默认事务
+		TranslateError: true, // 将数据库错误转换为gorm错误, 如唯一索引冲突转换为 gorm.ErrDuplicatedKey
 	})
 
 	if err != nil {
@@ -0,0 +1,278 @@
+// Package puzzle 数字华容道的核心逻辑: 棋盘、移动、可解性判断、打乱生成与解法校验,
+// 不依赖服务端的数据库与配置, 可以在命令行工具等场景中直接使用
+package puzzle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Board 棋盘, 使用一维数组按行存储, 并记录每个数字所在的下标, 移动时无需重建索引
+type Board struct {
+	width     int   // 宽度(列数)
+	height    int   // 高度(行数)
+	cells     []int // 每个下标上的数字, 0表示空白单元格
+	positions []int // 每个数字所在的下标
+}
+
+// NewBoard 根据按行排列的数字创建棋盘, cells 必须是 0 ~ width*height-1 的排列
+func NewBoard(width, height int, cells []int) (*Board, error) {
+	if width <= 1 || height <= 1 {
+		return nil, ErrInvalidSize
+	}
+
+	N := width * height
+	if len(cells) != N {
+		return nil, ErrInvalidScramble
+	}
+
+	board := &Board{
+		width:     width,
+		height:    height,
+		cells:     make([]int, N),
+		positions: make([]int, N),
+	}
+
+	seen := make([]bool, N)
+	for i, value := range cells {
+		if value < 0 || value >= N || seen[value] {
+			return nil, ErrInvalidScramble
+		}
+
+		seen[value] = true
+		board.cells[i] = value
+		board.positions[value] = i
+	}
+
+	return board, nil
+}
+
+// NewSolvedBoard 创建已还原的棋盘
+func NewSolvedBoard(width, height int) (*Board, error) {
+	cells := make([]int, width*height)
+	for i := 0; i < len(cells)-1; i++ {
+		cells[i] = i + 1
+	}
+
+	return NewBoard(width, height, cells)
+}
+
+// ParseScramble 解析逗号分隔的打乱字符串
+func ParseScramble(width, height int, scramble string) (*Board, error) {
+	values := strings.Split(scramble, ",")
+
+	cells := make([]int, len(values))
+	for i, v := range values {
+		value, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, ErrInvalidScramble
+		}
+		cells[i] = value
+	}
+
+	return NewBoard(width, height, cells)
+}
+
+// FormatScramble 将打乱数组转换为逗号分隔的打乱字符串
+func FormatScramble(cells []int) string {
+	return strings.Trim(strings.Replace(fmt.Sprint(cells), " ", ",", -1), "[]")
+}
+
+// Width 宽度(列数)
+func (b *Board) Width() int {
+	return b.width
+}
+
+// Height 高度(行数)
+func (b *Board) Height() int {
+	return b.height
+}
+
+// Cells 按行排列的数字副本
+func (b *Board) Cells() []int {
+	cells := make([]int, len(b.cells))
+	copy(cells, b.cells)
+	return cells
+}
+
+// At 获取第 row 行第 column 列的数字
+func (b *Board) At(row, column int) int {
+	return b.cells[row*b.width+column]
+}
+
+// Clone 复制棋盘
+func (b *Board) Clone() *Board {
+	board := &Board{
+		width:     b.width,
+		height:    b.height,
+		cells:     make([]int, len(b.cells)),
+		positions: make([]int, len(b.positions)),
+	}
+	copy(board.cells, b.cells)
+	copy(board.positions, b.positions)
+
+	return board
+}
+
+// String 逗号分隔的打乱字符串
+func (b *Board) String() string {
+	return FormatScramble(b.cells)
+}
+
+// Move 点击数字 tile, 数字必须与空白单元格同行或同列, 两者之间的数字依次向空白单元格滑动一格
+func (b *Board) Move(tile int) error {
+	if tile <= 0 || tile >= len(b.cells) {
+		return ErrIllegalMove
+	}
+
+	index := b.positions[tile]
+	nullIndex := b.positions[0]
+
+	row, column := index/b.width, index%b.width
+	nullRow, nullColumn := nullIndex/b.width, nullIndex%b.width
+
+	// 空白单元格每次移动的下标偏移量
+	var offset int
+	switch {
+	case row == nullRow && column > nullColumn: // 同一行, 点击的单元格在空白单元格的右边
+		offset = 1
+	case row == nullRow && column < nullColumn: // 同一行, 点击的单元格在空白单元格的左边
+		offset = -1
+	case column == nullColumn && row > nullRow: // 同一列, 点击的单元格在空白单元格的下边
+		offset = b.width
+	case column == nullColumn && row < nullRow: // 同一列, 点击的单元格在空白单元格的上边
+		offset = -b.width
+	default:
+		return ErrIllegalMove
+	}
+
+	for i := nullIndex; i != index; i += offset {
+		b.cells[i] = b.cells[i+offset]
+		b.positions[b.cells[i]] = i
+	}
+
+	b.cells[index] = 0
+	b.positions[0] = index
+
+	return nil
+}
+
+// Apply 依次执行解法中的移动, 失败时返回 *MoveError, 棋盘停留在失败前的状态
+func (b *Board) Apply(tiles []int) error {
+	for i, tile := range tiles {
+		if err := b.Move(tile); err != nil {
+			return &MoveError{Index: i, Tile: tile, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// ApplyLegacy 按旧版点击规则依次执行解法: 点击空白单元格或与空白单元格不在同一行(列)的数字时不移动,
+// 仅用于校验旧版打乱的历史解法, 其余情况与 Apply 相同
+func (b *Board) ApplyLegacy(tiles []int) error {
+	for i, tile := range tiles {
+		if tile >= 0 && tile < len(b.cells) && !b.isAligned(tile) {
+			continue
+		}
+
+		if err := b.Move(tile); err != nil {
+			return &MoveError{Index: i, Tile: tile, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// LegacyMoves 按旧版点击规则去掉解法中不移动的点击, 返回实际移动的数字, 不改变棋盘
+func (b *Board) LegacyMoves(tiles []int) []int {
+	board := b.Clone()
+	moves := make([]int, 0, len(tiles))
+
+	for _, tile := range tiles {
+		if tile >= 0 && tile < len(board.cells) && !board.isAligned(tile) {
+			continue
+		}
+
+		_ = board.Move(tile)
+		moves = append(moves, tile)
+	}
+
+	return moves
+}
+
+// isAligned 判断数字 tile 是否与空白单元格在同一行或同一列, 空白单元格本身不算
+func (b *Board) isAligned(tile int) bool {
+	if tile == 0 {
+		return false
+	}
+
+	index := b.positions[tile]
+	nullIndex := b.positions[0]
+
+	return index/b.width == nullIndex/b.width || index%b.width == nullIndex%b.width
+}
+
+// IsSolved 判断是否已还原
+func (b *Board) IsSolved() bool {
+	for i := 0; i < len(b.cells)-1; i++ {
+		if b.cells[i] != i+1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsSolvable 判断当前状态是否可解
+func (b *Board) IsSolvable() bool {
+	return IsSolvable(b.width, b.height, b.cells)
+}
+
+// IsSolvable 判断按行排列的数字是否可解
+// 宽度为奇数: 逆序数为偶数时可解
+// 宽度为偶数: 上下移动会使逆序数改变 width-1(奇数), 空格所在行改变1, 两者之和的奇偶性不变,
+// 因此与还原状态(逆序数为0, 空格在第 height-1 行)奇偶性相同时可解
+func IsSolvable(width, height int, cells []int) bool {
+	inversions := 0
+	blankRow := 0
+
+	for i := 0; i < len(cells); i++ {
+		if cells[i] == 0 {
+			blankRow = i / width
+			continue
+		}
+		for j := i + 1; j < len(cells); j++ {
+			if cells[j] != 0 && cells[j] < cells[i] {
+				inversions++
+			}
+		}
+	}
+
+	if width%2 == 1 {
+		return inversions%2 == 0
+	}
+
+	return (inversions+blankRow)%2 == (height-1)%2
+}
+
+// ParseSolution 解析逗号分隔的解法字符串, 每一项为点击的数字
+func ParseSolution(solution string) ([]int, error) {
+	if solution == "" {
+		return []int{}, nil
+	}
+
+	values := strings.Split(solution, ",")
+
+	tiles := make([]int, len(values))
+	for i, v := range values {
+		tile, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, ErrInvalidSolution
+		}
+		tiles[i] = tile
+	}
+
+	return tiles, nil
+}
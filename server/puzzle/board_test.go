@@ -0,0 +1,260 @@
+package puzzle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// solved3x3 3x3 的还原状态
+var solved3x3 = []int{1, 2, 3, 4, 5, 6, 7, 8, 0}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []int
+		tile  int
+		want  []int
+		err   error
+	}{
+		{"左边相邻", solved3x3, 8, []int{1, 2, 3, 4, 5, 6, 7, 0, 8}, nil},
+		{"左边隔一格", solved3x3, 7, []int{1, 2, 3, 4, 5, 6, 0, 7, 8}, nil},
+		{"上边相邻", solved3x3, 6, []int{1, 2, 3, 4, 5, 0, 7, 8, 6}, nil},
+		{"上边隔一格", solved3x3, 3, []int{1, 2, 0, 4, 5, 3, 7, 8, 6}, nil},
+		{"右边隔一格", []int{0, 1, 2, 3, 4, 5, 6, 7, 8}, 2, []int{1, 2, 0, 3, 4, 5, 6, 7, 8}, nil},
+		{"下边隔一格", []int{0, 1, 2, 3, 4, 5, 6, 7, 8}, 6, []int{3, 1, 2, 6, 4, 5, 0, 7, 8}, nil},
+		{"空白单元格", solved3x3, 0, solved3x3, ErrIllegalMove},
+		{"不在同一行列", solved3x3, 1, solved3x3, ErrIllegalMove},
+		{"超出范围", solved3x3, 9, solved3x3, ErrIllegalMove},
+		{"负数", solved3x3, -1, solved3x3, ErrIllegalMove},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, _ := NewBoard(3, 3, tt.cells)
+
+			err := board.Move(tt.tile)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Move(%d) error = %v, want %v", tt.tile, err, tt.err)
+			}
+			if got := board.Cells(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Move(%d) cells = %v, want %v", tt.tile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiles []int
+		want  []int
+		index int // 失败的步数下标, -1 表示成功
+	}{
+		{"空解法", []int{}, solved3x3, -1},
+		{"多步移动", []int{8, 5, 4, 7}, []int{1, 2, 3, 7, 4, 6, 0, 5, 8}, -1},
+		{"往返", []int{8, 8}, solved3x3, -1},
+		{"中途失败停留在失败前的状态", []int{8, 1}, []int{1, 2, 3, 4, 5, 6, 7, 0, 8}, 1},
+		{"点击空白单元格", []int{0}, solved3x3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, _ := NewSolvedBoard(3, 3)
+
+			err := board.Apply(tt.tiles)
+			if tt.index < 0 && err != nil {
+				t.Fatalf("Apply(%v) error = %v", tt.tiles, err)
+			}
+			if tt.index >= 0 {
+				var moveErr *MoveError
+				if !errors.As(err, &moveErr) || moveErr.Index != tt.index || !errors.Is(err, ErrIllegalMove) {
+					t.Fatalf("Apply(%v) error = %v, want MoveError at %d", tt.tiles, err, tt.index)
+				}
+			}
+			if got := board.Cells(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply(%v) cells = %v, want %v", tt.tiles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyLegacy(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiles []int
+		want  []int
+		err   error
+	}{
+		{"忽略空白单元格", []int{0, 8}, []int{1, 2, 3, 4, 5, 6, 7, 0, 8}, nil},
+		{"忽略不在同一行列的数字", []int{1, 8, 1, 5}, []int{1, 2, 3, 4, 0, 6, 7, 5, 8}, nil},
+		{"超出范围", []int{9}, solved3x3, ErrIllegalMove},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, _ := NewSolvedBoard(3, 3)
+
+			moves := board.LegacyMoves(tt.tiles)
+
+			err := board.ApplyLegacy(tt.tiles)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ApplyLegacy(%v) error = %v, want %v", tt.tiles, err, tt.err)
+			}
+			if got := board.Cells(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyLegacy(%v) cells = %v, want %v", tt.tiles, got, tt.want)
+			}
+
+			if tt.err == nil {
+				strict, _ := NewSolvedBoard(3, 3)
+				if err := strict.Apply(moves); err != nil || !reflect.DeepEqual(strict.Cells(), tt.want) {
+					t.Errorf("LegacyMoves(%v) = %v, Apply error = %v", tt.tiles, moves, err)
+				}
+			}
+		})
+	}
+}
+
+func TestIsSolved(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		cells  []int
+		want   bool
+	}{
+		{"3x3 还原", 3, 3, solved3x3, true},
+		{"3x3 移动一步", 3, 3, []int{1, 2, 3, 4, 5, 6, 7, 0, 8}, false},
+		{"3x2 还原", 3, 2, []int{1, 2, 3, 4, 5, 0}, true},
+		{"2x3 还原", 2, 3, []int{1, 2, 3, 4, 5, 0}, true},
+		{"空白单元格在开头", 3, 3, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, _ := NewBoard(tt.width, tt.height, tt.cells)
+			if got := board.IsSolved(); got != tt.want {
+				t.Errorf("IsSolved() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSolvable(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		cells  []int
+		want   bool
+	}{
+		{"3x3 还原", 3, 3, solved3x3, true},
+		{"3x3 交换1和2", 3, 3, []int{2, 1, 3, 4, 5, 6, 7, 8, 0}, false},
+		{"3x3 轮换三个数字", 3, 3, []int{3, 1, 2, 4, 5, 6, 7, 8, 0}, true},
+		{"4x4 还原", 4, 4, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 0}, true},
+		{"4x4 空白单元格上移一行", 4, 4, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 0, 13, 14, 15, 12}, true},
+		{"4x4 交换1和2", 4, 4, []int{2, 1, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 0}, false},
+		{"4x4 空白单元格上移一行后交换1和2", 4, 4, []int{2, 1, 3, 4, 5, 6, 7, 8, 9, 10, 11, 0, 13, 14, 15, 12}, false},
+		{"2x3 还原", 2, 3, []int{1, 2, 3, 4, 5, 0}, true},
+		{"2x3 交换1和2", 2, 3, []int{2, 1, 3, 4, 5, 0}, false},
+		{"4x2 空白单元格上移一行", 4, 2, []int{1, 2, 3, 0, 5, 6, 7, 4}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSolvable(tt.width, tt.height, tt.cells); got != tt.want {
+				t.Errorf("IsSolvable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScramble(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		scramble string
+		want     []int
+		err      error
+	}{
+		{"3x3", 3, 3, "1,2,3,4,5,6,7,8,0", solved3x3, nil},
+		{"3x2", 3, 2, "5,1,0,4,3,2", []int{5, 1, 0, 4, 3, 2}, nil},
+		{"宽度为1", 1, 3, "1,2,0", nil, ErrInvalidSize},
+		{"数量不足", 3, 3, "1,2,3", nil, ErrInvalidScramble},
+		{"数字重复", 3, 3, "1,2,3,4,5,6,7,8,8", nil, ErrInvalidScramble},
+		{"数字超出范围", 3, 3, "1,2,3,4,5,6,7,8,9", nil, ErrInvalidScramble},
+		{"不是数字", 3, 3, "1,2,3,4,5,6,7,8,a", nil, ErrInvalidScramble},
+		{"空字符串", 3, 3, "", nil, ErrInvalidScramble},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseScramble(tt.width, tt.height, tt.scramble)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseScramble(%q) error = %v, want %v", tt.scramble, err, tt.err)
+			}
+			if err == nil && !reflect.DeepEqual(board.Cells(), tt.want) {
+				t.Errorf("ParseScramble(%q) = %v, want %v", tt.scramble, board.Cells(), tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseScramble(f *testing.F) {
+	f.Add(3, 3, "1,2,3,4,5,6,7,8,0")
+	f.Add(3, 2, "5,1,0,4,3,2")
+	f.Add(2, 2, "1,2,3,3")
+	f.Add(4, 4, "")
+	f.Add(0, 0, ",")
+
+	f.Fuzz(func(t *testing.T, width, height int, scramble string) {
+		board, err := ParseScramble(width, height, scramble)
+		if err != nil {
+			return
+		}
+
+		// 格式化后再次解析得到相同的棋盘
+		again, err := ParseScramble(width, height, board.String())
+		if err != nil {
+			t.Fatalf("ParseScramble(%q) error = %v", board.String(), err)
+		}
+		if !reflect.DeepEqual(again.Cells(), board.Cells()) {
+			t.Fatalf("round trip = %v, want %v", again.Cells(), board.Cells())
+		}
+
+		if board.IsSolvable() != IsSolvable(width, height, board.Cells()) {
+			t.Fatalf("IsSolvable mismatch for %v", board.Cells())
+		}
+	})
+}
+
+// BenchmarkMove 在 20x20 的棋盘上来回点击同一行两端的数字, 每次点击滑动19格
+func BenchmarkMove(b *testing.B) {
+	board, _ := NewSolvedBoard(MaxSize, MaxSize)
+	last := MaxSize * (MaxSize - 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := board.Move(last + 1); err != nil {
+			b.Fatal(err)
+		}
+		if err := board.Move(last + MaxSize - 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkApply 在 20x20 的打乱上执行1万步以上的解法
+func BenchmarkApply(b *testing.B) {
+	generator, _ := GetGenerator(VersionUniform)
+	board, _ := NewBoard(MaxSize, MaxSize, generator.Generate(MaxSize, MaxSize, 1))
+	tiles := solve(board)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := board.Clone().Apply(tiles); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package puzzle
+
+import "math"
+
+// DefaultMaxSearchNodes 计算最优步数时默认最多搜索的节点数, 超出后放弃计算
+const DefaultMaxSearchNodes = 300000
+
+// maxOptimalCells 计算最优步数的最大单元格数量, 更大的棋盘在节点上限内几乎不可能完成搜索
+const maxOptimalCells = 12
+
+// Difficulty 打乱难度
+type Difficulty struct {
+	ManhattanDistance int // 所有数字到目标位置的曼哈顿距离之和
+	LinearConflicts   int // 线性冲突数, 同一行(列)中需要让位的最少数字个数之和
+	OptimalLength     int // 最少单格移动步数 -1:无法计算
+}
+
+// Difficulty 计算当前状态的难度, 最优步数在节点上限内无法求出时为-1
+func (b *Board) Difficulty() Difficulty {
+	difficulty, _ := b.DifficultyWithin(DefaultMaxSearchNodes)
+	return difficulty
+}
+
+// DifficultyWithin 计算当前状态的难度, 最优步数最多搜索 maxNodes 个节点, 同时返回实际搜索的节点数,
+// 用于在多次计算之间分配搜索的总量
+func (b *Board) DifficultyWithin(maxNodes int) (Difficulty, int) {
+	difficulty := Difficulty{
+		ManhattanDistance: b.ManhattanDistance(),
+		LinearConflicts:   b.LinearConflicts(),
+		OptimalLength:     -1,
+	}
+
+	nodes := 0
+	if len(b.cells) <= maxOptimalCells && maxNodes > 0 {
+		var (
+			length int
+			ok     bool
+		)
+		length, nodes, ok = b.searchOptimalLength(maxNodes)
+		if ok {
+			difficulty.OptimalLength = length
+		}
+	}
+
+	return difficulty, nodes
+}
+
+// ManhattanDistance 所有数字到目标位置的曼哈顿距离之和, 不计空白单元格
+func (b *Board) ManhattanDistance() int {
+	distance := 0
+	for index, value := range b.cells {
+		if value == 0 {
+			continue
+		}
+
+		distance += abs(index/b.width-(value-1)/b.width) + abs(index%b.width-(value-1)%b.width)
+	}
+
+	return distance
+}
+
+// LinearConflicts 线性冲突数: 目标位置在同一行(列)且顺序颠倒的数字, 至少需要有若干个先离开该行(列)再回来,
+// 每个这样的数字至少额外需要2步, 因此 ManhattanDistance + 2*LinearConflicts 仍然是最优步数的下界
+func (b *Board) LinearConflicts() int {
+	conflicts := 0
+	line := make([]int, 0, max(b.width, b.height))
+
+	for row := 0; row < b.height; row++ {
+		line = line[:0]
+		for column := 0; column < b.width; column++ {
+			value := b.cells[row*b.width+column]
+			if value != 0 && (value-1)/b.width == row {
+				line = append(line, (value-1)%b.width)
+			}
+		}
+		conflicts += len(line) - longestIncreasing(line)
+	}
+
+	for column := 0; column < b.width; column++ {
+		line = line[:0]
+		for row := 0; row < b.height; row++ {
+			value := b.cells[row*b.width+column]
+			if value != 0 && (value-1)%b.width == column {
+				line = append(line, (value-1)/b.width)
+			}
+		}
+		conflicts += len(line) - longestIncreasing(line)
+	}
+
+	return conflicts
+}
+
+// OptimalLength 使用 IDA* 搜索最少单格移动步数, 搜索节点超过 maxNodes 或不可解时返回 false
+func (b *Board) OptimalLength(maxNodes int) (int, bool) {
+	length, _, ok := b.searchOptimalLength(maxNodes)
+	return length, ok
+}
+
+// searchOptimalLength 与 OptimalLength 相同, 同时返回实际搜索的节点数
+func (b *Board) searchOptimalLength(maxNodes int) (int, int, bool) {
+	if !b.IsSolvable() {
+		return 0, 0, false
+	}
+
+	board := b.Clone()
+	nodes := 0
+	bound := board.heuristic()
+
+	for {
+		next, found := board.search(0, bound, -1, &nodes, maxNodes)
+		if found {
+			return next, nodes, true
+		}
+
+		if nodes > maxNodes || next == math.MaxInt {
+			return 0, min(nodes, maxNodes), false
+		}
+
+		bound = next
+	}
+}
+
+// heuristic 最优步数的下界
+func (b *Board) heuristic() int {
+	return b.ManhattanDistance() + 2*b.LinearConflicts()
+}
+
+// search IDA* 的深度优先搜索, 找到解时返回步数, 否则返回超出 bound 的最小估价作为下一轮的上限
+// prev 为上一步空白单元格所在的下标, 避免直接走回头路
+func (b *Board) search(g, bound, prev int, nodes *int, maxNodes int) (int, bool) {
+	h := b.heuristic()
+	if g+h > bound {
+		return g + h, false
+	}
+
+	if h == 0 {
+		return g, true
+	}
+
+	*nodes++
+	if *nodes > maxNodes {
+		return math.MaxInt, false
+	}
+
+	nullIndex := b.positions[0]
+	row, column := nullIndex/b.width, nullIndex%b.width
+
+	min := math.MaxInt
+	for _, next := range [4]int{nullIndex - b.width, nullIndex + b.width, nullIndex - 1, nullIndex + 1} {
+		switch {
+		case next == prev:
+			continue
+		case next == nullIndex-b.width && row == 0:
+			continue
+		case next == nullIndex+b.width && row == b.height-1:
+			continue
+		case next == nullIndex-1 && column == 0:
+			continue
+		case next == nullIndex+1 && column == b.width-1:
+			continue
+		}
+
+		b.swapBlank(next)
+		cost, found := b.search(g+1, bound, nullIndex, nodes, maxNodes)
+		b.swapBlank(nullIndex)
+
+		if found {
+			return cost, true
+		}
+
+		if cost < min {
+			min = cost
+		}
+	}
+
+	return min, false
+}
+
+// swapBlank 将空白单元格与下标 index 上的数字交换
+func (b *Board) swapBlank(index int) {
+	nullIndex := b.positions[0]
+	tile := b.cells[index]
+
+	b.cells[nullIndex], b.cells[index] = tile, 0
+	b.positions[tile], b.positions[0] = nullIndex, index
+}
+
+// longestIncreasing 最长严格递增子序列的长度
+func longestIncreasing(values []int) int {
+	longest := 0
+	lengths := make([]int, len(values))
+
+	for i := range values {
+		lengths[i] = 1
+		for j := 0; j < i; j++ {
+			if values[j] < values[i] && lengths[j]+1 > lengths[i] {
+				lengths[i] = lengths[j] + 1
+			}
+		}
+
+		if lengths[i] > longest {
+			longest = lengths[i]
+		}
+	}
+
+	return longest
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,59 @@
+package puzzle
+
+import "testing"
+
+func TestDifficulty(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		cells  []int
+		want   Difficulty
+	}{
+		{"还原状态", 3, 3, solved3x3, Difficulty{0, 0, 0}},
+		{"移动一步", 3, 3, []int{1, 2, 3, 4, 5, 6, 7, 0, 8}, Difficulty{1, 0, 1}},
+		{"移动四步", 3, 3, []int{1, 2, 3, 7, 4, 6, 0, 5, 8}, Difficulty{4, 0, 4}},
+		{"同一行的线性冲突", 3, 3, []int{3, 1, 2, 4, 5, 6, 7, 8, 0}, Difficulty{4, 1, 16}},
+		{"超过最优步数的单元格上限", 4, 4, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 0, 15}, Difficulty{1, 0, -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, _ := NewBoard(tt.width, tt.height, tt.cells)
+
+			got := board.Difficulty()
+			if got != tt.want {
+				t.Errorf("Difficulty() = %+v, want %+v", got, tt.want)
+			}
+			if got.OptimalLength >= 0 && got.OptimalLength < got.ManhattanDistance+2*got.LinearConflicts {
+				t.Errorf("OptimalLength %d is below the lower bound", got.OptimalLength)
+			}
+		})
+	}
+}
+
+func TestDifficultyWithin(t *testing.T) {
+	board, _ := NewBoard(3, 3, []int{3, 1, 2, 4, 5, 6, 7, 8, 0})
+
+	tests := []struct {
+		name     string
+		maxNodes int
+		length   int
+	}{
+		{"不搜索", 0, -1},
+		{"超出节点上限", 10, -1},
+		{"默认节点上限", DefaultMaxSearchNodes, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			difficulty, nodes := board.DifficultyWithin(tt.maxNodes)
+			if difficulty.OptimalLength != tt.length {
+				t.Errorf("OptimalLength = %d, want %d", difficulty.OptimalLength, tt.length)
+			}
+			if nodes > tt.maxNodes {
+				t.Errorf("nodes = %d, want <= %d", nodes, tt.maxNodes)
+			}
+		})
+	}
+}
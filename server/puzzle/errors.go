@@ -0,0 +1,35 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 校验失败的原因, 可通过 errors.Is 判断
+var (
+	ErrInvalidSize       = errors.New("尺寸不合法")
+	ErrInvalidScramble   = errors.New("打乱格式错误")
+	ErrUnsolvable        = errors.New("打乱不可解")
+	ErrUnknownVersion    = errors.New("打乱生成器版本错误")
+	ErrUnsupportedSize   = errors.New("打乱生成器不支持该尺寸")
+	ErrScrambleMismatch  = errors.New("打乱与种子不匹配")
+	ErrInvalidSolution   = errors.New("解法格式错误")
+	ErrStepCountMismatch = errors.New("步数与解法不一致")
+	ErrIllegalMove       = errors.New("非法移动")
+	ErrNotSolved         = errors.New("未完成还原")
+)
+
+// MoveError 解法中第 Index 步(从0开始)移动数字 Tile 失败
+type MoveError struct {
+	Index int // 步数下标
+	Tile  int // 移动的数字
+	Err   error
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("第 %d 步移动数字 %d 失败: %s", e.Index+1, e.Tile, e.Err)
+}
+
+func (e *MoveError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,217 @@
+package puzzle
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// 打乱种子的最大位数, 保证种子在前端(JavaScript Number)中不丢失精度
+const seedBits = 52
+
+// 生成器使用的盐值, 修改后所有历史打乱都将无法复现
+const salt = "defo1215_puzzle"
+
+// Generator 打乱生成器, 相同的宽高与种子必须生成相同的打乱, 不支持的尺寸返回nil
+type Generator interface {
+	Generate(width, height int, seed int64) []int
+}
+
+// 打乱生成器版本, 已发布的版本号及其生成结果不可修改, 否则历史记录将无法通过校验
+const (
+	VersionLegacy  = 1 // 旧版生成器 LegacyGenerator
+	VersionUniform = 2 // 均匀生成器 UniformGenerator
+)
+
+// CurrentVersion 服务端下发新打乱时使用的生成器版本
+const CurrentVersion = VersionUniform
+
+// 版本与生成器的映射关系
+var generators = map[int]Generator{
+	VersionLegacy:  LegacyGenerator{},
+	VersionUniform: UniformGenerator{},
+}
+
+// GetGenerator 根据版本获取打乱生成器
+func GetGenerator(version int) (Generator, bool) {
+	generator, ok := generators[version]
+	return generator, ok
+}
+
+// LegacyGenerator 旧版打乱生成器, 基于 math.Sin 的伪随机数, 仅用于校验历史记录
+type LegacyGenerator struct{}
+
+// Generate 生成旧版打乱, 旧版生成器仅支持方形
+func (LegacyGenerator) Generate(width, height int, seed int64) []int {
+	if width != height {
+		return nil
+	}
+
+	return shuffleLegacy(width, int(seed))
+}
+
+// UniformGenerator 均匀打乱生成器, 基于 ChaCha20 密钥流, 在所有可解状态中均匀采样
+type UniformGenerator struct{}
+
+// Generate 生成均匀分布的可解打乱
+func (UniformGenerator) Generate(width, height int, seed int64) []int {
+	N := width * height
+	r := newScrambleRand(seed)
+
+	array := make([]int, N)
+	for i := 0; i < N-1; i++ {
+		array[i] = i + 1
+	}
+	array[N-1] = 0
+
+	// Fisher-Yates 洗牌, 得到所有排列中的均匀分布
+	for i := N - 1; i > 0; i-- {
+		j := r.intn(i + 1)
+		array[i], array[j] = array[j], array[i]
+	}
+
+	// 交换数字1和2会翻转排列的奇偶性且不改变空格位置, 是可解与不可解状态之间的一一映射,
+	// 因此修正之后的结果在所有可解状态中仍然是均匀分布的
+	if !IsSolvable(width, height, array) {
+		var one, two int
+		for i, v := range array {
+			switch v {
+			case 1:
+				one = i
+			case 2:
+				two = i
+			}
+		}
+		array[one], array[two] = array[two], array[one]
+	}
+
+	return array
+}
+
+// GenerateSeed 使用系统安全随机数生成打乱种子
+func GenerateSeed() int64 {
+	var buf [8]byte
+	for {
+		_, err := rand.Read(buf[:])
+		if err != nil {
+			panic(fmt.Errorf("generate scramble seed error: %s", err))
+		}
+
+		seed := int64(binary.LittleEndian.Uint64(buf[:]) >> (64 - seedBits))
+		if seed != 0 {
+			return seed
+		}
+	}
+}
+
+// shuffleLegacy 旧版打乱算法, 基于 math.Sin 的伪随机数洗牌, 结果与前端练习模式保持一致
+func shuffleLegacy(n, idx int) []int {
+	N := n * n
+	currentIndex := N
+	randomIndex := 0
+
+	array := make([]int, N)
+	for i := 0; i < N; i++ {
+		array[i] = i + 1
+	}
+	array[N-1] = 0
+
+	seededRandom := func(min, max int) int {
+		x := math.Sin(float64(idx)) * 10000
+		idx++
+		return int((x-math.Floor(x))*float64(max-min+1)) + min
+	}
+
+	for currentIndex != 0 {
+		randomIndex = seededRandom(0, currentIndex-1)
+		currentIndex--
+		array[currentIndex], array[randomIndex] = array[randomIndex], array[currentIndex]
+	}
+
+	if !isSolvableLegacy(n, array) {
+		if array[N-1] != 0 && array[N-2] != 0 {
+			temp := array[N-1]
+			array[N-1] = array[N-2]
+			array[N-2] = temp
+		} else {
+			temp := array[N-4]
+			array[N-4] = array[N-3]
+			array[N-3] = temp
+		}
+	}
+
+	return array
+}
+
+// isSolvableLegacy 旧版可解性判断, 仅用于复现旧版打乱, 其余场景请使用 IsSolvable
+func isSolvableLegacy(n int, numsMap []int) bool {
+	sum := 0
+
+	// 若为偶数阶,需要考虑空格位置
+	if n%2 == 0 {
+		for i := 0; i < n*n; i++ {
+			if numsMap[i] == 0 {
+				sum += int(i/n) + ((i + 1) % n)
+				continue
+			}
+			for j := 0; j < n*n-i; j++ {
+				if numsMap[j+i] < numsMap[i] {
+					sum++
+				}
+			}
+		}
+	} else {
+		// 若为奇数阶
+		for i := 0; i < n*n; i++ {
+			for j := 0; j < n*n-i; j++ {
+				if numsMap[j+i] < numsMap[i] && numsMap[j+i] != 0 && numsMap[i] != 0 {
+					sum++
+				}
+			}
+		}
+	}
+
+	return sum%2 == 0
+}
+
+// scrambleRand 基于 ChaCha20 密钥流的确定性随机数生成器
+type scrambleRand struct {
+	cipher *chacha20.Cipher
+}
+
+// newScrambleRand 根据种子创建随机数生成器
+func newScrambleRand(seed int64) *scrambleRand {
+	key := sha256.Sum256([]byte(fmt.Sprintf("%d%s", seed, salt)))
+	nonce := make([]byte, chacha20.NonceSize)
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(key[:], nonce)
+	if err != nil {
+		panic(fmt.Errorf("create scramble cipher error: %s", err))
+	}
+
+	return &scrambleRand{cipher: cipher}
+}
+
+// uint64 获取下一个64位随机数
+func (r *scrambleRand) uint64() uint64 {
+	var buf [8]byte
+	r.cipher.XORKeyStream(buf[:], buf[:])
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// intn 获取 [0, n) 范围内均匀分布的随机数, 通过拒绝采样消除取模偏差
+func (r *scrambleRand) intn(n int) int {
+	max := uint64(n)
+	threshold := -max % max // 2^64 mod n
+
+	for {
+		v := r.uint64()
+		if v >= threshold {
+			return int(v % max)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package puzzle
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Direction 移动方向, 表示数字滑动的方向(空白单元格向相反方向移动)
+type Direction byte
+
+const (
+	Up    Direction = 'U' // 空白单元格下方的数字向上滑动
+	Down  Direction = 'D' // 空白单元格上方的数字向下滑动
+	Left  Direction = 'L' // 空白单元格右边的数字向左滑动
+	Right Direction = 'R' // 空白单元格左边的数字向右滑动
+)
+
+// DirectionMove 方向记法中的一步, 同一行或同一列的 Count 个数字一起滑动, 与点击一次数字等价
+type DirectionMove struct {
+	Direction Direction
+	Count     int
+}
+
+// String 格式化为 "R3" 的形式, 只移动一格时省略数量
+func (m DirectionMove) String() string {
+	if m.Count == 1 {
+		return string(m.Direction)
+	}
+
+	return string(m.Direction) + strconv.Itoa(m.Count)
+}
+
+// IsDirectionNotation 判断解法是否为方向记法
+func IsDirectionNotation(solution string) bool {
+	return strings.ContainsAny(solution, "UDLRudlr")
+}
+
+// ParseDirections 解析方向记法, 如 "R3 D2 L", 空格可省略, 每个字母(及其后的数量)记为一步
+func ParseDirections(solution string) ([]DirectionMove, error) {
+	moves := make([]DirectionMove, 0)
+
+	for i := 0; i < len(solution); {
+		c := solution[i]
+		if c == ' ' || c == ',' {
+			i++
+			continue
+		}
+
+		direction := Direction(c &^ 0x20) // 转为大写
+		switch direction {
+		case Up, Down, Left, Right:
+		default:
+			return nil, ErrInvalidSolution
+		}
+		i++
+
+		start := i
+		for i < len(solution) && solution[i] >= '0' && solution[i] <= '9' {
+			i++
+		}
+
+		count := 1
+		if i > start {
+			var err error
+			count, err = strconv.Atoi(solution[start:i])
+			if err != nil || count <= 0 {
+				return nil, ErrInvalidSolution
+			}
+		}
+
+		moves = append(moves, DirectionMove{Direction: direction, Count: count})
+	}
+
+	return moves, nil
+}
+
+// FormatDirections 将方向记法格式化为以空格分隔的字符串
+func FormatDirections(moves []DirectionMove) string {
+	tokens := make([]string, len(moves))
+	for i, move := range moves {
+		tokens[i] = move.String()
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+// tileAt 获取按方向移动 count 格时需要点击的数字, 超出棋盘时返回 false
+func (b *Board) tileAt(move DirectionMove) (int, bool) {
+	nullIndex := b.positions[0]
+	nullRow, nullColumn := nullIndex/b.width, nullIndex%b.width
+
+	row, column := nullRow, nullColumn
+	switch move.Direction {
+	case Up:
+		row += move.Count
+	case Down:
+		row -= move.Count
+	case Left:
+		column += move.Count
+	case Right:
+		column -= move.Count
+	}
+
+	if move.Count <= 0 || row < 0 || row >= b.height || column < 0 || column >= b.width {
+		return 0, false
+	}
+
+	return b.cells[row*b.width+column], true
+}
+
+// directionOf 获取点击数字 tile 对应的方向记法
+func (b *Board) directionOf(tile int) (DirectionMove, bool) {
+	if tile <= 0 || tile >= len(b.cells) {
+		return DirectionMove{}, false
+	}
+
+	index := b.positions[tile]
+	nullIndex := b.positions[0]
+
+	row, column := index/b.width, index%b.width
+	nullRow, nullColumn := nullIndex/b.width, nullIndex%b.width
+
+	switch {
+	case row == nullRow && column > nullColumn:
+		return DirectionMove{Direction: Left, Count: column - nullColumn}, true
+	case row == nullRow && column < nullColumn:
+		return DirectionMove{Direction: Right, Count: nullColumn - column}, true
+	case column == nullColumn && row > nullRow:
+		return DirectionMove{Direction: Up, Count: row - nullRow}, true
+	case column == nullColumn && row < nullRow:
+		return DirectionMove{Direction: Down, Count: nullRow - row}, true
+	}
+
+	return DirectionMove{}, false
+}
+
+// MoveDirection 按方向移动, 等价于点击对应位置的数字
+func (b *Board) MoveDirection(move DirectionMove) error {
+	tile, ok := b.tileAt(move)
+	if !ok {
+		return ErrIllegalMove
+	}
+
+	return b.Move(tile)
+}
+
+// DirectionsToTiles 将方向记法转换为从当前状态开始依次点击的数字, 不改变棋盘
+func (b *Board) DirectionsToTiles(moves []DirectionMove) ([]int, error) {
+	board := b.Clone()
+	tiles := make([]int, len(moves))
+
+	for i, move := range moves {
+		tile, ok := board.tileAt(move)
+		if !ok {
+			return nil, &MoveError{Index: i, Tile: tile, Err: ErrIllegalMove}
+		}
+
+		if err := board.Move(tile); err != nil {
+			return nil, &MoveError{Index: i, Tile: tile, Err: err}
+		}
+		tiles[i] = tile
+	}
+
+	return tiles, nil
+}
+
+// TilesToDirections 将从当前状态开始依次点击的数字转换为方向记法, 不改变棋盘
+func (b *Board) TilesToDirections(tiles []int) ([]DirectionMove, error) {
+	board := b.Clone()
+	moves := make([]DirectionMove, len(tiles))
+
+	for i, tile := range tiles {
+		move, ok := board.directionOf(tile)
+		if !ok {
+			return nil, &MoveError{Index: i, Tile: tile, Err: ErrIllegalMove}
+		}
+
+		if err := board.Move(tile); err != nil {
+			return nil, &MoveError{Index: i, Tile: tile, Err: err}
+		}
+		moves[i] = move
+	}
+
+	return moves, nil
+}
+
+// DecodeSolution 解析点击数字或方向记法的解法, 统一返回从当前状态开始依次点击的数字
+func (b *Board) DecodeSolution(solution string) ([]int, error) {
+	if !IsDirectionNotation(solution) {
+		return ParseSolution(solution)
+	}
+
+	moves, err := ParseDirections(solution)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.DirectionsToTiles(moves)
+}
@@ -0,0 +1,63 @@
+package puzzle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseDirections(t *testing.T) {
+	tests := []struct {
+		name     string
+		solution string
+		want     []DirectionMove
+		err      error
+	}{
+		{"空格分隔", "R3 D2 L", []DirectionMove{{Right, 3}, {Down, 2}, {Left, 1}}, nil},
+		{"省略空格与小写", "r3d2l", []DirectionMove{{Right, 3}, {Down, 2}, {Left, 1}}, nil},
+		{"逗号分隔", "U,D", []DirectionMove{{Up, 1}, {Down, 1}}, nil},
+		{"多位数量", "L12", []DirectionMove{{Left, 12}}, nil},
+		{"空字符串", "", []DirectionMove{}, nil},
+		{"未知字母", "X", nil, ErrInvalidSolution},
+		{"数量为0", "R0", nil, ErrInvalidSolution},
+		{"数量在前", "3R", nil, ErrInvalidSolution},
+		{"数量溢出", "R99999999999999999999", nil, ErrInvalidSolution},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDirections(tt.solution)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseDirections(%q) error = %v, want %v", tt.solution, err, tt.err)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDirections(%q) = %v, want %v", tt.solution, got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseDirections(f *testing.F) {
+	f.Add("R3 D2 L")
+	f.Add("r3d2l")
+	f.Add("U,D")
+	f.Add("R0")
+	f.Add("L99999999999999999999")
+
+	f.Fuzz(func(t *testing.T, solution string) {
+		moves, err := ParseDirections(solution)
+		if err != nil {
+			return
+		}
+
+		// 格式化后再次解析得到相同的移动
+		formatted := FormatDirections(moves)
+		again, err := ParseDirections(formatted)
+		if err != nil {
+			t.Fatalf("ParseDirections(%q) error = %v", formatted, err)
+		}
+		if !reflect.DeepEqual(again, moves) {
+			t.Fatalf("round trip = %v, want %v", again, moves)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package puzzle
+
+// Optimization 删除冗余移动的结果, 原解法的点击次数 = 循环抵消减少的次数 + 合并减少的次数 + 结果的点击次数
+type Optimization struct {
+	Tiles     []int // 删除冗余后依次点击的数字
+	Cancelled int   // 往返抵消与循环删除的点击次数, 部分被抵消的点击不计入
+	Merged    int   // 合并同一方向的连续移动减少的点击次数
+}
+
+// Optimize 删除解法中的冗余移动, 返回从当前状态开始依次点击的数字, 不改变棋盘
+func (b *Board) Optimize(tiles []int) ([]int, error) {
+	optimization, err := b.OptimizeDetail(tiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return optimization.Tiles, nil
+}
+
+// OptimizeDetail 删除解法中的冗余移动, 同时返回循环抵消与合并分别减少的点击次数, 不改变棋盘
+// 解法先拆分为单格移动, 回到之前出现过的状态时删除中间的循环(往返抵消是长度为2的循环),
+// 再将同一方向的连续单格移动合并为一次点击, 因此结果的步数不会超过原解法
+// 状态使用哈希值判断是否重复, 哈希冲突导致结果到达的状态与原解法不同时返回原解法
+func (b *Board) OptimizeDetail(tiles []int) (Optimization, error) {
+	moves, err := b.TilesToDirections(tiles)
+	if err != nil {
+		return Optimization{}, err
+	}
+
+	board := b.Clone()
+
+	hash := board.hash()
+	hashes := []uint64{hash}                 // 每一步之后的状态, 第0项为初始状态
+	seen := map[uint64]int{hash: 0}          // 状态在 hashes 中的下标
+	path := make([]Direction, 0, len(tiles)) // 去除循环后的单格移动
+	clicks := make([]int, 0, len(tiles))     // path 中每一步所属的原点击序号
+
+	for click, move := range moves {
+		for i := 0; i < move.Count; i++ {
+			tile, _ := board.tileAt(DirectionMove{Direction: move.Direction, Count: 1})
+			from := board.positions[tile]
+			to := board.positions[0]
+
+			_ = board.Move(tile)
+			hash ^= cellHash(tile, from) ^ cellHash(tile, to) ^ cellHash(0, to) ^ cellHash(0, from)
+
+			// 回到之前的状态, 删除循环
+			if index, ok := seen[hash]; ok {
+				for _, h := range hashes[index+1:] {
+					delete(seen, h)
+				}
+				hashes = hashes[:index+1]
+				path = path[:index]
+				clicks = clicks[:index]
+				continue
+			}
+
+			seen[hash] = len(hashes)
+			hashes = append(hashes, hash)
+			path = append(path, move.Direction)
+			clicks = append(clicks, click)
+		}
+	}
+
+	// 合并同一方向的连续移动, 同时统计不合并时保留下来的原点击次数
+	merged := make([]DirectionMove, 0, len(path))
+	kept := 0
+	for i, direction := range path {
+		if i == 0 || clicks[i] != clicks[i-1] || direction != path[i-1] {
+			kept++
+		}
+
+		if n := len(merged); n > 0 && merged[n-1].Direction == direction {
+			merged[n-1].Count++
+			continue
+		}
+		merged = append(merged, DirectionMove{Direction: direction, Count: 1})
+	}
+
+	optimized, err := b.DirectionsToTiles(merged)
+	if err != nil || !b.sameResult(tiles, optimized) {
+		return Optimization{Tiles: append([]int{}, tiles...)}, nil
+	}
+
+	return Optimization{
+		Tiles:     optimized,
+		Cancelled: len(moves) - kept,
+		Merged:    kept - len(optimized),
+	}, nil
+}
+
+// sameResult 判断从当前状态开始分别执行两个解法后是否到达相同的状态
+func (b *Board) sameResult(tiles, other []int) bool {
+	expected := b.Clone()
+	actual := b.Clone()
+
+	if expected.Apply(tiles) != nil || actual.Apply(other) != nil {
+		return false
+	}
+
+	for i := range expected.cells {
+		if expected.cells[i] != actual.cells[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hash 计算当前状态的哈希值, 每个单元格的哈希值异或得到, 移动时可以增量更新
+func (b *Board) hash() uint64 {
+	var hash uint64
+	for index, value := range b.cells {
+		hash ^= cellHash(value, index)
+	}
+
+	return hash
+}
+
+// cellHash 数字 value 位于下标 index 时的哈希值(splitmix64)
+func cellHash(value, index int) uint64 {
+	x := uint64(value)<<32 | uint64(index)
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,111 @@
+package puzzle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiles []int
+		want  []int
+	}{
+		{"空解法", []int{}, []int{}},
+		{"往返抵消", []int{8, 8}, []int{}},
+		{"嵌套往返抵消", []int{6, 3, 3, 6}, []int{}},
+		{"合并同一方向", []int{8, 7}, []int{7}},
+		{"绕 2x2 转动三圈回到原状态", []int{6, 5, 8, 6, 5, 8, 6, 5, 8, 6, 5, 8}, []int{}},
+		{"删除中间的循环", []int{8, 8, 6, 3}, []int{3}},
+		{"无冗余", []int{8, 5, 4, 7, 8}, []int{8, 5, 4, 7, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, _ := NewSolvedBoard(3, 3)
+
+			got, err := board.Optimize(tt.tiles)
+			if err != nil {
+				t.Fatalf("Optimize(%v) error = %v", tt.tiles, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Optimize(%v) = %v, want %v", tt.tiles, got, tt.want)
+			}
+
+			// 优化前后到达相同的状态
+			before, after := board.Clone(), board.Clone()
+			_ = before.Apply(tt.tiles)
+			if err := after.Apply(got); err != nil || !reflect.DeepEqual(before.Cells(), after.Cells()) {
+				t.Errorf("Optimize(%v) = %v reaches %v, want %v", tt.tiles, got, after.Cells(), before.Cells())
+			}
+		})
+	}
+
+	t.Run("到达的状态不同", func(t *testing.T) {
+		board, _ := NewSolvedBoard(3, 3)
+		if board.sameResult([]int{8, 5}, []int{6, 5}) {
+			t.Error("sameResult([8 5], [6 5]) = true")
+		}
+		if !board.sameResult([]int{8, 8}, []int{}) {
+			t.Error("sameResult([8 8], []) = false")
+		}
+	})
+
+	t.Run("非法移动", func(t *testing.T) {
+		board, _ := NewSolvedBoard(3, 3)
+		if _, err := board.Optimize([]int{1}); err == nil {
+			t.Error("Optimize([1]) error = nil")
+		}
+	})
+
+	t.Run("构造解法", func(t *testing.T) {
+		generator, _ := GetGenerator(VersionUniform)
+		board, _ := NewBoard(5, 4, generator.Generate(5, 4, 42))
+		tiles := solve(board)
+
+		got, err := board.Optimize(tiles)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		after := board.Clone()
+		if err := after.Apply(got); err != nil || !after.IsSolved() || len(got) > len(tiles) {
+			t.Errorf("Optimize() = %d steps, solved = %v, error = %v", len(got), after.IsSolved(), err)
+		}
+	})
+}
+
+func TestOptimizeDetail(t *testing.T) {
+	tests := []struct {
+		name      string
+		tiles     []int
+		want      []int
+		cancelled int
+		merged    int
+	}{
+		{"往返抵消", []int{8, 8}, []int{}, 2, 0},
+		{"合并同一方向", []int{8, 7}, []int{7}, 0, 1},
+		{"部分抵消的点击", []int{7, 7}, []int{8}, 1, 0},
+		{"删除循环后合并", []int{8, 8, 6, 3}, []int{3}, 2, 1},
+		{"无冗余", []int{8, 5, 4, 7, 8}, []int{8, 5, 4, 7, 8}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, _ := NewSolvedBoard(3, 3)
+
+			got, err := board.OptimizeDetail(tt.tiles)
+			if err != nil {
+				t.Fatalf("OptimizeDetail(%v) error = %v", tt.tiles, err)
+			}
+			if !reflect.DeepEqual(got.Tiles, tt.want) || got.Cancelled != tt.cancelled || got.Merged != tt.merged {
+				t.Errorf("OptimizeDetail(%v) = %+v, want {Tiles:%v Cancelled:%d Merged:%d}", tt.tiles, got, tt.want, tt.cancelled, tt.merged)
+			}
+
+			// 原点击次数 = 抵消 + 合并 + 结果
+			if len(tt.tiles) != got.Cancelled+got.Merged+len(got.Tiles) {
+				t.Errorf("OptimizeDetail(%v) = %+v does not add up to %d steps", tt.tiles, got, len(tt.tiles))
+			}
+		})
+	}
+}
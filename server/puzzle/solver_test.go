@@ -0,0 +1,165 @@
+package puzzle
+
+// solver 测试使用的构造性解法: 逐行归位直到剩余两行, 再逐列归位直到剩余 2x2, 每一步只移动一格
+// 解法远非最优, 仅用于为任意尺寸的打乱构造可以通过校验的解法
+type solver struct {
+	board  *Board
+	locked []bool // 已归位的单元格
+	tiles  []int  // 依次点击的数字
+}
+
+// solve 构造从当前状态到还原状态的解法, 不改变棋盘, 不可解时返回 nil
+func solve(b *Board) []int {
+	s := &solver{
+		board:  b.Clone(),
+		locked: make([]bool, len(b.cells)),
+	}
+
+	w, h := b.width, b.height
+
+	// 逐行归位, 每行最后两个数字先分别放到最后一列的本行与下一行, 再一起移入
+	for r := 0; r < h-2; r++ {
+		for c := 0; c < w-2; c++ {
+			s.place(r*w+c+1, r*w+c)
+		}
+
+		a, b := r*w+w-1, r*w+w
+		s.pair(a, b, r*w+w-1, (r+1)*w+w-1, r*w+w-2, (h-1)*w)
+	}
+
+	// 剩余两行逐列归位, 每列的两个数字先分别放到最后一行的本列与下一列, 再一起移入
+	for c := 0; c < w-2; c++ {
+		a, b := (h-2)*w+c+1, (h-1)*w+c+1
+		s.pair(a, b, (h-1)*w+c, (h-1)*w+c+1, (h-2)*w+c, h*w-1)
+	}
+
+	// 剩余 2x2 沿顺时针方向转动空白单元格
+	cycle := []int{(h-2)*w + w - 2, (h-2)*w + w - 1, (h-1)*w + w - 1, (h-1)*w + w - 2}
+	for i := 0; i < 12 && !s.board.IsSolved(); i++ {
+		blank := s.board.positions[0]
+		for j, index := range cycle {
+			if index == blank {
+				s.click(cycle[(j+1)%len(cycle)])
+				break
+			}
+		}
+	}
+
+	if !s.board.IsSolved() {
+		return nil
+	}
+
+	return s.tiles
+}
+
+// place 将数字 tile 移动到下标 target 并锁定
+func (s *solver) place(tile, target int) {
+	s.route(tile, target)
+	s.locked[target] = true
+}
+
+// pair 将数字 a、b 分别移动到 aTemp、bTemp, 再将空白单元格移动到 blank, 依次点击 a、b 后 a 位于 blank, b 位于 aTemp
+// 锁定 a 后 blank 只剩一个相邻单元格, b 或空白单元格被困在该处时先将 b 移动到远离角落的 far 再重试
+func (s *solver) pair(a, b, aTemp, bTemp, blank, far int) {
+	for attempt := 0; attempt < 3; attempt++ {
+		s.route(a, aTemp)
+		s.locked[aTemp] = true
+
+		if s.board.positions[0] == blank {
+			s.click(s.neighbors(blank)[0])
+		}
+
+		if s.route(b, bTemp) {
+			break
+		}
+
+		s.locked[aTemp] = false
+		s.route(b, far)
+	}
+	s.locked[bTemp] = true
+	s.route(0, blank)
+
+	s.click(aTemp)
+	s.click(bTemp)
+
+	s.locked[bTemp] = false
+	s.locked[blank] = true
+}
+
+// route 广度优先搜索(数字位置, 空白位置)的状态, 以最少的单格移动将数字 tile 移动到下标 target, 不经过锁定的单元格
+// tile 为0时只移动空白单元格, 无法到达时返回 false 且不改变棋盘
+func (s *solver) route(tile, target int) bool {
+	N := len(s.board.cells)
+	start := s.board.positions[tile]*N + s.board.positions[0]
+
+	parent := make([]int, N*N)
+	for i := range parent {
+		parent[i] = -1
+	}
+	parent[start] = start
+
+	queue := []int{start}
+	goal := -1
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		if state/N == target {
+			goal = state
+			break
+		}
+
+		for _, next := range s.neighbors(state % N) {
+			position := state / N
+			if tile != 0 && next == position {
+				position = state % N
+			} else if tile == 0 {
+				position = next
+			}
+
+			nextState := position*N + next
+			if parent[nextState] == -1 {
+				parent[nextState] = state
+				queue = append(queue, nextState)
+			}
+		}
+	}
+
+	if goal == -1 {
+		return false
+	}
+
+	var blanks []int
+	for state := goal; state != start; state = parent[state] {
+		blanks = append(blanks, state%N)
+	}
+
+	for i := len(blanks) - 1; i >= 0; i-- {
+		s.click(blanks[i])
+	}
+
+	return true
+}
+
+// neighbors 与下标 index 相邻且未锁定的单元格
+func (s *solver) neighbors(index int) []int {
+	w, h := s.board.width, s.board.height
+	row, column := index/w, index%w
+
+	result := make([]int, 0, 4)
+	for _, next := range [][2]int{{row - 1, column}, {row + 1, column}, {row, column - 1}, {row, column + 1}} {
+		if next[0] < 0 || next[0] >= h || next[1] < 0 || next[1] >= w || s.locked[next[0]*w+next[1]] {
+			continue
+		}
+		result = append(result, next[0]*w+next[1])
+	}
+
+	return result
+}
+
+// click 点击下标 index 上与空白单元格相邻的数字
+func (s *solver) click(index int) {
+	tile := s.board.cells[index]
+	_ = s.board.Move(tile)
+	s.tiles = append(s.tiles, tile)
+}
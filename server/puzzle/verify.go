@@ -0,0 +1,71 @@
+package puzzle
+
+// MaxSize 校验解法时棋盘的最大边长, 超过该值的棋盘生成打乱与判断可解性的开销过大
+const MaxSize = 20
+
+// Params 解法校验参数
+type Params struct {
+	Width     int    // 宽度(列数)
+	Height    int    // 高度(行数)
+	Version   int    // 打乱生成器版本
+	Seed      int64  // 打乱种子
+	Scramble  string // 逗号分隔的打乱
+	Solution  string // 逗号分隔的点击数字, 或方向记法(如 "R3 D2 L")
+	StepCount int    // 步数, 方向记法中每个字母记为一步
+}
+
+// Verify 校验解法: 打乱必须由对应版本的生成器根据种子生成, 解法的步数与 StepCount 一致,
+// 且每一步都是合法移动, 执行完毕后棋盘处于还原状态, 校验失败时返回具体原因
+func Verify(params Params) error {
+	if params.Width <= 1 || params.Height <= 1 || params.Width > MaxSize || params.Height > MaxSize {
+		return ErrInvalidSize
+	}
+
+	generator, ok := GetGenerator(params.Version)
+	if !ok {
+		return ErrUnknownVersion
+	}
+
+	expected := generator.Generate(params.Width, params.Height, params.Seed)
+	if expected == nil {
+		return ErrUnsupportedSize
+	}
+
+	if FormatScramble(expected) != params.Scramble {
+		return ErrScrambleMismatch
+	}
+
+	board, err := ParseScramble(params.Width, params.Height, params.Scramble)
+	if err != nil {
+		return err
+	}
+
+	if !board.IsSolvable() {
+		return ErrUnsolvable
+	}
+
+	tiles, err := board.DecodeSolution(params.Solution)
+	if err != nil {
+		return err
+	}
+
+	if len(tiles) != params.StepCount {
+		return ErrStepCountMismatch
+	}
+
+	// 旧版点击规则会忽略无效点击, 历史解法中可能包含这样的点击
+	if params.Version == VersionLegacy {
+		err = board.ApplyLegacy(tiles)
+	} else {
+		err = board.Apply(tiles)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !board.IsSolved() {
+		return ErrNotSolved
+	}
+
+	return nil
+}
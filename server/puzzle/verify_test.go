@@ -0,0 +1,212 @@
+package puzzle
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// formatSolution 将点击的数字转换为逗号分隔的解法字符串
+func formatSolution(tiles []int) string {
+	values := make([]string, len(tiles))
+	for i, tile := range tiles {
+		values[i] = strconv.Itoa(tile)
+	}
+
+	return strings.Join(values, ",")
+}
+
+// solvedParams 根据生成器的打乱与构造的解法创建可以通过校验的参数
+func solvedParams(t testing.TB, version, width, height int, seed int64) Params {
+	generator, _ := GetGenerator(version)
+	cells := generator.Generate(width, height, seed)
+
+	board, err := NewBoard(width, height, cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tiles := solve(board)
+
+	return Params{
+		Width:     width,
+		Height:    height,
+		Version:   version,
+		Seed:      seed,
+		Scramble:  FormatScramble(cells),
+		Solution:  formatSolution(tiles),
+		StepCount: len(tiles),
+	}
+}
+
+// TestVerify 每种校验失败的原因各一个用例
+// 打乱必须与生成器的结果一致, 因此 ErrInvalidScramble 与 ErrUnsolvable 无法通过 Verify 触发,
+// 分别由 TestParseScramble 与 TestIsSolvable 覆盖
+func TestVerify(t *testing.T) {
+	valid := solvedParams(t, VersionUniform, 3, 3, 20240101)
+	legacy := solvedParams(t, VersionLegacy, 3, 3, 123456)
+
+	board, _ := ParseScramble(3, 3, valid.Scramble)
+	tiles, _ := ParseSolution(valid.Solution)
+	moves, _ := board.TilesToDirections(tiles)
+
+	// 旧版点击规则忽略点击空白单元格与不在同一行列的数字
+	legacyBoard, _ := ParseScramble(3, 3, legacy.Scramble)
+	ignored := 0
+	for _, tile := range legacyBoard.Cells() {
+		if tile != 0 && !legacyBoard.isAligned(tile) {
+			ignored = tile
+			break
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *Params)
+		err    error
+	}{
+		{"通过", func(p *Params) {}, nil},
+		{"方向记法", func(p *Params) {
+			p.Solution = FormatDirections(moves)
+			p.StepCount = len(moves)
+		}, nil},
+		{"宽度过小", func(p *Params) { p.Width = 1 }, ErrInvalidSize},
+		{"宽度过大", func(p *Params) { p.Width = MaxSize + 1 }, ErrInvalidSize},
+		{"版本错误", func(p *Params) { p.Version = 0 }, ErrUnknownVersion},
+		{"旧版生成器不支持非方形", func(p *Params) {
+			p.Version = VersionLegacy
+			p.Width = 4
+		}, ErrUnsupportedSize},
+		{"打乱与种子不匹配", func(p *Params) { p.Seed++ }, ErrScrambleMismatch},
+		{"解法格式错误", func(p *Params) { p.Solution = "1,a" }, ErrInvalidSolution},
+		{"方向记法格式错误", func(p *Params) { p.Solution = "R0" }, ErrInvalidSolution},
+		{"步数不一致", func(p *Params) { p.StepCount++ }, ErrStepCountMismatch},
+		{"非法移动", func(p *Params) {
+			p.Solution = "0," + p.Solution
+			p.StepCount++
+		}, ErrIllegalMove},
+		{"未完成还原", func(p *Params) {
+			p.Solution = ""
+			p.StepCount = 0
+		}, ErrNotSolved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := valid
+			tt.modify(&params)
+
+			if err := Verify(params); !errors.Is(err, tt.err) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	t.Run("旧版解法", func(t *testing.T) {
+		if err := Verify(legacy); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+	})
+
+	t.Run("旧版解法中的无效点击", func(t *testing.T) {
+		params := legacy
+		params.Solution = "0," + strconv.Itoa(ignored) + "," + params.Solution
+		params.StepCount += 2
+
+		if err := Verify(params); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+	})
+
+	t.Run("旧版解法中超出范围的数字", func(t *testing.T) {
+		params := legacy
+		params.Solution = "9," + params.Solution
+		params.StepCount++
+
+		if err := Verify(params); !errors.Is(err, ErrIllegalMove) {
+			t.Errorf("Verify() error = %v, want %v", err, ErrIllegalMove)
+		}
+	})
+}
+
+func FuzzVerify(f *testing.F) {
+	valid := solvedParams(f, VersionUniform, 3, 3, 1)
+	f.Add(uint8(3), uint8(3), int64(1), valid.Solution)
+	f.Add(uint8(3), uint8(3), int64(1), "R2 D")
+	f.Add(uint8(2), uint8(4), int64(7), "8,0,-1")
+	f.Add(uint8(4), uint8(4), int64(99), "U99999999999999999999")
+
+	f.Fuzz(func(t *testing.T, width, height uint8, seed int64, solution string) {
+		params := Params{
+			Width:    2 + int(width%3),
+			Height:   2 + int(height%3),
+			Version:  VersionUniform,
+			Seed:     seed,
+			Solution: solution,
+		}
+
+		generator, _ := GetGenerator(params.Version)
+		params.Scramble = FormatScramble(generator.Generate(params.Width, params.Height, seed))
+
+		board, _ := ParseScramble(params.Width, params.Height, params.Scramble)
+		tiles, err := board.DecodeSolution(solution)
+		if err == nil {
+			params.StepCount = len(tiles)
+		}
+
+		if err := Verify(params); err != nil {
+			return
+		}
+
+		// 通过校验的解法转换为另一种记法后仍然通过校验
+		moves, err := board.TilesToDirections(tiles)
+		if err != nil {
+			t.Fatalf("TilesToDirections() error = %v", err)
+		}
+
+		params.Solution, params.StepCount = FormatDirections(moves), len(moves)
+		if IsDirectionNotation(solution) {
+			params.Solution, params.StepCount = formatSolution(tiles), len(tiles)
+		}
+
+		if err := Verify(params); err != nil {
+			t.Fatalf("Verify(%q) error = %v", params.Solution, err)
+		}
+	})
+}
+
+// BenchmarkVerify 校验 20x20 打乱的构造解法, 解法约2万步单格移动
+func BenchmarkVerify(b *testing.B) {
+	const seed = 1
+
+	generator, _ := GetGenerator(VersionUniform)
+	cells := generator.Generate(MaxSize, MaxSize, seed)
+
+	board, err := NewBoard(MaxSize, MaxSize, cells)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	tiles := solve(board)
+	if len(tiles) < 10000 {
+		b.Fatalf("解法步数 %d 少于 10000", len(tiles))
+	}
+
+	params := Params{
+		Width:     MaxSize,
+		Height:    MaxSize,
+		Version:   VersionUniform,
+		Seed:      seed,
+		Scramble:  FormatScramble(cells),
+		Solution:  formatSolution(tiles),
+		StepCount: len(tiles),
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Verify(params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
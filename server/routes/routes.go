@@ -33,12 +33,17 @@ func InitRouter() *gin.Engine {
 		// 记录
 		record := root.Group("/record").Use(jwt.JWT())
 		{
-			record.POST("/insert", controllers.Record.Insert)                     // 新增记录
-			record.POST("/list-record", controllers.Record.List)                  // 记录列表
-			record.POST("/list-best-single", controllers.RecordBestSingle.List)   // 最佳单次记录列表
-			record.POST("/list-best-average", controllers.RecordBestAverage.List) // 最佳平均记录列表
-			record.POST("/list-best-step", controllers.RecordBestStep.List)       // 最佳步数记录列表
-			record.POST("/update", controllers.Record.Update)                     // 更新记录
+			record.POST("/insert", controllers.Record.Insert)                               // 新增记录
+			record.POST("/list-record", controllers.Record.List)                            // 记录列表
+			record.POST("/list-best-single", controllers.RecordBestSingle.List)             // 最佳单次记录列表
+			record.POST("/list-best-average", controllers.RecordBestAverage.List)           // 最佳平均记录列表
+			record.POST("/list-best-step", controllers.RecordBestStep.List)                 // 最佳步数记录列表
+			record.POST("/list-best-blindfold", controllers.RecordBestBlindfold.List)       // 最佳盲拧记录列表
+			record.POST("/report", controllers.RecordReport.Insert)                         // 举报排行榜记录
+			record.POST("/analyze-solution", controllers.Record.AnalyzeSolution)            // 分析解法
+			record.POST("/get-challenge-scramble", controllers.Record.GetChallengeScramble) // 获取挑战记录的打乱
+			record.POST("/challenge-leaderboard", controllers.Record.ChallengeLeaderboard)  // 同一打乱的排行榜
+			record.POST("/get-ghost", controllers.Record.GetGhost)                          // 获取幽灵
 		}
 
 		// 打乱
@@ -48,6 +53,69 @@ func InitRouter() *gin.Engine {
 			scramble.POST("/get-user-scramble", controllers.Scramble.GetUserScramble) // 获取用户打乱
 		}
 
+		// 打乱组
+		scrambleSet := root.Group("/scramble-set").Use(jwt.JWT())
+		{
+			scrambleSet.POST("/insert", controllers.ScrambleSet.Insert)                           // 创建打乱组
+			scrambleSet.POST("/list", controllers.ScrambleSet.List)                               // 打乱组列表
+			scrambleSet.POST("/start-session", controllers.ScrambleSet.StartSession)              // 开始挑战
+			scrambleSet.POST("/get-session-scramble", controllers.ScrambleSet.GetSessionScramble) // 获取挑战的当前打乱
+			scrambleSet.POST("/result", controllers.ScrambleSet.Result)                           // 成绩对比
+		}
+
+		// 接力
+		relay := root.Group("/relay").Use(jwt.JWT())
+		{
+			relay.POST("/start", controllers.Relay.Start)              // 开始接力
+			relay.POST("/submit", controllers.Relay.Submit)            // 提交接力
+			relay.POST("/list", controllers.Relay.List)                // 接力列表
+			relay.POST("/list-best", controllers.RecordBestRelay.List) // 最佳接力记录列表
+		}
+
+		// 耐力模式
+		marathon := root.Group("/marathon").Use(jwt.JWT())
+		{
+			marathon.POST("/start", controllers.Marathon.Start)              // 开始马拉松或限时
+			marathon.POST("/submit", controllers.Marathon.Submit)            // 提交当前打乱
+			marathon.POST("/finish", controllers.Marathon.Finish)            // 结束
+			marathon.POST("/list", controllers.Marathon.List)                // 耐力模式列表
+			marathon.POST("/list-best", controllers.RecordBestMarathon.List) // 最佳耐力模式记录列表
+		}
+
+		// 直播
+		live := root.Group("/live")
+		{
+			live.POST("/list", controllers.Live.List) // 直播列表
+		}
+
+		// 赛事
+		tournament := root.Group("/tournament").Use(jwt.JWT())
+		{
+			tournament.POST("/list", controllers.Tournament.List)             // 赛事列表
+			tournament.POST("/get", controllers.Tournament.Get)               // 赛事详情
+			tournament.POST("/register", controllers.Tournament.Register)     // 报名
+			tournament.POST("/unregister", controllers.Tournament.Unregister) // 取消报名
+			tournament.POST("/get-match", controllers.Tournament.GetMatch)    // 比赛详情
+			tournament.POST("/submit", controllers.Tournament.Submit)         // 提交一局成绩
+		}
+
+		// 好友挑战
+		friendChallenge := root.Group("/friend-challenge").Use(jwt.JWT())
+		{
+			friendChallenge.POST("/create", controllers.FriendChallenge.Create)   // 发起挑战
+			friendChallenge.POST("/decline", controllers.FriendChallenge.Decline) // 拒绝挑战
+			friendChallenge.POST("/cancel", controllers.FriendChallenge.Cancel)   // 取消挑战
+			friendChallenge.POST("/submit", controllers.FriendChallenge.Submit)   // 提交成绩
+			friendChallenge.POST("/list", controllers.FriendChallenge.List)       // 挑战列表
+			friendChallenge.POST("/get", controllers.FriendChallenge.Get)         // 挑战详情
+		}
+
+		// 聊天
+		chat := root.Group("/chat").Use(jwt.JWT())
+		{
+			chat.POST("/history", controllers.Chat.History) // 历史消息
+		}
+
 		// 通知
 		notification := root.Group("/notification")
 		{
@@ -84,8 +152,38 @@ func InitRouter() *gin.Engine {
 			// 记录
 			recordManage := admin.Group("/record-manage").Use(jwt.AdminJWT())
 			{
-				recordManage.POST("/list", controllers.Admin.ListRecordData)     // 记录列表
-				recordManage.POST("/update", controllers.Admin.UpdateRecordData) // 更新记录
+				recordManage.POST("/list", controllers.Admin.ListRecordData)                             // 记录列表
+				recordManage.POST("/update", controllers.Admin.UpdateRecordData)                         // 更新记录
+				recordManage.POST("/moderation-list", controllers.Admin.ListRecordModerationData)        // 审核队列
+				recordManage.POST("/moderation-flag", controllers.Admin.FlagRecordData)                  // 标记记录
+				recordManage.POST("/moderation-handle", controllers.Admin.HandleRecordModerationData)    // 处理审核
+				recordManage.POST("/moderation-log-list", controllers.Admin.ListRecordModerationLogData) // 审核日志
+			}
+
+			// 聊天
+			chatManage := admin.Group("/chat-manage").Use(jwt.AdminJWT())
+			{
+				chatManage.POST("/list", controllers.Admin.ListChatMessageData)                     // 聊天消息列表
+				chatManage.POST("/update", controllers.Admin.UpdateChatMessageData)                 // 更新聊天消息
+				chatManage.POST("/restrict", controllers.Admin.RestrictChatData)                    // 禁言或封禁
+				chatManage.POST("/restriction-list", controllers.Admin.ListChatRestrictionData)     // 聊天限制列表
+				chatManage.POST("/restriction-update", controllers.Admin.UpdateChatRestrictionData) // 解除聊天限制
+			}
+
+			// WebSocket 网关
+			wsManage := admin.Group("/ws-manage").Use(jwt.AdminJWT())
+			{
+				wsManage.POST("/nodes", controllers.Admin.ListWebsocketNodeData) // 在线网关节点列表
+			}
+
+			// 赛事
+			tournamentManage := admin.Group("/tournament-manage").Use(jwt.AdminJWT())
+			{
+				tournamentManage.POST("/list", controllers.Tournament.List)                 // 赛事列表
+				tournamentManage.POST("/create", controllers.Admin.CreateTournamentData)    // 创建赛事
+				tournamentManage.POST("/start", controllers.Admin.StartTournamentData)      // 开赛
+				tournamentManage.POST("/cancel", controllers.Admin.CancelTournamentData)    // 取消赛事
+				tournamentManage.POST("/judge", controllers.Admin.JudgeTournamentMatchData) // 判定比赛胜者
 			}
 
 			// 最佳单次记录
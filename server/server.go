@@ -1,8 +1,10 @@
 package main
 
 import (
+	"puzzle/app/controllers"
 	"puzzle/app/middlewares/rabbitmq"
 	"puzzle/app/middlewares/websocket"
+	"puzzle/app/services"
 	"puzzle/config"
 	"puzzle/database"
 	"puzzle/routes"
@@ -15,6 +17,11 @@ func main() {
 	database.InitRedis() // 初始化Redis数据库连接
 
 	go websocket.ClientManagerInstance.Start() // 初始化WebSocket服务端
+	websocket.StartCluster()                   // 注册网关节点并订阅跨节点消息
+	controllers.WebSocket.RegisterHandlers()   // 注册WebSocket业务消息
+	defer websocket.StopCluster()
+
+	go services.FriendChallenge.Watch() // 定时结算过期的好友挑战
 
 	// 初始化队列和消费者
 	go rabbitmq.InitQueuesAndConsumers()
@@ -1,6 +1,10 @@
 package utils
 
-import "gorm.io/gorm"
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
 
 // 分页模型
 type Pagination struct {
@@ -33,3 +37,15 @@ func Paginate(pagination *Pagination) func(db *gorm.DB) *gorm.DB {
 		return db.Offset(offset).Limit(pageSize)
 	}
 }
+
+// SortDirection 校验排序方向, 只允许 asc 与 desc, 其他值使用默认方向, 避免拼接到排序语句中造成注入
+func SortDirection(sorted string, fallback string) string {
+	switch strings.ToLower(sorted) {
+	case "asc":
+		return "asc"
+	case "desc":
+		return "desc"
+	default:
+		return fallback
+	}
+}